@@ -6,8 +6,10 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -17,6 +19,26 @@ const (
 	// overridden by environment variables.
 	DefaultFuzzTime = "120s"
 
+	// CloneBudget is the time a fuzzing cycle budgets, on top of the fuzz
+	// time of its targets, for cloning the project and the corpus.
+	CloneBudget = 5 * time.Minute
+
+	// ReplayBudget is the time a fuzzing cycle budgets, on top of the fuzz
+	// time of its targets, for replaying the open crashes before they
+	// start. The crashes left once it is spent are replayed in a later
+	// cycle.
+	ReplayBudget = 5 * time.Minute
+
+	// BuildBudget is the time a fuzzing cycle budgets, on top of the fuzz
+	// time of its targets, for building them before their fuzz time
+	// starts, and saving their corpus once it ends.
+	BuildBudget = 5 * time.Minute
+
+	// BisectBudget is the time a fuzzing cycle budgets, once its fuzz
+	// targets are done, for searching the commits introducing its new
+	// crashes, if bisection is enabled.
+	BisectBudget = 30 * time.Minute
+
 	// DefaultCorpusDir is the directory where the fuzzing corpus is stored.
 	DefaultCorpusDir = "out/corpus"
 
@@ -26,10 +48,79 @@ const (
 	// DefaultReportName is the directory name where fuzzing results are
 	// stored.
 	DefaultReportName = "fuzz_results"
+
+	// DefaultLogMaxAgeDays is the number of days the complete output of
+	// past fuzz target runs is kept.
+	DefaultLogMaxAgeDays = 7
+
+	// DefaultLogMaxSizeMB is the maximum total size, in megabytes, of the
+	// archived output of past fuzz target runs.
+	DefaultLogMaxSizeMB = 1024
+
+	// DefaultStallTimeoutSecs is the number of seconds a fuzz target may
+	// run without making progress before it is considered stalled.
+	DefaultStallTimeoutSecs = 600
+
+	// StallActionRestart restarts a stalled fuzz target.
+	StallActionRestart = "restart"
+
+	// StallActionSkip stops a stalled fuzz target for the rest of the
+	// cycle.
+	StallActionSkip = "skip"
+
+	// LogSinkFile writes failure logs as plain text files.
+	LogSinkFile = "file"
+
+	// LogSinkJSON writes failure logs as JSON lines files.
+	LogSinkJSON = "jsonl"
+
+	// LogSinkGzip writes failure logs as gzip-compressed text files.
+	LogSinkGzip = "gzip"
+
+	// LogSinkMemory keeps failure logs in memory, mostly useful for
+	// tests.
+	LogSinkMemory = "memory"
+
+	// DefaultGitHubAPIURL is the base URL of the GitHub REST API issues are
+	// filed through.
+	DefaultGitHubAPIURL = "https://api.github.com"
+
+	// DefaultVerifyRuns is the number of times the failing input of a new
+	// crash is re-run to verify that it reproduces.
+	DefaultVerifyRuns = 5
+)
+
+const (
+	// WebhookFormatJSON posts new crashes as generic JSON objects.
+	WebhookFormatJSON = "json"
+
+	// WebhookFormatSlack posts new crashes as Slack messages.
+	WebhookFormatSlack = "slack"
+
+	// WebhookFormatDiscord posts new crashes as Discord messages.
+	WebhookFormatDiscord = "discord"
 )
 
+// Webhook is an endpoint notified of new crashes.
+type Webhook struct {
+	// Format is the payload format expected by the endpoint: "json",
+	// "slack" or "discord".
+	Format string
+
+	// URL is the address the payload is posted to.
+	URL string
+}
+
+// DefaultLogSinks are the failure log sinks used if not overridden by
+// environment variables.
+var DefaultLogSinks = []string{LogSinkFile}
+
 // Config holds the configuration parameters for the fuzzing setup.
 type Config struct {
+	// ConfigFile is the path of the configuration file read, empty if
+	// none.
+	ConfigFile string
+
 	// ProjectSrcPath is the Git repository URL of the project to be fuzzed.
 	ProjectSrcPath string
 
@@ -45,13 +136,86 @@ type Config struct {
 	// be fuzzed.
 	FuzzPkgs []string
 
-	// FuzzTime is the duration (in seconds) for which the fuzzing engine
-	// should run.
+	// FuzzTime is the duration for which the fuzzing engine should run,
+	// as a Go duration string.
 	FuzzTime string
 
+	// CycleDuration is the maximum duration of a fuzzing cycle, at least
+	// MinCycleDuration.
+	CycleDuration time.Duration
+
 	// NumProcesses specifies the number of fuzzing processes to run
 	// concurrently.
 	NumProcesses int
+
+	// LogSinks lists the sinks every failure log is written to, e.g.
+	// "file" and "jsonl".
+	LogSinks []string
+
+	// LogMaxAge is how long the archived output of past fuzz target runs
+	// is kept. Zero keeps it forever.
+	LogMaxAge time.Duration
+
+	// LogMaxSize is the maximum total size, in bytes, of the archived
+	// output of past fuzz target runs. Zero means no limit.
+	LogMaxSize int64
+
+	// StallTimeout is how long a fuzz target may run without making
+	// progress before the watchdog interrupts it. Zero disables the
+	// watchdog.
+	StallTimeout time.Duration
+
+	// StallAction is what happens to a stalled fuzz target once it has
+	// been interrupted: "restart" or "skip".
+	StallAction string
+
+	// Webhooks are the endpoints notified of every new crash signature.
+	Webhooks []Webhook
+
+	// GitHubRepo is the "owner/name" GitHub repository where an issue is
+	// filed for every new crash signature. Empty disables issue filing.
+	GitHubRepo string
+
+	// GitHubToken is the token authenticating the requests to the GitHub
+	// API.
+	GitHubToken string
+
+	// GitHubAPIURL is the base URL of the GitHub REST API.
+	GitHubAPIURL string
+
+	// VerifyRuns is the number of times the failing input of a new crash
+	// is re-run with "go test -run" before the crash is reported, to
+	// measure how reliably it reproduces. Zero disables verification.
+	VerifyRuns int
+
+	// VerifyRace enables the race detector in the verification runs.
+	VerifyRace bool
+
+	// BisectCommits is the number of commits, back from the fuzzed one,
+	// searched for the commit introducing a new crash. Zero disables
+	// bisection.
+	BisectCommits int
+
+	// HTTPAddr is the address the HTTP server exposing the dashboard,
+	// metrics and the control API listens on, e.g. ":9090". Empty
+	// disables the server.
+	HTTPAddr string
+
+	// BuildTags are the build tags the packages are built with, unless
+	// overridden.
+	BuildTags []string
+
+	// Race enables the race detector while fuzzing, unless overridden.
+	Race bool
+
+	// TargetEnv holds the variables added to the environment of every
+	// fuzz target, from the configuration file.
+	TargetEnv map[string]string
+
+	// Overrides holds the settings of the configuration file overriding
+	// the global ones for the fuzz targets of some packages, by package.
+	// See Target.
+	Overrides map[string]*PackageOverride
 }
 
 // LoadEnv loads environment variables from a .env file in the current
@@ -73,12 +237,12 @@ func LoadEnv() error {
 }
 
 // calculateProcessCount determines the number of processes to use based on the
-// FUZZ_NUM_PROCESSES environment variable. If the variable is set to a valid
-// number,it will return that value (capped by the number of available CPUs).
-// Otherwise, it returns the number of CPUs available.
-func calculateProcessCount() int {
+// FUZZ_NUM_PROCESSES setting. If the variable is set to a valid number,it will
+// return that value (capped by the number of available CPUs). Otherwise, it
+// returns the number of CPUs available.
+func calculateProcessCount(s settings) int {
 	// Check for a user-specified value in FUZZ_NUM_PROCESSES
-	if envVal := os.Getenv("FUZZ_NUM_PROCESSES"); envVal != "" {
+	if envVal := s.get("FUZZ_NUM_PROCESSES"); envVal != "" {
 		num, err := strconv.Atoi(envVal)
 
 		// Only accept valid, positive integers
@@ -102,14 +266,22 @@ func calculateProcessCount() int {
 	return runtime.NumCPU()
 }
 
-// LoadConfig loads the fuzzing configuration from environment variables.
-// It sets default values where applicable and returns an error if required
-// environment variables are missing or invalid.
-func LoadConfig() (*Config, error) {
+// LoadConfig loads the fuzzing configuration from the command line flags, the
+// environment variables and the configuration file named by FUZZ_CONFIG_FILE,
+// if any, in that order of precedence. It sets default values where
+// applicable and returns an error if required environment variables are
+// missing or invalid.
+func LoadConfig(flags Flags) (*Config, error) {
+	s, fc, err := loadSettings(flags)
+	if err != nil {
+		return nil, err
+	}
+
 	cfg := &Config{
-		ProjectSrcPath: os.Getenv("PROJECT_SRC_PATH"),
-		GitStorageRepo: os.Getenv("GIT_STORAGE_REPO"),
-		FuzzTime:       DefaultFuzzTime,
+		ConfigFile:     s.get("FUZZ_CONFIG_FILE"),
+		ProjectSrcPath: s.get("PROJECT_SRC_PATH"),
+		GitStorageRepo: s.get("GIT_STORAGE_REPO"),
+		HTTPAddr:       s.get("FUZZ_HTTP_ADDR"),
 	}
 
 	// Validate required variables
@@ -122,35 +294,277 @@ func LoadConfig() (*Config, error) {
 			" required")
 	}
 
-	// Override default FuzzTime if user provided a value
-	if fuzzTimeStr := os.Getenv("FUZZ_TIME"); fuzzTimeStr != "" {
-		// parse as integer seconds
-		seconds, err := strconv.Atoi(fuzzTimeStr)
+	if err := loadTargetConfig(cfg, s, fc); err != nil {
+		return nil, err
+	}
+
+	// FUZZ_PKG is required
+	if len(cfg.FuzzPkgs) == 0 {
+		return nil, errors.New("FUZZ_PKG environment variable required")
+	}
+
+	// Select the failure log sinks, defaulting to plain text files.
+	cfg.LogSinks = slices.Clone(DefaultLogSinks)
+	if sinks := s.get("FUZZ_LOG_SINKS"); sinks != "" {
+		cfg.LogSinks = strings.Fields(sinks)
+		for _, sink := range cfg.LogSinks {
+			if !isValidLogSink(sink) {
+				return nil, fmt.Errorf("%s contains "+
+					"unknown sink %q",
+					s.origin("FUZZ_LOG_SINKS"), sink)
+			}
+		}
+	}
+
+	// Determine how long, and how much of, the complete fuzzer output is
+	// archived.
+	maxAgeDays, err := s.nonNegativeInt("FUZZ_LOG_MAX_AGE_DAYS",
+		DefaultLogMaxAgeDays)
+	if err != nil {
+		return nil, err
+	}
+	cfg.LogMaxAge = time.Duration(maxAgeDays) * 24 * time.Hour
+
+	maxSizeMB, err := s.nonNegativeInt("FUZZ_LOG_MAX_SIZE_MB",
+		DefaultLogMaxSizeMB)
+	if err != nil {
+		return nil, err
+	}
+	cfg.LogMaxSize = int64(maxSizeMB) << 20
+
+	// Determine when a fuzz target is considered stalled and what to do
+	// with it.
+	stallSecs, err := s.nonNegativeInt("FUZZ_STALL_TIMEOUT",
+		DefaultStallTimeoutSecs)
+	if err != nil {
+		return nil, err
+	}
+	cfg.StallTimeout = time.Duration(stallSecs) * time.Second
+
+	cfg.StallAction = StallActionRestart
+	if action := s.get("FUZZ_STALL_ACTION"); action != "" {
+		if action != StallActionRestart && action != StallActionSkip {
+			return nil, fmt.Errorf("%s must be %q or %q, got %q",
+				s.origin("FUZZ_STALL_ACTION"),
+				StallActionRestart, StallActionSkip, action)
+		}
+		cfg.StallAction = action
+	}
+
+	// Select the webhooks notified of new crashes.
+	for i, entry := range strings.Fields(s.get("FUZZ_WEBHOOKS")) {
+		webhook, err := parseWebhook(entry)
 		if err != nil {
-			return nil, fmt.Errorf("FUZZ_TIME environment "+
-				"variable must be a number, got %q",
-				fuzzTimeStr)
+			return nil, fmt.Errorf("%s: entry %d: %w",
+				s.origin("FUZZ_WEBHOOKS"), i+1, err)
+		}
+		cfg.Webhooks = append(cfg.Webhooks, webhook)
+	}
+
+	// File GitHub issues for new crashes, if a repository is set.
+	if repo := s.get("FUZZ_GITHUB_REPO"); repo != "" {
+		owner, name, ok := strings.Cut(repo, "/")
+		if !ok || owner == "" || name == "" ||
+			strings.Contains(name, "/") {
+
+			return nil, fmt.Errorf("%s must be \"owner/name\", "+
+				"got %q", s.origin("FUZZ_GITHUB_REPO"), repo)
+		}
+
+		cfg.GitHubRepo = repo
+		cfg.GitHubToken = s.get("FUZZ_GITHUB_TOKEN")
+		if cfg.GitHubToken == "" {
+			return nil, errors.New("FUZZ_GITHUB_TOKEN " +
+				"environment variable required when " +
+				"FUZZ_GITHUB_REPO is set")
 		}
 
-		cfg.FuzzTime = fmt.Sprintf("%ds", seconds)
+		cfg.GitHubAPIURL = DefaultGitHubAPIURL
+		if apiURL := s.get("FUZZ_GITHUB_API_URL"); apiURL != "" {
+			cfg.GitHubAPIURL = strings.TrimSuffix(apiURL, "/")
+		}
 	}
 
-	// Determine how many concurrent fuzz processes to spawn
-	cfg.NumProcesses = calculateProcessCount()
+	// Determine how new crashes are verified to reproduce.
+	cfg.VerifyRuns, err = s.nonNegativeInt("FUZZ_VERIFY_RUNS",
+		DefaultVerifyRuns)
+	if err != nil {
+		return nil, err
+	}
 
-	// FUZZ_PKG is required: a space-separated list of package names
-	// (assumed to match their directory names)
-	fuzzPkgs := os.Getenv("FUZZ_PKG")
-	if fuzzPkgs == "" {
-		return nil, errors.New("FUZZ_PKG environment variable required")
+	if race := s.get("FUZZ_VERIFY_RACE"); race != "" {
+		cfg.VerifyRace, err = strconv.ParseBool(race)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a boolean, got %q",
+				s.origin("FUZZ_VERIFY_RACE"), race)
+		}
+	}
+
+	// Determine how far back new crashes are bisected.
+	cfg.BisectCommits, err = s.nonNegativeInt("FUZZ_BISECT_COMMITS", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Budget the fuzzing cycles, so that they do not end before their
+	// fuzz targets have been cloned, built, fuzzed and their corpus saved,
+	// nor before their new crashes have been bisected.
+	minCycle := cfg.MinCycleDuration()
+	cfg.CycleDuration, err = s.positiveDuration("CYCLE_DURATION", minCycle)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.CycleDuration < minCycle {
+		return nil, fmt.Errorf("%s must be at least %s, the longest "+
+			"fuzz time plus %s of overhead, got %s",
+			s.origin("CYCLE_DURATION"), minCycle,
+			cfg.CycleOverhead(), cfg.CycleDuration)
 	}
-	cfg.FuzzPkgs = strings.Fields(fuzzPkgs) // split on whitespace
 
 	// Build the directory where fuzz reports (and logs) will be written
 	// FUZZ_RESULTS_PATH may itself come from an env var (can be empty)
 	cfg.FuzzResultsPath = filepath.Join(
-		os.Getenv("FUZZ_RESULTS_PATH"), DefaultReportName,
+		s.get("FUZZ_RESULTS_PATH"), DefaultReportName,
 	)
 
 	return cfg, nil
 }
+
+// LoadTargetConfig loads the settings selecting the fuzz targets and how they
+// run, like LoadConfig: FUZZ_PKG, FUZZ_TIME, FUZZ_NUM_PROCESSES,
+// FUZZ_BUILD_TAGS, FUZZ_RACE and the overrides of the configuration file.
+// Unlike LoadConfig, it requires none of the settings, for the commands
+// inspecting the fuzz targets of a project.
+func LoadTargetConfig(flags Flags) (*Config, error) {
+	s, fc, err := loadSettings(flags)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{ConfigFile: s.get("FUZZ_CONFIG_FILE")}
+	if err := loadTargetConfig(cfg, s, fc); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// loadTargetConfig loads the settings of LoadTargetConfig into the
+// configuration.
+func loadTargetConfig(cfg *Config, s settings, fc *fileConfig) error {
+	// Override default FuzzTime if user provided a value
+	cfg.FuzzTime = DefaultFuzzTime
+	if s.get("FUZZ_TIME") != "" {
+		fuzzTime, err := s.positiveDuration("FUZZ_TIME", 0)
+		if err != nil {
+			return err
+		}
+
+		cfg.FuzzTime = fuzzTime.String()
+	}
+
+	// Determine how many concurrent fuzz processes to spawn
+	cfg.NumProcesses = calculateProcessCount(s)
+
+	// FUZZ_PKG is a space-separated list of package names (assumed to
+	// match their directory names)
+	cfg.FuzzPkgs = strings.Fields(s.get("FUZZ_PKG")) // split on whitespace
+
+	// Determine how the fuzz targets are built and run, unless
+	// overridden.
+	if tags := s.get("FUZZ_BUILD_TAGS"); tags != "" {
+		cfg.BuildTags = strings.Fields(tags)
+	}
+	if race := s.get("FUZZ_RACE"); race != "" {
+		var err error
+		cfg.Race, err = strconv.ParseBool(race)
+		if err != nil {
+			return fmt.Errorf("%s must be a boolean, got %q",
+				s.origin("FUZZ_RACE"), race)
+		}
+	}
+	cfg.TargetEnv = fc.Env
+	cfg.Overrides = fc.Packages
+
+	return nil
+}
+
+// nonNegativeInt returns the value of the setting as a non-negative integer,
+// or def if the setting is not set.
+func (s settings) nonNegativeInt(name string, def int) (int, error) {
+	envVal := s.get(name)
+	if envVal == "" {
+		return def, nil
+	}
+
+	num, err := strconv.Atoi(envVal)
+	if err != nil || num < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative number, "+
+			"got %q", s.origin(name), envVal)
+	}
+
+	return num, nil
+}
+
+// positiveDuration returns the positive duration the setting gives, see
+// parseDuration, or def if the setting is not set.
+func (s settings) positiveDuration(name string,
+	def time.Duration) (time.Duration, error) {
+
+	envVal := s.get(name)
+	if envVal == "" {
+		return def, nil
+	}
+
+	d, err := parseDuration(envVal)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%s must be a positive duration, e.g. "+
+			"90, 30m or 1h30m, got %q", s.origin(name), envVal)
+	}
+
+	return d, nil
+}
+
+// parseDuration parses a duration given either as a number of seconds or as a
+// Go duration string, e.g. "1h30m".
+func parseDuration(value string) (time.Duration, error) {
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+
+	return time.ParseDuration(value)
+}
+
+// parseWebhook parses a "format=URL" webhook entry. An entry without a known
+// format prefix is a URL receiving generic JSON. Errors do not quote the
+// entry, as webhook URLs are secrets.
+func parseWebhook(entry string) (Webhook, error) {
+	webhook := Webhook{Format: WebhookFormatJSON, URL: entry}
+	if format, url, ok := strings.Cut(entry, "="); ok {
+		switch format {
+		case WebhookFormatJSON, WebhookFormatSlack,
+			WebhookFormatDiscord:
+
+			webhook = Webhook{Format: format, URL: url}
+		}
+	}
+
+	if !strings.HasPrefix(webhook.URL, "http://") &&
+		!strings.HasPrefix(webhook.URL, "https://") {
+
+		return Webhook{}, errors.New("invalid webhook, expected " +
+			"[json|slack|discord=]http(s)://...")
+	}
+
+	return webhook, nil
+}
+
+// isValidLogSink reports whether the name refers to a known failure log sink.
+func isValidLogSink(sink string) bool {
+	switch sink {
+	case LogSinkFile, LogSinkJSON, LogSinkGzip, LogSinkMemory:
+		return true
+	default:
+		return false
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"runtime"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestCalculateProcessCount verifies that the calculateProcessCount function
@@ -49,7 +51,7 @@ func TestCalculateProcessCount(t *testing.T) {
 			t.Setenv("FUZZ_NUM_PROCESSES", tt.envValue)
 
 			// Call the function under test.
-			actualResult := calculateProcessCount()
+			actualResult := calculateProcessCount(settings{})
 
 			assert.Equal(t, tt.expectedResult, actualResult,
 				"calculated process count does not match")
@@ -69,7 +71,21 @@ func TestLoadConfig(t *testing.T) {
 		gitStorageRepo string
 		fuzzPkgs       string
 		fuzzTime       string
+		cycleDuration  string
 		numProcesses   string
+		logSinks       string
+		logMaxAgeDays  string
+		logMaxSizeMB   string
+		stallTimeout   string
+		stallAction    string
+		httpAddr       string
+		webhooks       string
+		githubRepo     string
+		githubToken    string
+		githubAPIURL   string
+		verifyRuns     string
+		verifyRace     string
+		bisectCommits  string
 		expectErr      bool
 		errorMsg       string
 		expectedCfg    *Config
@@ -95,7 +111,28 @@ func TestLoadConfig(t *testing.T) {
 			fuzzTime:       "five",
 			expectErr:      true,
 			errorMsg: "FUZZ_TIME environment variable must be " +
-				"a number",
+				"a positive duration",
+		},
+		{
+			name:           "invalid CYCLE_DURATION",
+			projectSrcPath: "https://github.com/OWNER/REPO.git",
+			gitStorageRepo: "https://github.com/OWNER/REPO.git",
+			fuzzPkgs:       "parser",
+			cycleDuration:  "soon",
+			expectErr:      true,
+			errorMsg: "CYCLE_DURATION environment variable must " +
+				"be a positive duration",
+		},
+		{
+			name:           "CYCLE_DURATION shorter than fuzz time",
+			projectSrcPath: "https://github.com/OWNER/REPO.git",
+			gitStorageRepo: "https://github.com/OWNER/REPO.git",
+			fuzzPkgs:       "parser",
+			fuzzTime:       "30m",
+			cycleDuration:  "35m",
+			expectErr:      true,
+			errorMsg: "CYCLE_DURATION environment variable must " +
+				"be at least 45m0s",
 		},
 		{
 			name:           "missing FUZZ_PKG",
@@ -118,11 +155,331 @@ func TestLoadConfig(t *testing.T) {
 				GitStorageRepo: "https://github.com/OWNER/" +
 					"REPO.git",
 				FuzzTime:        "20s",
+				CycleDuration:   920 * time.Second,
 				NumProcesses:    runtime.NumCPU(),
 				FuzzPkgs:        []string{"fuzz", "parser"},
 				FuzzResultsPath: "fuzz_results",
+				LogSinks:        []string{"file"},
+				LogMaxAge:       7 * 24 * time.Hour,
+				LogMaxSize:      1024 << 20,
+				StallTimeout:    600 * time.Second,
+				StallAction:     "restart",
+				VerifyRuns:      5,
+			},
+		},
+		{
+			name:           "duration strings",
+			projectSrcPath: "https://github.com/OWNER/REPO.git",
+			gitStorageRepo: "https://github.com/OWNER/REPO.git",
+			fuzzTime:       "1h30m",
+			cycleDuration:  "2h",
+			fuzzPkgs:       "parser",
+			expectErr:      false,
+			expectedCfg: &Config{
+				ProjectSrcPath: "https://github.com/OWNER/" +
+					"REPO.git",
+				GitStorageRepo: "https://github.com/OWNER/" +
+					"REPO.git",
+				FuzzTime:        "1h30m0s",
+				CycleDuration:   2 * time.Hour,
+				NumProcesses:    runtime.NumCPU(),
+				FuzzPkgs:        []string{"parser"},
+				FuzzResultsPath: "fuzz_results",
+				LogSinks:        []string{"file"},
+				LogMaxAge:       7 * 24 * time.Hour,
+				LogMaxSize:      1024 << 20,
+				StallTimeout:    600 * time.Second,
+				StallAction:     "restart",
+				VerifyRuns:      5,
+			},
+		},
+		{
+			name:           "multiple log sinks",
+			projectSrcPath: "https://github.com/OWNER/REPO.git",
+			gitStorageRepo: "https://github.com/OWNER/REPO.git",
+			fuzzPkgs:       "parser",
+			logSinks:       "file jsonl gzip",
+			expectErr:      false,
+			expectedCfg: &Config{
+				ProjectSrcPath: "https://github.com/OWNER/" +
+					"REPO.git",
+				GitStorageRepo: "https://github.com/OWNER/" +
+					"REPO.git",
+				FuzzTime:        "120s",
+				CycleDuration:   17 * time.Minute,
+				NumProcesses:    runtime.NumCPU(),
+				FuzzPkgs:        []string{"parser"},
+				FuzzResultsPath: "fuzz_results",
+				LogSinks: []string{"file", "jsonl",
+					"gzip"},
+				LogMaxAge:    7 * 24 * time.Hour,
+				LogMaxSize:   1024 << 20,
+				StallTimeout: 600 * time.Second,
+				StallAction:  "restart",
+				VerifyRuns:   5,
+			},
+		},
+		{
+			name:           "unknown log sink",
+			projectSrcPath: "https://github.com/OWNER/REPO.git",
+			gitStorageRepo: "https://github.com/OWNER/REPO.git",
+			fuzzPkgs:       "parser",
+			logSinks:       "file syslog",
+			expectErr:      true,
+			errorMsg:       "unknown sink \"syslog\"",
+		},
+		{
+			name:           "log retention overrides",
+			projectSrcPath: "https://github.com/OWNER/REPO.git",
+			gitStorageRepo: "https://github.com/OWNER/REPO.git",
+			fuzzPkgs:       "parser",
+			logMaxAgeDays:  "0",
+			logMaxSizeMB:   "10",
+			expectErr:      false,
+			expectedCfg: &Config{
+				ProjectSrcPath: "https://github.com/OWNER/" +
+					"REPO.git",
+				GitStorageRepo: "https://github.com/OWNER/" +
+					"REPO.git",
+				FuzzTime:        "120s",
+				CycleDuration:   17 * time.Minute,
+				NumProcesses:    runtime.NumCPU(),
+				FuzzPkgs:        []string{"parser"},
+				FuzzResultsPath: "fuzz_results",
+				LogSinks:        []string{"file"},
+				LogMaxAge:       0,
+				LogMaxSize:      10 << 20,
+				StallTimeout:    600 * time.Second,
+				StallAction:     "restart",
+				VerifyRuns:      5,
 			},
 		},
+		{
+			name:           "negative log retention",
+			projectSrcPath: "https://github.com/OWNER/REPO.git",
+			gitStorageRepo: "https://github.com/OWNER/REPO.git",
+			fuzzPkgs:       "parser",
+			logMaxAgeDays:  "-1",
+			expectErr:      true,
+			errorMsg: "FUZZ_LOG_MAX_AGE_DAYS environment " +
+				"variable must be a non-negative number",
+		},
+		{
+			name:           "stall watchdog and HTTP overrides",
+			projectSrcPath: "https://github.com/OWNER/REPO.git",
+			gitStorageRepo: "https://github.com/OWNER/REPO.git",
+			fuzzPkgs:       "parser",
+			stallTimeout:   "0",
+			stallAction:    "skip",
+			httpAddr:       ":9090",
+			expectErr:      false,
+			expectedCfg: &Config{
+				ProjectSrcPath: "https://github.com/OWNER/" +
+					"REPO.git",
+				GitStorageRepo: "https://github.com/OWNER/" +
+					"REPO.git",
+				FuzzTime:        "120s",
+				CycleDuration:   17 * time.Minute,
+				NumProcesses:    runtime.NumCPU(),
+				FuzzPkgs:        []string{"parser"},
+				FuzzResultsPath: "fuzz_results",
+				LogSinks:        []string{"file"},
+				LogMaxAge:       7 * 24 * time.Hour,
+				LogMaxSize:      1024 << 20,
+				StallTimeout:    0,
+				StallAction:     "skip",
+				HTTPAddr:        ":9090",
+				VerifyRuns:      5,
+			},
+		},
+		{
+			name:           "webhooks",
+			projectSrcPath: "https://github.com/OWNER/REPO.git",
+			gitStorageRepo: "https://github.com/OWNER/REPO.git",
+			fuzzPkgs:       "parser",
+			webhooks: "https://example.com/hook?a=b " +
+				"slack=https://hooks.slack.com/services/X " +
+				"discord=https://discord.com/api/webhooks/Y",
+			expectErr: false,
+			expectedCfg: &Config{
+				ProjectSrcPath: "https://github.com/OWNER/" +
+					"REPO.git",
+				GitStorageRepo: "https://github.com/OWNER/" +
+					"REPO.git",
+				FuzzTime:        "120s",
+				CycleDuration:   17 * time.Minute,
+				NumProcesses:    runtime.NumCPU(),
+				FuzzPkgs:        []string{"parser"},
+				FuzzResultsPath: "fuzz_results",
+				LogSinks:        []string{"file"},
+				LogMaxAge:       7 * 24 * time.Hour,
+				LogMaxSize:      1024 << 20,
+				StallTimeout:    600 * time.Second,
+				StallAction:     "restart",
+				Webhooks: []Webhook{
+					{
+						Format: "json",
+						URL: "https://example.com/" +
+							"hook?a=b",
+					},
+					{
+						Format: "slack",
+						URL: "https://hooks.slack." +
+							"com/services/X",
+					},
+					{
+						Format: "discord",
+						URL: "https://discord.com/" +
+							"api/webhooks/Y",
+					},
+				},
+				VerifyRuns: 5,
+			},
+		},
+		{
+			name:           "invalid webhook",
+			projectSrcPath: "https://github.com/OWNER/REPO.git",
+			gitStorageRepo: "https://github.com/OWNER/REPO.git",
+			fuzzPkgs:       "parser",
+			webhooks: "slack=https://hooks.slack.com/x " +
+				"teams=https://example.com/secret",
+			expectErr: true,
+			errorMsg:  "entry 2: invalid webhook",
+		},
+		{
+			name:           "github issues",
+			projectSrcPath: "https://github.com/OWNER/REPO.git",
+			gitStorageRepo: "https://github.com/OWNER/REPO.git",
+			fuzzPkgs:       "parser",
+			githubRepo:     "OWNER/REPO",
+			githubToken:    "ghp_secret",
+			githubAPIURL:   "http://localhost:8080/",
+			expectErr:      false,
+			expectedCfg: &Config{
+				ProjectSrcPath: "https://github.com/OWNER/" +
+					"REPO.git",
+				GitStorageRepo: "https://github.com/OWNER/" +
+					"REPO.git",
+				FuzzTime:        "120s",
+				CycleDuration:   17 * time.Minute,
+				NumProcesses:    runtime.NumCPU(),
+				FuzzPkgs:        []string{"parser"},
+				FuzzResultsPath: "fuzz_results",
+				LogSinks:        []string{"file"},
+				LogMaxAge:       7 * 24 * time.Hour,
+				LogMaxSize:      1024 << 20,
+				StallTimeout:    600 * time.Second,
+				StallAction:     "restart",
+				GitHubRepo:      "OWNER/REPO",
+				GitHubToken:     "ghp_secret",
+				GitHubAPIURL:    "http://localhost:8080",
+				VerifyRuns:      5,
+			},
+		},
+		{
+			name:           "crash verification",
+			projectSrcPath: "https://github.com/OWNER/REPO.git",
+			gitStorageRepo: "https://github.com/OWNER/REPO.git",
+			fuzzPkgs:       "parser",
+			verifyRuns:     "10",
+			verifyRace:     "true",
+			expectErr:      false,
+			expectedCfg: &Config{
+				ProjectSrcPath: "https://github.com/OWNER/" +
+					"REPO.git",
+				GitStorageRepo: "https://github.com/OWNER/" +
+					"REPO.git",
+				FuzzTime:        "120s",
+				CycleDuration:   17 * time.Minute,
+				NumProcesses:    runtime.NumCPU(),
+				FuzzPkgs:        []string{"parser"},
+				FuzzResultsPath: "fuzz_results",
+				LogSinks:        []string{"file"},
+				LogMaxAge:       7 * 24 * time.Hour,
+				LogMaxSize:      1024 << 20,
+				StallTimeout:    600 * time.Second,
+				StallAction:     "restart",
+				VerifyRuns:      10,
+				VerifyRace:      true,
+			},
+		},
+		{
+			name:           "invalid FUZZ_VERIFY_RACE",
+			projectSrcPath: "https://github.com/OWNER/REPO.git",
+			gitStorageRepo: "https://github.com/OWNER/REPO.git",
+			fuzzPkgs:       "parser",
+			verifyRace:     "sometimes",
+			expectErr:      true,
+			errorMsg: "FUZZ_VERIFY_RACE environment variable " +
+				"must be a boolean",
+		},
+		{
+			name:           "crash bisection",
+			projectSrcPath: "https://github.com/OWNER/REPO.git",
+			gitStorageRepo: "https://github.com/OWNER/REPO.git",
+			fuzzPkgs:       "parser",
+			bisectCommits:  "50",
+			expectErr:      false,
+			expectedCfg: &Config{
+				ProjectSrcPath: "https://github.com/OWNER/" +
+					"REPO.git",
+				GitStorageRepo: "https://github.com/OWNER/" +
+					"REPO.git",
+				FuzzTime:        "120s",
+				CycleDuration:   47 * time.Minute,
+				NumProcesses:    runtime.NumCPU(),
+				FuzzPkgs:        []string{"parser"},
+				FuzzResultsPath: "fuzz_results",
+				LogSinks:        []string{"file"},
+				LogMaxAge:       7 * 24 * time.Hour,
+				LogMaxSize:      1024 << 20,
+				StallTimeout:    600 * time.Second,
+				StallAction:     "restart",
+				VerifyRuns:      5,
+				BisectCommits:   50,
+			},
+		},
+		{
+			name:           "negative FUZZ_BISECT_COMMITS",
+			projectSrcPath: "https://github.com/OWNER/REPO.git",
+			gitStorageRepo: "https://github.com/OWNER/REPO.git",
+			fuzzPkgs:       "parser",
+			bisectCommits:  "-5",
+			expectErr:      true,
+			errorMsg: "FUZZ_BISECT_COMMITS environment variable " +
+				"must be a non-negative number",
+		},
+		{
+			name:           "invalid github repository",
+			projectSrcPath: "https://github.com/OWNER/REPO.git",
+			gitStorageRepo: "https://github.com/OWNER/REPO.git",
+			fuzzPkgs:       "parser",
+			githubRepo:     "https://github.com/OWNER/REPO",
+			githubToken:    "ghp_secret",
+			expectErr:      true,
+			errorMsg: "FUZZ_GITHUB_REPO environment variable " +
+				"must be \"owner/name\"",
+		},
+		{
+			name:           "missing github token",
+			projectSrcPath: "https://github.com/OWNER/REPO.git",
+			gitStorageRepo: "https://github.com/OWNER/REPO.git",
+			fuzzPkgs:       "parser",
+			githubRepo:     "OWNER/REPO",
+			expectErr:      true,
+			errorMsg: "FUZZ_GITHUB_TOKEN environment variable " +
+				"required",
+		},
+		{
+			name:           "unknown stall action",
+			projectSrcPath: "https://github.com/OWNER/REPO.git",
+			gitStorageRepo: "https://github.com/OWNER/REPO.git",
+			fuzzPkgs:       "parser",
+			stallAction:    "retry",
+			expectErr:      true,
+			errorMsg: "FUZZ_STALL_ACTION environment variable " +
+				"must be \"restart\" or \"skip\"",
+		},
 	}
 
 	for _, tt := range tests {
@@ -131,10 +488,24 @@ func TestLoadConfig(t *testing.T) {
 			t.Setenv("PROJECT_SRC_PATH", tt.projectSrcPath)
 			t.Setenv("GIT_STORAGE_REPO", tt.gitStorageRepo)
 			t.Setenv("FUZZ_TIME", tt.fuzzTime)
+			t.Setenv("CYCLE_DURATION", tt.cycleDuration)
 			t.Setenv("FUZZ_PKG", tt.fuzzPkgs)
 			t.Setenv("FUZZ_NUM_PROCESSES", tt.numProcesses)
+			t.Setenv("FUZZ_LOG_SINKS", tt.logSinks)
+			t.Setenv("FUZZ_LOG_MAX_AGE_DAYS", tt.logMaxAgeDays)
+			t.Setenv("FUZZ_LOG_MAX_SIZE_MB", tt.logMaxSizeMB)
+			t.Setenv("FUZZ_STALL_TIMEOUT", tt.stallTimeout)
+			t.Setenv("FUZZ_STALL_ACTION", tt.stallAction)
+			t.Setenv("FUZZ_HTTP_ADDR", tt.httpAddr)
+			t.Setenv("FUZZ_WEBHOOKS", tt.webhooks)
+			t.Setenv("FUZZ_GITHUB_REPO", tt.githubRepo)
+			t.Setenv("FUZZ_GITHUB_TOKEN", tt.githubToken)
+			t.Setenv("FUZZ_GITHUB_API_URL", tt.githubAPIURL)
+			t.Setenv("FUZZ_VERIFY_RUNS", tt.verifyRuns)
+			t.Setenv("FUZZ_VERIFY_RACE", tt.verifyRace)
+			t.Setenv("FUZZ_BISECT_COMMITS", tt.bisectCommits)
 
-			actualCfg, err := LoadConfig()
+			actualCfg, err := LoadConfig(nil)
 
 			if tt.expectErr {
 				assert.Error(t, err)
@@ -147,3 +518,11 @@ func TestLoadConfig(t *testing.T) {
 		})
 	}
 }
+
+// TestParseWebhookError verifies that invalid webhook entries, which may hold
+// secrets, are not quoted in errors.
+func TestParseWebhookError(t *testing.T) {
+	_, err := parseWebhook("slack=hooks.slack.com/services/T0/B0/secret")
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret")
+}
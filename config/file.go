@@ -0,0 +1,454 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TargetOptions are the settings a fuzz target runs with, resolved from the
+// global settings and the overrides of the configuration file.
+type TargetOptions struct {
+	// FuzzTime is how long the fuzz target is fuzzed, its weight applied.
+	FuzzTime time.Duration
+
+	// Weight scales the fuzz time of the fuzz target.
+	Weight float64
+
+	// Parallelism is the number of fuzzing processes of the fuzz target.
+	Parallelism int
+
+	// BuildTags are the build tags the package is built with.
+	BuildTags []string
+
+	// Env lists the "KEY=VALUE" variables added to the environment of the
+	// fuzz target, sorted by key.
+	Env []string
+
+	// Race enables the race detector.
+	Race bool
+
+	// Exclude leaves the fuzz target out of the fuzzing cycles.
+	Exclude bool
+}
+
+// BuildFlags returns the flags of "go test" building the package with the
+// build tags and the race detector of the options.
+func (o TargetOptions) BuildFlags() []string {
+	var flags []string
+	if len(o.BuildTags) > 0 {
+		flags = append(flags, "-tags="+strings.Join(o.BuildTags, ","))
+	}
+	if o.Race {
+		flags = append(flags, "-race")
+	}
+
+	return flags
+}
+
+// Override holds the settings of the configuration file overriding, for the
+// fuzz targets of a package or for a single fuzz target, the settings
+// inherited from the level above. Unset settings are nil.
+type Override struct {
+	// FuzzTime is the duration of fuzzing.
+	FuzzTime *Duration `yaml:"fuzz_time"`
+
+	// Weight scales the fuzz time, e.g. 2 fuzzes twice as long.
+	Weight *float64 `yaml:"weight"`
+
+	// Parallelism is the number of fuzzing processes.
+	Parallelism *int `yaml:"parallelism"`
+
+	// BuildTags replace the inherited build tags.
+	BuildTags []string `yaml:"build_tags"`
+
+	// Env holds variables added to the inherited environment.
+	Env map[string]string `yaml:"env"`
+
+	// Race enables or disables the race detector.
+	Race *bool `yaml:"race"`
+
+	// Exclude leaves the fuzz targets out of the fuzzing cycles.
+	Exclude *bool `yaml:"exclude"`
+}
+
+// PackageOverride holds the overrides of the configuration file for the fuzz
+// targets of a package.
+type PackageOverride struct {
+	// Override applies to every fuzz target of the package.
+	Override `yaml:",inline"`
+
+	// Targets holds the overrides of single fuzz targets of the package,
+	// by name, which take precedence over those of the package.
+	Targets map[string]*Override `yaml:"targets"`
+}
+
+// Duration is a duration of the configuration file, given either as a number of
+// seconds or as a Go duration string, e.g. "1h30m".
+type Duration time.Duration
+
+// UnmarshalYAML accepts a scalar parsed by parseDuration.
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: expected a duration", node.Line)
+	}
+
+	value, err := parseDuration(node.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: invalid duration %q, e.g. 90, 30m "+
+			"or 1h30m", node.Line, node.Value)
+	}
+	*d = Duration(value)
+
+	return nil
+}
+
+// fileConfig is the content of the configuration file.
+type fileConfig struct {
+	// Env holds the variables added to the environment of every fuzz
+	// target.
+	Env map[string]string `yaml:"env"`
+
+	// Packages holds the overrides of the fuzz targets of packages, by
+	// package.
+	Packages map[string]*PackageOverride `yaml:"packages"`
+
+	// Settings holds the global settings, by the lowercase name of their
+	// environment variable.
+	Settings map[string]settingValue `yaml:",inline"`
+}
+
+// settingValue is the value of a global setting in the configuration file, as
+// the value of its environment variable. A list is joined with spaces.
+type settingValue string
+
+// UnmarshalYAML accepts a scalar or a list of scalars.
+func (v *settingValue) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		*v = settingValue(node.Value)
+		return nil
+
+	case yaml.SequenceNode:
+		items := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return fmt.Errorf("line %d: list items must "+
+					"be scalars", item.Line)
+			}
+			items = append(items, item.Value)
+		}
+		*v = settingValue(strings.Join(items, " "))
+		return nil
+	}
+
+	return fmt.Errorf("line %d: expected a scalar or a list", node.Line)
+}
+
+// Flags holds the values of the global settings given as command line flags,
+// by the name of the environment variable they mirror.
+type Flags map[string]string
+
+// Source tells where the value of a setting comes from.
+type Source string
+
+const (
+	// SourceFlag is a value given as a command line flag.
+	SourceFlag Source = "flag"
+
+	// SourceEnv is a value set in the environment or the .env file.
+	SourceEnv Source = "env"
+
+	// SourceFile is a value set in the configuration file.
+	SourceFile Source = "file"
+
+	// SourceDefault is the default value of an unset setting.
+	SourceDefault Source = "default"
+)
+
+// settings gives the values of the global settings, named by their
+// environment variable: from the command line flags if given there, otherwise
+// from the environment, otherwise from the configuration file.
+type settings struct {
+	// flags holds the values given as command line flags.
+	flags Flags
+
+	// file holds the values set in the configuration file.
+	file map[string]string
+}
+
+// get returns the value of the setting, empty if it is not set.
+func (s settings) get(name string) string {
+	value, _ := s.lookup(name)
+	return value
+}
+
+// lookup returns the value of the setting and where it comes from.
+func (s settings) lookup(name string) (string, Source) {
+	if value := s.flags[name]; value != "" {
+		return value, SourceFlag
+	}
+	if value := os.Getenv(name); value != "" {
+		return value, SourceEnv
+	}
+	if value := s.file[name]; value != "" {
+		return value, SourceFile
+	}
+
+	return "", SourceDefault
+}
+
+// origin names the setting in the errors about its value, after where the value
+// comes from: its command line flag, its key in the configuration file or its
+// environment variable.
+func (s settings) origin(name string) string {
+	switch _, source := s.lookup(name); source {
+	case SourceFlag:
+		v := EnvVar{Name: name}
+		return "--" + v.Flag().Name + " flag"
+
+	case SourceFile:
+		return strings.ToLower(name) + " setting of the " +
+			"configuration file"
+
+	default:
+		return name + " environment variable"
+	}
+}
+
+// loadSettings returns the settings given by the flags, the environment and
+// the configuration file named by FUZZ_CONFIG_FILE, with the content of the
+// file. The configuration is empty if no file is named.
+func loadSettings(flags Flags) (settings, *fileConfig, error) {
+	s := settings{flags: flags}
+	path := s.get("FUZZ_CONFIG_FILE")
+	if path == "" {
+		return s, &fileConfig{}, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return s, nil, fmt.Errorf("failed to open configuration "+
+			"file: %w", err)
+	}
+	defer f.Close()
+
+	var fc *fileConfig
+
+	s.file, fc, err = parseConfigFile(f)
+	if err != nil {
+		return s, nil, fmt.Errorf("invalid configuration file "+
+			"%s: %w", path, err)
+	}
+
+	return s, fc, nil
+}
+
+// Setting returns the value of the global setting named by its environment
+// variable, given by the flags, the environment or the configuration file, in
+// that order of precedence. It is empty if the setting is not set.
+func Setting(flags Flags, name string) (string, error) {
+	s, _, err := loadSettings(flags)
+	if err != nil {
+		return "", err
+	}
+
+	return s.get(name), nil
+}
+
+// parseConfigFile decodes and checks the YAML configuration file. It returns
+// the global settings it sets, by the name of their environment variable.
+func parseConfigFile(r io.Reader) (map[string]string, *fileConfig, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	fc := &fileConfig{}
+	if err := decoder.Decode(fc); err != nil && !errors.Is(err, io.EOF) {
+		return nil, nil, err
+	}
+
+	s := make(map[string]string)
+	for key, value := range fc.Settings {
+		name := strings.ToUpper(key)
+		if key != strings.ToLower(key) || !isFileSetting(name) {
+			return nil, nil, fmt.Errorf("unknown setting %q", key)
+		}
+		s[name] = string(value)
+	}
+
+	if err := checkEnv(fc.Env); err != nil {
+		return nil, nil, err
+	}
+	for pkg, p := range fc.Packages {
+		if p == nil {
+			return nil, nil, fmt.Errorf("package %s: no settings",
+				pkg)
+		}
+		if err := p.check(); err != nil {
+			return nil, nil, fmt.Errorf("package %s: %w", pkg, err)
+		}
+		for target, t := range p.Targets {
+			if t == nil {
+				return nil, nil, fmt.Errorf("package %s, "+
+					"target %s: no settings", pkg, target)
+			}
+			if err := t.check(); err != nil {
+				return nil, nil, fmt.Errorf("package %s, "+
+					"target %s: %w", pkg, target, err)
+			}
+		}
+	}
+
+	return s, fc, nil
+}
+
+// isFileSetting reports whether the environment variable can be set in the
+// configuration file, which is the case of all but FUZZ_CONFIG_FILE.
+func isFileSetting(name string) bool {
+	if name == "FUZZ_CONFIG_FILE" {
+		return false
+	}
+
+	return slices.ContainsFunc(EnvVars, func(v EnvVar) bool {
+		return v.Name == name
+	})
+}
+
+// check validates the values of the overridden settings.
+func (o *Override) check() error {
+	if o.FuzzTime != nil && *o.FuzzTime <= 0 {
+		return fmt.Errorf("fuzz_time must be a positive duration, got "+
+			"%s", time.Duration(*o.FuzzTime))
+	}
+	if o.Weight != nil && *o.Weight <= 0 {
+		return fmt.Errorf("weight must be a positive number, got %g",
+			*o.Weight)
+	}
+	if o.Parallelism != nil && *o.Parallelism <= 0 {
+		return fmt.Errorf("parallelism must be a positive number, "+
+			"got %d", *o.Parallelism)
+	}
+
+	return checkEnv(o.Env)
+}
+
+// checkEnv validates the names of environment variables.
+func checkEnv(env map[string]string) error {
+	for name := range env {
+		if name == "" || strings.ContainsAny(name, "= ") {
+			return fmt.Errorf("invalid environment variable name "+
+				"%q", name)
+		}
+	}
+
+	return nil
+}
+
+// apply overrides the options and the environment with the settings set.
+func (o *Override) apply(opts *TargetOptions, fuzzTime *time.Duration,
+	env map[string]string) {
+
+	if o.FuzzTime != nil {
+		*fuzzTime = time.Duration(*o.FuzzTime)
+	}
+	if o.Weight != nil {
+		opts.Weight = *o.Weight
+	}
+	if o.Parallelism != nil {
+		opts.Parallelism = min(*o.Parallelism, runtime.NumCPU())
+	}
+	if o.BuildTags != nil {
+		opts.BuildTags = o.BuildTags
+	}
+	maps.Copy(env, o.Env)
+	if o.Race != nil {
+		opts.Race = *o.Race
+	}
+	if o.Exclude != nil {
+		opts.Exclude = *o.Exclude
+	}
+}
+
+// Target returns the options the fuzz target of the package runs with: the
+// global settings, overridden by those of the package in the configuration
+// file, overridden in turn by those of the fuzz target. An empty target gives
+// the options of the package.
+func (c *Config) Target(pkg, target string) TargetOptions {
+	fuzzTime, _ := time.ParseDuration(c.FuzzTime)
+	opts := TargetOptions{
+		Weight:      1,
+		Parallelism: c.NumProcesses,
+		BuildTags:   c.BuildTags,
+		Race:        c.Race,
+	}
+	env := maps.Clone(c.TargetEnv)
+	if env == nil {
+		env = make(map[string]string)
+	}
+
+	if p := c.Overrides[pkg]; p != nil {
+		p.apply(&opts, &fuzzTime, env)
+		if t := p.Targets[target]; t != nil {
+			t.apply(&opts, &fuzzTime, env)
+		}
+	}
+
+	opts.FuzzTime = time.Duration(float64(fuzzTime) * opts.Weight)
+	for _, name := range slices.Sorted(maps.Keys(env)) {
+		opts.Env = append(opts.Env, name+"="+env[name])
+	}
+
+	return opts
+}
+
+// MaxFuzzTime returns the longest fuzz time of the fuzz targets of FuzzPkgs
+// not excluded, which is how long a fuzzing cycle runs, or the global fuzz
+// time if they are all excluded.
+func (c *Config) MaxFuzzTime() time.Duration {
+	var longest time.Duration
+	for _, pkg := range c.FuzzPkgs {
+		targets := []string{""}
+		if p := c.Overrides[pkg]; p != nil {
+			targets = slices.AppendSeq(targets,
+				maps.Keys(p.Targets))
+		}
+
+		for _, target := range targets {
+			opts := c.Target(pkg, target)
+			if !opts.Exclude {
+				longest = max(longest, opts.FuzzTime)
+			}
+		}
+	}
+	if longest == 0 {
+		longest, _ = time.ParseDuration(c.FuzzTime)
+	}
+
+	return longest
+}
+
+// MinCycleDuration returns the shortest fuzzing cycle letting every fuzz target
+// run for its fuzz time: the longest fuzz time, plus the overhead of the cycle.
+func (c *Config) MinCycleDuration() time.Duration {
+	return c.MaxFuzzTime() + c.CycleOverhead()
+}
+
+// CycleOverhead returns the time a fuzzing cycle budgets on top of the fuzz
+// time of its targets: CloneBudget, ReplayBudget and BuildBudget, plus
+// BisectBudget if bisection is enabled.
+func (c *Config) CycleOverhead() time.Duration {
+	overhead := CloneBudget + ReplayBudget + BuildBudget
+	if c.BisectCommits > 0 {
+		overhead += BisectBudget
+	}
+
+	return overhead
+}
@@ -0,0 +1,317 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testConfigFile is a configuration file setting global settings, and
+// overriding them for a package and one of its fuzz targets.
+const testConfigFile = `
+project_src_path: https://github.com/OWNER/REPO.git
+git_storage_repo: https://github.com/OWNER/STORAGE.git
+fuzz_pkg:
+  - parser
+  - routing
+fuzz_time: 60
+fuzz_num_processes: 1
+fuzz_build_tags: dev
+env:
+  GODEBUG: madvdontneed=1
+packages:
+  routing:
+    weight: 2
+    build_tags: [dev, routing]
+    env:
+      ROUTING: "1"
+    targets:
+      FuzzSlow:
+        fuzz_time: 5m
+        race: true
+      FuzzBroken:
+        exclude: true
+`
+
+// TestParseConfigFile verifies that invalid configuration files are rejected
+// with a descriptive error.
+func TestParseConfigFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		errorMsg string
+	}{
+		{
+			name:    "empty file",
+			content: "",
+		},
+		{
+			name:    "valid file",
+			content: testConfigFile,
+		},
+		{
+			name:     "unknown setting",
+			content:  "fuzz_timeout: 60\n",
+			errorMsg: `unknown setting "fuzz_timeout"`,
+		},
+		{
+			name:     "uppercase setting",
+			content:  "FUZZ_TIME: 60\n",
+			errorMsg: `unknown setting "FUZZ_TIME"`,
+		},
+		{
+			name:     "configuration file setting",
+			content:  "fuzz_config_file: other.yaml\n",
+			errorMsg: `unknown setting "fuzz_config_file"`,
+		},
+		{
+			name:     "nested list",
+			content:  "fuzz_pkg: [[parser]]\n",
+			errorMsg: "list items must be scalars",
+		},
+		{
+			name:     "unknown override",
+			content:  "packages:\n  parser:\n    timeout: 60\n",
+			errorMsg: "field timeout not found",
+		},
+		{
+			name:     "non-positive weight",
+			content:  "packages:\n  parser:\n    weight: 0\n",
+			errorMsg: "package parser: weight must be a positive",
+		},
+		{
+			name: "non-positive target fuzz time",
+			content: "packages:\n  parser:\n    targets:\n" +
+				"      FuzzFoo:\n        fuzz_time: -1\n",
+			errorMsg: "package parser, target FuzzFoo: fuzz_time " +
+				"must be a positive",
+		},
+		{
+			name: "invalid target fuzz time",
+			content: "packages:\n  parser:\n    targets:\n" +
+				"      FuzzFoo:\n        fuzz_time: soon\n",
+			errorMsg: `invalid duration "soon"`,
+		},
+		{
+			name:     "invalid environment variable",
+			content:  "env:\n  A=B: C\n",
+			errorMsg: `invalid environment variable name "A=B"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := parseConfigFile(
+				strings.NewReader(tt.content),
+			)
+			if tt.errorMsg != "" {
+				assert.ErrorContains(t, err, tt.errorMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// TestLoadConfigFile verifies that the configuration file sets the settings
+// not set in the environment, and the overrides of the fuzz targets.
+func TestLoadConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fuzz.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testConfigFile), 0644))
+
+	for _, name := range []string{
+		"PROJECT_SRC_PATH", "GIT_STORAGE_REPO", "FUZZ_PKG",
+		"FUZZ_NUM_PROCESSES", "FUZZ_BUILD_TAGS", "FUZZ_RACE",
+	} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("FUZZ_CONFIG_FILE", path)
+	t.Setenv("FUZZ_TIME", "30")
+
+	cfg, err := LoadConfig(nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://github.com/OWNER/REPO.git",
+		cfg.ProjectSrcPath)
+	assert.Equal(t, []string{"parser", "routing"}, cfg.FuzzPkgs)
+	assert.Equal(t, 1, cfg.NumProcesses)
+	assert.Equal(t, []string{"dev"}, cfg.BuildTags)
+
+	// The environment takes precedence over the file.
+	assert.Equal(t, "30s", cfg.FuzzTime)
+
+	assert.Equal(t, TargetOptions{
+		FuzzTime:    60 * time.Second,
+		Weight:      2,
+		Parallelism: 1,
+		BuildTags:   []string{"dev", "routing"},
+		Env:         []string{"GODEBUG=madvdontneed=1", "ROUTING=1"},
+	}, cfg.Target("routing", "FuzzFast"))
+	assert.Equal(t, 10*time.Minute, cfg.MaxFuzzTime())
+	assert.Equal(t, 25*time.Minute, cfg.CycleDuration)
+
+	// Invalid values are reported against the file.
+	require.NoError(t, os.WriteFile(path, []byte(testConfigFile+
+		"cycle_duration: 1m\n"), 0644))
+	_, err = LoadConfig(nil)
+	assert.ErrorContains(t, err, "cycle_duration setting of the "+
+		"configuration file must be at least 25m0s")
+
+	t.Setenv("FUZZ_CONFIG_FILE", filepath.Join(t.TempDir(), "missing"))
+	_, err = LoadConfig(nil)
+	assert.ErrorContains(t, err, "failed to open configuration file")
+}
+
+// TestSettingOrigin verifies that settings are named after where their value
+// comes from.
+func TestSettingOrigin(t *testing.T) {
+	t.Setenv("FUZZ_TIME", "30")
+	t.Setenv("CYCLE_DURATION", "")
+	t.Setenv("FUZZ_RACE", "")
+	t.Setenv("FUZZ_STALL_ACTION", "")
+	s := settings{
+		flags: Flags{"CYCLE_DURATION": "1m"},
+		file:  map[string]string{"FUZZ_RACE": "maybe"},
+	}
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{
+			name:     "CYCLE_DURATION",
+			expected: "--cycle-duration flag",
+		},
+		{
+			name:     "FUZZ_TIME",
+			expected: "FUZZ_TIME environment variable",
+		},
+		{
+			name:     "FUZZ_RACE",
+			expected: "fuzz_race setting of the configuration file",
+		},
+		{
+			name:     "FUZZ_STALL_ACTION",
+			expected: "FUZZ_STALL_ACTION environment variable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, s.origin(tt.name))
+		})
+	}
+}
+
+// TestTarget verifies that the options of a fuzz target are the global
+// settings, overridden by those of its package, then by its own.
+func TestTarget(t *testing.T) {
+	_, fc, err := parseConfigFile(strings.NewReader(testConfigFile))
+	require.NoError(t, err)
+
+	cfg := &Config{
+		FuzzPkgs:     []string{"parser", "routing"},
+		FuzzTime:     "60s",
+		NumProcesses: 1,
+		BuildTags:    []string{"dev"},
+		TargetEnv:    fc.Env,
+		Overrides:    fc.Packages,
+	}
+
+	tests := []struct {
+		name     string
+		pkg      string
+		target   string
+		expected TargetOptions
+	}{
+		{
+			name:   "global settings",
+			pkg:    "parser",
+			target: "FuzzFoo",
+			expected: TargetOptions{
+				FuzzTime:    time.Minute,
+				Weight:      1,
+				Parallelism: 1,
+				BuildTags:   []string{"dev"},
+				Env:         []string{"GODEBUG=madvdontneed=1"},
+			},
+		},
+		{
+			name:   "package overrides",
+			pkg:    "routing",
+			target: "FuzzFast",
+			expected: TargetOptions{
+				FuzzTime:    2 * time.Minute,
+				Weight:      2,
+				Parallelism: 1,
+				BuildTags:   []string{"dev", "routing"},
+				Env: []string{
+					"GODEBUG=madvdontneed=1", "ROUTING=1",
+				},
+			},
+		},
+		{
+			name:   "target overrides",
+			pkg:    "routing",
+			target: "FuzzSlow",
+			expected: TargetOptions{
+				FuzzTime:    10 * time.Minute,
+				Weight:      2,
+				Parallelism: 1,
+				BuildTags:   []string{"dev", "routing"},
+				Env: []string{
+					"GODEBUG=madvdontneed=1", "ROUTING=1",
+				},
+				Race: true,
+			},
+		},
+		{
+			name:   "excluded target",
+			pkg:    "routing",
+			target: "FuzzBroken",
+			expected: TargetOptions{
+				FuzzTime:    2 * time.Minute,
+				Weight:      2,
+				Parallelism: 1,
+				BuildTags:   []string{"dev", "routing"},
+				Env: []string{
+					"GODEBUG=madvdontneed=1", "ROUTING=1",
+				},
+				Exclude: true,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, cfg.Target(tt.pkg,
+				tt.target))
+		})
+	}
+
+	assert.Equal(t, 10*time.Minute, cfg.MaxFuzzTime())
+
+	// Parallelism overrides are capped by the number of CPUs.
+	parallelism := runtime.NumCPU() + 1
+	cfg.Overrides["parser"] = &PackageOverride{
+		Override: Override{Parallelism: &parallelism},
+	}
+	assert.Equal(t, runtime.NumCPU(), cfg.Target("parser", "").Parallelism)
+}
+
+// TestBuildFlags verifies the "go test" flags building a package with the
+// build tags and race detector of a fuzz target.
+func TestBuildFlags(t *testing.T) {
+	assert.Empty(t, TargetOptions{}.BuildFlags())
+	assert.Equal(t, []string{"-tags=dev,routing", "-race"},
+		TargetOptions{
+			BuildTags: []string{"dev", "routing"},
+			Race:      true,
+		}.BuildFlags())
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHelpText verifies that the help message lists every command and every
+// environment variable with the flag mirroring it, and fits in 80 columns.
+func TestHelpText(t *testing.T) {
+	for _, c := range Commands {
+		assert.Contains(t, HelpText, "\n  "+c.Name)
+	}
+	for _, v := range EnvVars {
+		f := v.Flag()
+		line := "\n  " + v.Name + ", " + f.Synopsis()
+		if v.Required {
+			line += "    (Required)"
+		}
+		assert.Contains(t, HelpText, line+"\n")
+	}
+
+	for _, line := range strings.Split(HelpText, "\n") {
+		assert.LessOrEqual(t, len(line), 80, line)
+	}
+}
+
+// TestCommandHelp verifies that the help of a command lists its flags with
+// their defaults.
+func TestCommandHelp(t *testing.T) {
+	c, _ := LookupCommand([]string{"corpus", "minimize"})
+	require.NotNil(t, c)
+
+	help := c.Help()
+	assert.True(t, strings.HasPrefix(help, "Usage: go run main.go corpus "+
+		"minimize [flags]\n"))
+	assert.Contains(t, help, "\n  --results DIR\n")
+	assert.Contains(t, help, "Default: $FUZZ_RESULTS_PATH/fuzz_results\n")
+	assert.Contains(t, help, "\n  --project DIR\n")
+	assert.Contains(t, help, "\n  --dry-run\n")
+
+	c, _ = LookupCommand([]string{"sarif"})
+	require.NotNil(t, c)
+	assert.Contains(t, c.Help(), "\n  -o FILE\n")
+
+	// Commands reading the configuration accept the flags mirroring the
+	// environment variables.
+	c, _ = LookupCommand([]string{"version"})
+	require.NotNil(t, c)
+	assert.Equal(t, "go run main.go version", c.Usage())
+	assert.NotContains(t, c.Help(), "--fuzz-time")
+	c, _ = LookupCommand([]string{"validate-config"})
+	require.NotNil(t, c)
+	assert.Equal(t, "go run main.go validate-config [flags]", c.Usage())
+	assert.Contains(t, c.Help(), "--fuzz-time\nfor FUZZ_TIME")
+}
+
+// TestEnvVarFlag verifies that the flags mirroring the environment variables
+// are named after them.
+func TestEnvVarFlag(t *testing.T) {
+	tests := []struct {
+		name     string
+		envVar   EnvVar
+		synopsis string
+	}{
+		{
+			name:     "value",
+			envVar:   EnvVar{Name: "FUZZ_TIME", Arg: "SECONDS"},
+			synopsis: "--fuzz-time SECONDS",
+		},
+		{
+			name:     "boolean",
+			envVar:   EnvVar{Name: "FUZZ_RACE"},
+			synopsis: "--fuzz-race",
+		},
+		{
+			name:     "without prefix",
+			envVar:   EnvVar{Name: "PROJECT_SRC_PATH", Arg: "URL"},
+			synopsis: "--project-src-path URL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := tt.envVar.Flag()
+			assert.Equal(t, tt.synopsis, f.Synopsis())
+		})
+	}
+}
+
+// TestLookupCommand verifies that commands, including those of several words,
+// are found from the leading arguments.
+func TestLookupCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		command string
+		rest    []string
+	}{
+		{
+			name:    "single word",
+			args:    []string{"run", "--once"},
+			command: "run",
+			rest:    []string{"--once"},
+		},
+		{
+			name:    "several words",
+			args:    []string{"corpus", "stats", "--results", "x"},
+			command: "corpus stats",
+			rest:    []string{"--results", "x"},
+		},
+		{
+			name: "incomplete command",
+			args: []string{"corpus"},
+			rest: []string{"corpus"},
+		},
+		{
+			name: "unknown command",
+			args: []string{"fuzz", "now"},
+			rest: []string{"fuzz", "now"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, rest := LookupCommand(tt.args)
+			if tt.command == "" {
+				assert.Nil(t, c)
+			} else {
+				require.NotNil(t, c)
+				assert.Equal(t, tt.command, c.Name)
+			}
+			assert.Equal(t, tt.rest, rest)
+		})
+	}
+}
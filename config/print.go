@@ -0,0 +1,208 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// redacted replaces the secrets in the printed configuration.
+const redacted = "REDACTED"
+
+// settingValues render the effective value of every global setting from the
+// configuration, by the name of its environment variable, secrets redacted.
+var settingValues = map[string]func(c *Config) string{
+	"FUZZ_NUM_PROCESSES": func(c *Config) string {
+		return strconv.Itoa(c.NumProcesses)
+	},
+	"PROJECT_SRC_PATH": func(c *Config) string {
+		return redactURL(c.ProjectSrcPath)
+	},
+	"GIT_STORAGE_REPO": func(c *Config) string {
+		return redactURL(c.GitStorageRepo)
+	},
+	"FUZZ_TIME": func(c *Config) string {
+		return c.FuzzTime
+	},
+	"CYCLE_DURATION": func(c *Config) string {
+		return c.CycleDuration.String()
+	},
+	"FUZZ_PKG": func(c *Config) string {
+		return strings.Join(c.FuzzPkgs, " ")
+	},
+	"FUZZ_RESULTS_PATH": func(c *Config) string {
+		return filepath.Dir(c.FuzzResultsPath)
+	},
+	"FUZZ_LOG_SINKS": func(c *Config) string {
+		return strings.Join(c.LogSinks, " ")
+	},
+	"FUZZ_LOG_MAX_AGE_DAYS": func(c *Config) string {
+		return strconv.Itoa(int(c.LogMaxAge / (24 * time.Hour)))
+	},
+	"FUZZ_LOG_MAX_SIZE_MB": func(c *Config) string {
+		return strconv.FormatInt(c.LogMaxSize>>20, 10)
+	},
+	"FUZZ_STALL_TIMEOUT": func(c *Config) string {
+		return strconv.Itoa(int(c.StallTimeout / time.Second))
+	},
+	"FUZZ_STALL_ACTION": func(c *Config) string {
+		return c.StallAction
+	},
+	"FUZZ_WEBHOOKS": func(c *Config) string {
+		webhooks := make([]string, 0, len(c.Webhooks))
+		for _, webhook := range c.Webhooks {
+			webhooks = append(webhooks, webhook.Format+"="+
+				redactURLPath(webhook.URL))
+		}
+
+		return strings.Join(webhooks, " ")
+	},
+	"FUZZ_GITHUB_REPO": func(c *Config) string {
+		return c.GitHubRepo
+	},
+	"FUZZ_GITHUB_TOKEN": func(c *Config) string {
+		if c.GitHubToken == "" {
+			return ""
+		}
+
+		return redacted
+	},
+	"FUZZ_GITHUB_API_URL": func(c *Config) string {
+		return redactURL(c.GitHubAPIURL)
+	},
+	"FUZZ_VERIFY_RUNS": func(c *Config) string {
+		return strconv.Itoa(c.VerifyRuns)
+	},
+	"FUZZ_VERIFY_RACE": func(c *Config) string {
+		return strconv.FormatBool(c.VerifyRace)
+	},
+	"FUZZ_BISECT_COMMITS": func(c *Config) string {
+		return strconv.Itoa(c.BisectCommits)
+	},
+	"FUZZ_HTTP_ADDR": func(c *Config) string {
+		return c.HTTPAddr
+	},
+	"FUZZ_BUILD_TAGS": func(c *Config) string {
+		return strings.Join(c.BuildTags, " ")
+	},
+	"FUZZ_RACE": func(c *Config) string {
+		return strconv.FormatBool(c.Race)
+	},
+	"FUZZ_CONFIG_FILE": func(c *Config) string {
+		return c.ConfigFile
+	},
+}
+
+// PrintConfig writes the effective configuration, loaded from the flags, the
+// environment and the configuration file: the value of every global setting,
+// secrets redacted, with where it comes from, then the options of the fuzz
+// targets overridden by the configuration file. Only the names of the
+// variables added to the environment of the fuzz targets are written, as
+// their values may be secrets.
+func PrintConfig(w io.Writer, cfg *Config, flags Flags) error {
+	s, _, err := loadSettings(flags)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SETTING\tVALUE\tSOURCE")
+	for _, v := range EnvVars {
+		value := settingValues[v.Name](cfg)
+		if value == "" {
+			value = "-"
+		}
+		_, source := s.lookup(v.Name)
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", v.Name, value, source)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(cfg.Overrides) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(w, "\nOverrides of %s:\n", cfg.ConfigFile)
+	fmt.Fprintln(tw, "PACKAGE\tTARGET\tFUZZ TIME\tWEIGHT\tPARALLELISM\t"+
+		"BUILD TAGS\tENV\tRACE\tEXCLUDE")
+	for _, pkg := range slices.Sorted(maps.Keys(cfg.Overrides)) {
+		targets := append([]string{""}, slices.Sorted(
+			maps.Keys(cfg.Overrides[pkg].Targets))...)
+
+		for _, target := range targets {
+			opts := cfg.Target(pkg, target)
+			names := make([]string, 0, len(opts.Env))
+			for _, env := range opts.Env {
+				name, _, _ := strings.Cut(env, "=")
+				names = append(names, name)
+			}
+			if target == "" {
+				target = "*"
+			}
+
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%g\t%d\t%s\t%s\t%t\t%t\n",
+				pkg, target, opts.FuzzTime, opts.Weight,
+				opts.Parallelism, orDash(opts.BuildTags),
+				orDash(names), opts.Race, opts.Exclude)
+		}
+	}
+
+	return tw.Flush()
+}
+
+// orDash joins the list with commas, or returns "-" if it is empty.
+func orDash(list []string) string {
+	if len(list) == 0 {
+		return "-"
+	}
+
+	return strings.Join(list, ",")
+}
+
+// redactURL returns the URL with the credentials it may embed, e.g. a GitHub
+// Personal Access Token, redacted. If the URL cannot be parsed, it is redacted
+// entirely.
+func redactURL(rawURL string) string {
+	if rawURL == "" {
+		return ""
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return redacted
+	}
+	if parsed.User != nil {
+		if _, ok := parsed.User.Password(); ok {
+			parsed.User = url.UserPassword(parsed.User.Username(),
+				redacted)
+		} else {
+			parsed.User = url.User(redacted)
+		}
+	}
+
+	return parsed.String()
+}
+
+// redactURLPath returns the URL with its path, which holds the secret of
+// webhooks, and its credentials redacted.
+func redactURLPath(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return redacted
+	}
+	parsed.User = nil
+	parsed.RawQuery = ""
+	parsed.Fragment = ""
+	parsed.Path = "/" + redacted
+	parsed.RawPath = ""
+
+	return parsed.String()
+}
@@ -0,0 +1,329 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TargetState is the execution state of a fuzz target within a cycle.
+type TargetState string
+
+const (
+	// TargetRunning is a fuzz target being fuzzed.
+	TargetRunning TargetState = "running"
+
+	// TargetFinished is a fuzz target that ran until the end of its
+	// fuzzing time or of the cycle.
+	TargetFinished TargetState = "finished"
+
+	// TargetStopped is a fuzz target stopped through the controller.
+	TargetStopped TargetState = "stopped"
+
+	// TargetFailed is a fuzz target whose execution failed, e.g. because
+	// its package does not build.
+	TargetFailed TargetState = "failed"
+)
+
+var (
+	// ErrPaused is returned when a new cycle is requested while fuzzing
+	// is paused.
+	ErrPaused = errors.New("fuzzing is paused")
+
+	// ErrTargetNotRunning is returned when stopping a fuzz target that is
+	// not running.
+	ErrTargetNotRunning = errors.New("fuzz target is not running")
+)
+
+// TargetStatus describes a fuzz target of the current cycle.
+type TargetStatus struct {
+	// Package is the package containing the fuzz target.
+	Package string `json:"package"`
+
+	// Target is the name of the fuzz target.
+	Target string `json:"target"`
+
+	// State is the execution state of the fuzz target.
+	State TargetState `json:"state"`
+
+	// StartedAt is when the fuzz target started running.
+	StartedAt time.Time `json:"started_at"`
+
+	// FinishedAt is when the fuzz target stopped running, if it did.
+	FinishedAt time.Time `json:"finished_at,omitzero"`
+
+	// Error describes why the execution failed, if it did.
+	Error string `json:"error,omitempty"`
+}
+
+// Status describes what the fuzzer is doing.
+type Status struct {
+	// Paused indicates whether fuzzing is paused.
+	Paused bool `json:"paused"`
+
+	// Cycle identifies the current, or last, fuzzing cycle.
+	Cycle string `json:"cycle,omitempty"`
+
+	// CycleStart is the time the cycle started.
+	CycleStart time.Time `json:"cycle_start,omitzero"`
+
+	// Targets lists the fuzz targets of the cycle, sorted by package and
+	// target name.
+	Targets []TargetStatus `json:"targets"`
+}
+
+// target tracks a fuzz target of the current cycle.
+type target struct {
+	status  TargetStatus
+	cancel  context.CancelFunc
+	stopped bool
+}
+
+// Controller lets the fuzzing cycles be observed and steered while they run.
+// The scheduler waits on it between cycles and interrupts the current cycle
+// when asked to, and each fuzz target runs with a context the controller can
+// cancel. A nil Controller is valid and never pauses nor interrupts anything.
+type Controller struct {
+	mu sync.Mutex
+
+	// paused indicates whether fuzzing is paused. While it is, resumed is
+	// an open channel, closed on resume.
+	paused  bool
+	resumed chan struct{}
+
+	// interrupt holds a pending request to end the current cycle.
+	interrupt chan struct{}
+
+	cycle      string
+	cycleStart time.Time
+	targets    map[string]*target
+}
+
+// New creates a Controller with fuzzing running.
+func New() *Controller {
+	return &Controller{
+		interrupt: make(chan struct{}, 1),
+		targets:   make(map[string]*target),
+	}
+}
+
+// contextKey is the key of the Controller in a context.
+type contextKey struct{}
+
+// NewContext returns a copy of the context carrying the controller.
+func NewContext(ctx context.Context, c *Controller) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+// FromContext returns the controller carried by the context, or nil.
+func FromContext(ctx context.Context) *Controller {
+	c, _ := ctx.Value(contextKey{}).(*Controller)
+	return c
+}
+
+// Pause interrupts the current cycle and keeps new cycles from starting until
+// Resume is called.
+func (c *Controller) Pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.paused {
+		return
+	}
+	c.paused = true
+	c.resumed = make(chan struct{})
+	c.requestInterrupt()
+}
+
+// Resume lets new cycles start again after Pause.
+func (c *Controller) Resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.paused {
+		return
+	}
+	c.paused = false
+	close(c.resumed)
+}
+
+// TriggerCycle interrupts the current cycle so that a new one starts right
+// away. It returns ErrPaused if fuzzing is paused.
+func (c *Controller) TriggerCycle() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.paused {
+		return ErrPaused
+	}
+	c.requestInterrupt()
+
+	return nil
+}
+
+// requestInterrupt records a request to end the current cycle, unless one is
+// already pending. The caller must hold the lock.
+func (c *Controller) requestInterrupt() {
+	select {
+	case c.interrupt <- struct{}{}:
+	default:
+	}
+}
+
+// Interrupts returns the channel receiving a value when the current cycle is
+// to be ended early.
+func (c *Controller) Interrupts() <-chan struct{} {
+	if c == nil {
+		return nil
+	}
+
+	return c.interrupt
+}
+
+// WaitReady blocks while fuzzing is paused, then discards the interrupt
+// requests made before the new cycle. It returns false if the context was
+// canceled first.
+func (c *Controller) WaitReady(ctx context.Context) bool {
+	if c == nil {
+		return ctx.Err() == nil
+	}
+
+	for {
+		c.mu.Lock()
+		if !c.paused {
+			select {
+			case <-c.interrupt:
+			default:
+			}
+			c.mu.Unlock()
+
+			return true
+		}
+		resumed := c.resumed
+		c.mu.Unlock()
+
+		select {
+		case <-resumed:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// StartCycle records the start of a fuzzing cycle, forgetting the fuzz
+// targets of the previous one.
+func (c *Controller) StartCycle(id string, start time.Time) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cycle = id
+	c.cycleStart = start
+	c.targets = make(map[string]*target)
+}
+
+// StartTarget records that the fuzz target is running and returns the context
+// it must run with, which is canceled by StopTarget. The cancel function must
+// be called once the target has finished.
+func (c *Controller) StartTarget(ctx context.Context, pkg,
+	name string) (context.Context, context.CancelFunc) {
+
+	ctx, cancel := context.WithCancel(ctx)
+	if c == nil {
+		return ctx, cancel
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.targets[targetKey(pkg, name)] = &target{
+		status: TargetStatus{
+			Package:   pkg,
+			Target:    name,
+			State:     TargetRunning,
+			StartedAt: time.Now().UTC(),
+		},
+		cancel: cancel,
+	}
+
+	return ctx, cancel
+}
+
+// FinishTarget records that the fuzz target is no longer running, along with
+// the error that ended it, if any.
+func (c *Controller) FinishTarget(pkg, name string, err error) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, ok := c.targets[targetKey(pkg, name)]
+	if !ok {
+		return
+	}
+
+	t.status.FinishedAt = time.Now().UTC()
+	switch {
+	case t.stopped:
+		t.status.State = TargetStopped
+	case err != nil:
+		t.status.State = TargetFailed
+		t.status.Error = err.Error()
+	default:
+		t.status.State = TargetFinished
+	}
+}
+
+// StopTarget stops the fuzz target for the rest of the cycle. It returns
+// ErrTargetNotRunning if the target is not running.
+func (c *Controller) StopTarget(pkg, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, ok := c.targets[targetKey(pkg, name)]
+	if !ok || t.status.State != TargetRunning {
+		return ErrTargetNotRunning
+	}
+
+	t.stopped = true
+	t.cancel()
+
+	return nil
+}
+
+// Status returns what the fuzzer is doing.
+func (c *Controller) Status() Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	status := Status{
+		Paused:     c.paused,
+		Cycle:      c.cycle,
+		CycleStart: c.cycleStart,
+		Targets:    make([]TargetStatus, 0, len(c.targets)),
+	}
+	for _, t := range c.targets {
+		status.Targets = append(status.Targets, t.status)
+	}
+	sort.Slice(status.Targets, func(i, j int) bool {
+		a, b := status.Targets[i], status.Targets[j]
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		return a.Target < b.Target
+	})
+
+	return status
+}
+
+// targetKey identifies a fuzz target across packages.
+func targetKey(pkg, name string) string {
+	return pkg + "/" + name
+}
@@ -0,0 +1,128 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPauseResume verifies that pausing interrupts the current cycle and holds
+// the next one back until fuzzing is resumed.
+func TestPauseResume(t *testing.T) {
+	ctl := New()
+	require.True(t, ctl.WaitReady(context.Background()))
+
+	ctl.Pause()
+	select {
+	case <-ctl.Interrupts():
+	default:
+		t.Fatal("pausing did not interrupt the cycle")
+	}
+	assert.ErrorIs(t, ctl.TriggerCycle(), ErrPaused)
+
+	ready := make(chan bool)
+	go func() {
+		ready <- ctl.WaitReady(context.Background())
+	}()
+
+	select {
+	case <-ready:
+		t.Fatal("cycle started while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	ctl.Resume()
+	assert.True(t, <-ready)
+
+	// Waiting is abandoned once the context is canceled.
+	ctl.Pause()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.False(t, ctl.WaitReady(ctx))
+}
+
+// TestTriggerCycle verifies that a new cycle request interrupts the current
+// cycle once, and that requests made before a cycle starts are discarded.
+func TestTriggerCycle(t *testing.T) {
+	ctl := New()
+
+	require.NoError(t, ctl.TriggerCycle())
+	require.NoError(t, ctl.TriggerCycle())
+	<-ctl.Interrupts()
+	select {
+	case <-ctl.Interrupts():
+		t.Fatal("duplicate interrupt")
+	default:
+	}
+
+	require.NoError(t, ctl.TriggerCycle())
+	require.True(t, ctl.WaitReady(context.Background()))
+	select {
+	case <-ctl.Interrupts():
+		t.Fatal("stale interrupt delivered to the new cycle")
+	default:
+	}
+}
+
+// TestTargets verifies the state reported for the fuzz targets of a cycle.
+func TestTargets(t *testing.T) {
+	ctl := New()
+	ctl.StartCycle("20250101T000000Z", time.Now())
+
+	names := []string{"FuzzStopped", "FuzzFailed", "FuzzDone", "FuzzRun"}
+	ctxs := make(map[string]context.Context)
+	for _, name := range names {
+		ctx, cancel := ctl.StartTarget(context.Background(), "foo",
+			name)
+		defer cancel()
+		ctxs[name] = ctx
+	}
+
+	require.NoError(t, ctl.StopTarget("foo", "FuzzStopped"))
+	assert.Error(t, ctxs["FuzzStopped"].Err())
+	assert.NoError(t, ctxs["FuzzRun"].Err())
+
+	ctl.FinishTarget("foo", "FuzzStopped", context.Canceled)
+	ctl.FinishTarget("foo", "FuzzFailed", errors.New("build failed"))
+	ctl.FinishTarget("foo", "FuzzDone", nil)
+
+	assert.ErrorIs(t, ctl.StopTarget("foo", "FuzzDone"),
+		ErrTargetNotRunning)
+	assert.ErrorIs(t, ctl.StopTarget("bar", "FuzzRun"),
+		ErrTargetNotRunning)
+
+	states := make(map[string]TargetState)
+	for _, ts := range ctl.Status().Targets {
+		states[ts.Target] = ts.State
+	}
+	assert.Equal(t, map[string]TargetState{
+		"FuzzStopped": TargetStopped,
+		"FuzzFailed":  TargetFailed,
+		"FuzzDone":    TargetFinished,
+		"FuzzRun":     TargetRunning,
+	}, states)
+
+	// A new cycle starts without targets.
+	ctl.StartCycle("20250101T010000Z", time.Now())
+	assert.Empty(t, ctl.Status().Targets)
+}
+
+// TestNilController verifies that a nil controller never holds back nor
+// interrupts fuzzing.
+func TestNilController(t *testing.T) {
+	var ctl *Controller
+
+	assert.Nil(t, FromContext(context.Background()))
+	assert.True(t, ctl.WaitReady(context.Background()))
+	assert.Nil(t, ctl.Interrupts())
+
+	ctl.StartCycle("20250101T000000Z", time.Now())
+	ctx, cancel := ctl.StartTarget(context.Background(), "foo", "FuzzFoo")
+	defer cancel()
+	assert.NoError(t, ctx.Err())
+	ctl.FinishTarget("foo", "FuzzFoo", nil)
+}
@@ -0,0 +1,154 @@
+package corpus
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/NishantBansal2003/LND-Fuzz/crash"
+)
+
+// Target is the corpus of a fuzz target saved in the results directory, under
+// <package>/testdata/fuzz/<target>.
+type Target struct {
+	// Package is the package containing the fuzz target, relative to the
+	// project root.
+	Package string
+
+	// Name is the name of the fuzz target.
+	Name string
+
+	// Dir is the directory holding the corpus inputs.
+	Dir string
+}
+
+// Stats describes the corpus of a fuzz target.
+type Stats struct {
+	// Target is the fuzz target.
+	Target Target
+
+	// Inputs is the number of inputs in the corpus.
+	Inputs int
+
+	// Bytes is the total size of the inputs.
+	Bytes int64
+}
+
+// Targets returns the fuzz targets with a corpus in the results directory,
+// sorted by package and name. The reproducer bundles of the crash database,
+// which hold failing inputs rather than corpora, are left out.
+func Targets(resultsDir string) ([]Target, error) {
+	var targets []Target
+	err := filepath.WalkDir(resultsDir, func(path string, d fs.DirEntry,
+		err error) error {
+
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path == filepath.Join(resultsDir, crash.DirName) {
+			return filepath.SkipDir
+		}
+
+		// Look for <package>/testdata/fuzz/<target>.
+		fuzzDir := filepath.Dir(path)
+		testdataDir := filepath.Dir(fuzzDir)
+		if filepath.Base(fuzzDir) != "fuzz" ||
+			filepath.Base(testdataDir) != "testdata" {
+
+			return nil
+		}
+
+		pkg, err := filepath.Rel(resultsDir, filepath.Dir(testdataDir))
+		if err != nil {
+			return err
+		}
+		targets = append(targets, Target{
+			Package: filepath.ToSlash(pkg),
+			Name:    d.Name(),
+			Dir:     path,
+		})
+
+		return filepath.SkipDir
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no results directory %q", resultsDir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk results directory: %w",
+			err)
+	}
+
+	sort.Slice(targets, func(i, j int) bool {
+		if targets[i].Package != targets[j].Package {
+			return targets[i].Package < targets[j].Package
+		}
+
+		return targets[i].Name < targets[j].Name
+	})
+
+	return targets, nil
+}
+
+// Stat returns the number of inputs and the size of the corpus of the fuzz
+// target.
+func Stat(target Target) (*Stats, error) {
+	inputs, err := readInputs(target)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &Stats{Target: target, Inputs: len(inputs)}
+	for _, input := range inputs {
+		stats.Bytes += input.size
+	}
+
+	return stats, nil
+}
+
+// input is a file of a corpus.
+type input struct {
+	// name is the name of the file, which "go test" uses as the name of
+	// the subtest running it.
+	name string
+
+	// size is the size of the file in bytes.
+	size int64
+}
+
+// readInputs returns the inputs of the corpus of the fuzz target, smallest
+// first.
+func readInputs(target Target) ([]input, error) {
+	entries, err := os.ReadDir(target.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read corpus of %s/%s: %w",
+			target.Package, target.Name, err)
+	}
+
+	var inputs []input
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat corpus "+
+				"input: %w", err)
+		}
+		inputs = append(inputs, input{
+			name: entry.Name(),
+			size: info.Size(),
+		})
+	}
+	sort.SliceStable(inputs, func(i, j int) bool {
+		return inputs[i].size < inputs[j].size
+	})
+
+	return inputs, nil
+}
@@ -0,0 +1,56 @@
+package corpus
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NishantBansal2003/LND-Fuzz/crash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeInput writes a corpus input of the fuzz target of the package under
+// the directory, and returns its path.
+func writeInput(t *testing.T, dir, pkg, target, name,
+	content string) string {
+
+	path := filepath.Join(dir, pkg, "testdata", "fuzz", target, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	return path
+}
+
+// TestTargets verifies that the corpora of the results directory are found
+// with their package and fuzz target, leaving out the reproducer bundles.
+func TestTargets(t *testing.T) {
+	resultsDir := t.TempDir()
+	writeInput(t, resultsDir, "foo/bar", "FuzzBar", "a", "12")
+	writeInput(t, resultsDir, "foo/bar", "FuzzBar", "b", "1234")
+	writeInput(t, resultsDir, "foo", "FuzzFoo", "a", "123")
+	writeInput(t, resultsDir, filepath.Join(crash.DirName, "0123",
+		crash.ReproducerDirName), "FuzzFoo", "c", "1")
+	require.NoError(t, os.MkdirAll(filepath.Join(resultsDir, "stats"),
+		0755))
+
+	targets, err := Targets(resultsDir)
+	require.NoError(t, err)
+	require.Len(t, targets, 2)
+	assert.Equal(t, Target{
+		Package: "foo",
+		Name:    "FuzzFoo",
+		Dir: filepath.Join(resultsDir, "foo", "testdata", "fuzz",
+			"FuzzFoo"),
+	}, targets[0])
+	assert.Equal(t, "foo/bar", targets[1].Package)
+	assert.Equal(t, "FuzzBar", targets[1].Name)
+
+	stats, err := Stat(targets[1])
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Inputs)
+	assert.EqualValues(t, 6, stats.Bytes)
+
+	_, err = Targets(filepath.Join(resultsDir, "missing"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,215 @@
+package corpus
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/otiai10/copy"
+)
+
+const (
+	// inputTimeout bounds the run of each input, so that an input hanging
+	// the fuzz target is treated as failing instead of blocking the
+	// minimization.
+	inputTimeout = time.Minute
+
+	// inputWaitDelay is how long the run of an input may keep its output
+	// open once the test binary has been killed.
+	inputWaitDelay = 10 * time.Second
+)
+
+// Minimization is the outcome of minimizing the corpus of a fuzz target.
+type Minimization struct {
+	// Target is the fuzz target.
+	Target Target
+
+	// Kept lists the inputs covering code no smaller input covers.
+	Kept []string
+
+	// Failing lists the inputs failing the fuzz target, which are kept
+	// so that the crashes they trigger are not lost.
+	Failing []string
+
+	// Removed lists the inputs adding no coverage, removed from the
+	// corpus unless minimizing as a dry run.
+	Removed []string
+
+	// Bytes is the size of the corpus before minimization.
+	Bytes int64
+
+	// RemovedBytes is the size of the removed inputs.
+	RemovedBytes int64
+}
+
+// Minimize shrinks the corpus of the fuzz target to the inputs covering the
+// same code. The test binary of the package, in the project checked out in
+// projectDir, is built with coverage enabled, then each input is run alone,
+// smallest first, from the testdata/fuzz directory of the package. An input is
+// kept if it covers a block no input run before it covered, or if it fails.
+// The other inputs are removed from the corpus, unless dryRun is set.
+func Minimize(ctx context.Context, logger *slog.Logger, projectDir string,
+	target Target, dryRun bool) (*Minimization, error) {
+
+	inputs, err := readInputs(target)
+	if err != nil {
+		return nil, err
+	}
+
+	pkgDir := filepath.Join(projectDir, target.Package)
+	tmpDir, err := os.MkdirTemp("", "minimize-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: "+
+			"%w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	binary := filepath.Join(tmpDir, "fuzz.test")
+	cmd := exec.CommandContext(ctx, "go", "test", "-c", "-cover", "-o",
+		binary, ".")
+	cmd.Dir = pkgDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("failed to build %s: %w: %s",
+			target.Package, err, bytes.TrimSpace(output))
+	}
+
+	m := &Minimization{Target: target}
+	covered := make(map[string]bool)
+	for _, in := range inputs {
+		m.Bytes += in.size
+
+		blocks, failed, err := runInput(ctx, binary, pkgDir, tmpDir,
+			target, in.name)
+		if err != nil {
+			return nil, err
+		}
+
+		added := 0
+		for _, block := range blocks {
+			if !covered[block] {
+				covered[block] = true
+				added++
+			}
+		}
+
+		switch {
+		case failed:
+			m.Failing = append(m.Failing, in.name)
+		case added > 0:
+			m.Kept = append(m.Kept, in.name)
+		default:
+			m.Removed = append(m.Removed, in.name)
+			m.RemovedBytes += in.size
+		}
+		logger.Debug("Corpus input run", "target", target.Name,
+			"input", in.name, "failed", failed, "newBlocks", added)
+	}
+
+	if dryRun {
+		return m, nil
+	}
+	for _, name := range m.Removed {
+		err := os.Remove(filepath.Join(target.Dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to remove corpus "+
+				"input: %w", err)
+		}
+	}
+
+	return m, nil
+}
+
+// runInput runs the corpus input alone with the test binary, copying it to the
+// testdata/fuzz directory of the package for the run unless it is already
+// there. It returns the code blocks the run covered and whether it failed.
+func runInput(ctx context.Context, binary, pkgDir, tmpDir string,
+	target Target, name string) ([]string, bool, error) {
+
+	inputPath := filepath.Join(pkgDir, "testdata", "fuzz", target.Name,
+		name)
+	if _, err := os.Stat(inputPath); errors.Is(err, os.ErrNotExist) {
+		err := copy.Copy(filepath.Join(target.Dir, name), inputPath)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to copy corpus "+
+				"input: %w", err)
+		}
+		defer os.Remove(inputPath)
+	}
+
+	profile := filepath.Join(tmpDir, "cover.out")
+	if err := os.Remove(profile); err != nil &&
+		!errors.Is(err, os.ErrNotExist) {
+
+		return nil, false, fmt.Errorf("failed to remove coverage "+
+			"profile: %w", err)
+	}
+
+	pattern := fmt.Sprintf("^%s$/^%s$", regexp.QuoteMeta(target.Name),
+		regexp.QuoteMeta(name))
+	cmd := exec.CommandContext(ctx, binary, "-test.run="+pattern,
+		"-test.v", "-test.count=1", "-test.coverprofile="+profile,
+		fmt.Sprintf("-test.timeout=%s", inputTimeout))
+	cmd.Dir = pkgDir
+	cmd.WaitDelay = inputWaitDelay
+
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return nil, false, fmt.Errorf("minimization interrupted: %w",
+			ctx.Err())
+	}
+
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return nil, false, fmt.Errorf("failed to run test binary: %w",
+			err)
+	}
+	if !bytes.Contains(output, []byte("=== RUN   "+target.Name+"/"+name)) {
+		return nil, false, fmt.Errorf("input %s/%s was not run: %s",
+			target.Name, name, bytes.TrimSpace(output))
+	}
+
+	blocks, readErr := coveredBlocks(profile)
+	if readErr != nil && err == nil {
+		return nil, false, readErr
+	}
+
+	return blocks, err != nil, nil
+}
+
+// coveredBlocks returns the code blocks, as "file:start,end" positions, that
+// the coverage profile reports as run.
+func coveredBlocks(profile string) ([]string, error) {
+	f, err := os.Open(profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open coverage profile: %w",
+			err)
+	}
+	defer f.Close()
+
+	// Each line is "<file>:<start>,<end> <statements> <count>", after
+	// the "mode:" header.
+	var blocks []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || fields[2] == "0" {
+			continue
+		}
+		blocks = append(blocks, fields[0])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read coverage profile: %w",
+			err)
+	}
+
+	return blocks, nil
+}
@@ -0,0 +1,106 @@
+package corpus
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fooSource is a package whose coverage depends on the length of its input,
+// and which panics on "boom".
+const fooSource = `package foo
+
+func Foo(s string) string {
+	if s == "boom" {
+		panic("boom")
+	}
+	if len(s) > 8 {
+		return s[:8]
+	}
+
+	return s
+}
+`
+
+// fooFuzzTest is the fuzz target of the foo package.
+const fooFuzzTest = `package foo
+
+import "testing"
+
+func FuzzFoo(f *testing.F) {
+	f.Fuzz(func(t *testing.T, s string) {
+		Foo(s)
+	})
+}
+`
+
+// TestMinimize verifies that the inputs adding no coverage are removed from
+// the corpus, while the smallest inputs covering each block and the failing
+// inputs are kept.
+func TestMinimize(t *testing.T) {
+	projectDir := t.TempDir()
+	pkgDir := filepath.Join(projectDir, "foo")
+	require.NoError(t, os.MkdirAll(pkgDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "go.mod"),
+		[]byte("module example.com/project\n\ngo 1.21\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(pkgDir, "foo.go"),
+		[]byte(fooSource), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(pkgDir, "foo_test.go"),
+		[]byte(fooFuzzTest), 0644))
+
+	resultsDir := t.TempDir()
+	inputs := map[string]string{
+		"short":  "a",
+		"short2": "ab",
+		"long":   "0123456789",
+		"long2":  "0123456789abcdef",
+		"boom":   "boom",
+	}
+	for name, s := range inputs {
+		writeInput(t, resultsDir, "foo", "FuzzFoo", name,
+			"go test fuzz v1\nstring(\""+s+"\")\n")
+	}
+	targets, err := Targets(resultsDir)
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	t.Setenv("GOFLAGS", "")
+
+	// A dry run removes nothing.
+	m, err := Minimize(context.Background(), logger, projectDir,
+		targets[0], true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"short", "long"}, m.Kept)
+	assert.Equal(t, []string{"boom"}, m.Failing)
+	assert.Equal(t, []string{"short2", "long2"}, m.Removed)
+	stats, err := Stat(targets[0])
+	require.NoError(t, err)
+	assert.Equal(t, 5, stats.Inputs)
+
+	m, err = Minimize(context.Background(), logger, projectDir,
+		targets[0], false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"short2", "long2"}, m.Removed)
+	assert.Equal(t, stats.Bytes, m.Bytes)
+
+	entries, err := os.ReadDir(targets[0].Dir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.ElementsMatch(t, []string{"short", "long", "boom"}, names)
+
+	// The inputs copied into the package are removed.
+	entries, err = os.ReadDir(filepath.Join(pkgDir, "testdata", "fuzz",
+		"FuzzFoo"))
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,71 @@
+package crash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/NishantBansal2003/LND-Fuzz/git"
+)
+
+// Bisect searches the history of the project cloned in
+// config.DefaultProjectDir for the commit introducing the crash, by running
+// its failing input against past commits, see git.Bisect. Commits where the
+// package does not exist or does not build, or where the fuzz target does not
+// run the input, are skipped. It returns nil if bisection is disabled.
+func Bisect(ctx context.Context, logger *slog.Logger, cfg *config.Config,
+	f *Finding) (*git.Bisection, error) {
+
+	if cfg.BisectCommits == 0 {
+		return nil, nil
+	}
+
+	input, err := os.ReadFile(f.InputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read failing input: %w", err)
+	}
+
+	opts := runOptions(cfg, f.Package, f.Target)
+	test := func(ctx context.Context, dir string) (git.Verdict, error) {
+		pkgDir := filepath.Join(dir, f.Package)
+		if _, err := os.Stat(pkgDir); errors.Is(err, os.ErrNotExist) {
+			return git.VerdictSkip, nil
+		}
+
+		inputDir := filepath.Join(pkgDir, "testdata", "fuzz", f.Target)
+		if err := os.MkdirAll(inputDir, 0755); err != nil {
+			return 0, fmt.Errorf("failed to create corpus "+
+				"directory: %w", err)
+		}
+		err := os.WriteFile(filepath.Join(inputDir, f.InputID), input,
+			0644)
+		if err != nil {
+			return 0, fmt.Errorf("failed to write failing "+
+				"input: %w", err)
+		}
+
+		failed, err := runInput(ctx, pkgDir, f.Target, f.InputID,
+			opts)
+		switch {
+		case errors.Is(err, errBuildFailed),
+			errors.Is(err, errInputNotRun):
+
+			return git.VerdictSkip, nil
+
+		case err != nil:
+			return 0, err
+
+		case failed:
+			return git.VerdictBad, nil
+		}
+
+		return git.VerdictGood, nil
+	}
+
+	return git.Bisect(ctx, logger, config.DefaultProjectDir, f.Commit,
+		cfg.BisectCommits, test)
+}
@@ -0,0 +1,80 @@
+package crash
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBisect verifies that the commit introducing a crash is found by running
+// its failing input against the project history, skipping the commits where
+// the package is missing or does not build.
+func TestBisect(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	// Set up a project whose fuzz target was added broken, fixed, and
+	// then started to crash.
+	projectDir := config.DefaultProjectDir
+	repo, err := git.PlainInit(projectDir, false)
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	writeFile(t, projectDir, "go.mod", "module example.com/project\n\n"+
+		"go 1.21\n")
+	require.NoError(t, worktree.AddGlob("."))
+	_, err = worktree.Commit("add module", &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  "fuzz",
+			Email: "fuzz@example.com",
+			When:  time.Now(),
+		},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, os.Mkdir(filepath.Join(projectDir, "foo"), 0755))
+	writeFile(t, projectDir, "foo/foo.go",
+		"package foo\n\nvar x int = \"\"\n")
+	commitFuzzTest(t, repo, projectDir, "fixed")
+	writeFile(t, projectDir, "foo/foo.go", "package foo\n")
+	lastGood := commitFuzzTest(t, repo, projectDir, "fixed")
+	firstBad := commitFuzzTest(t, repo, projectDir, "boom")
+	writeFile(t, projectDir, "foo/foo.go", "package foo\n\nvar x int\n")
+	head := commitFuzzTest(t, repo, projectDir, "boom")
+
+	finding := &Finding{
+		Package: "foo",
+		Target:  "FuzzFoo",
+		InputID: "771e938e4458e983",
+		InputPath: writeFile(t, t.TempDir(), "771e938e4458e983",
+			"go test fuzz v1\nstring(\"boom\")\n"),
+		Commit: head,
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	// Bisection is disabled by default.
+	b, err := Bisect(context.Background(), logger, &config.Config{},
+		finding)
+	require.NoError(t, err)
+	assert.Nil(t, b)
+
+	b, err = Bisect(context.Background(), logger,
+		&config.Config{BisectCommits: 10}, finding)
+	require.NoError(t, err)
+	require.NotNil(t, b)
+	assert.Equal(t, firstBad, b.FirstBad)
+	assert.Equal(t, lastGood, b.LastGood)
+	assert.Empty(t, b.Candidates)
+	assert.Equal(t, 5, b.Searched)
+	assert.Len(t, b.Skipped, 2)
+}
@@ -0,0 +1,207 @@
+package crash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/otiai10/copy"
+)
+
+// State is where a crash stands in its lifecycle.
+type State string
+
+const (
+	// StateNew is a crash found by fuzzing and not replayed since.
+	StateNew State = "new"
+
+	// StateReproducing is a crash found again, by fuzzing or by replaying
+	// its failing inputs against a later commit.
+	StateReproducing State = "reproducing"
+
+	// StateFixed is a crash whose failing inputs no longer reproduce it.
+	StateFixed State = "fixed"
+)
+
+// Transition is a change of the state of a crash.
+type Transition struct {
+	// Record is the crash, once updated.
+	Record *Record
+
+	// From is the state the crash left.
+	From State
+
+	// To is the state the crash entered.
+	To State
+}
+
+// Replay runs the failing inputs of every crash that is not fixed against
+// the given commit of the project cloned in config.DefaultProjectDir, with
+// "go test -run", and moves the crash to the reproducing state if one of them
+// still fails, or to the fixed state, recording the commit, if none does.
+// Each input is copied to the testdata/fuzz directory of its package for the
+// run, then removed. Crashes which did not fail every verification run are
+// given as many runs as verification before being considered fixed. Crashes
+// without a runnable input, e.g. hangs or crashes whose fuzz target is gone,
+// keep their state, and so do the crashes left once the context is done, e.g.
+// when the time budgeted for the replay is spent. It returns the state
+// changes.
+func (s *Store) Replay(ctx context.Context, logger *slog.Logger,
+	cfg *config.Config, commit string) ([]Transition, error) {
+
+	records, err := s.Records()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]bool)
+	for _, record := range records {
+		if record.State == StateFixed || len(record.Inputs) == 0 {
+			continue
+		}
+
+		if ctx.Err() != nil {
+			logger.Warn("No time left to replay crash",
+				"signature", record.Signature)
+			continue
+		}
+
+		runs := 1
+		if v := record.Verification; v != nil &&
+			v.Label != Reproducible {
+
+			runs = max(cfg.VerifyRuns, 1)
+		}
+
+		reproduced, replayed, err := s.replayInputs(ctx, cfg, record,
+			runs)
+		if err != nil && ctx.Err() != nil {
+			logger.Warn("No time left to replay crash",
+				"signature", record.Signature)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !replayed {
+			logger.Warn("No failing input of the crash could be "+
+				"replayed", "signature", record.Signature)
+			continue
+		}
+		results[record.Signature] = reproduced
+	}
+
+	return s.applyReplay(results, commit)
+}
+
+// replayInputs runs the failing inputs of the crash, each up to the given
+// number of times, until one fails. It reports whether an input failed, and
+// whether any input could be run at all.
+func (s *Store) replayInputs(ctx context.Context, cfg *config.Config,
+	record *Record, runs int) (bool, bool, error) {
+
+	replayed := false
+	for _, input := range record.Inputs {
+		pkgDir := filepath.Join(config.DefaultProjectDir, input.Package)
+		if _, err := os.Stat(pkgDir); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+
+		for range runs {
+			failed, err := s.replayInput(ctx, cfg, pkgDir, input)
+			if errors.Is(err, errBuildFailed) ||
+				errors.Is(err, errInputNotRun) {
+
+				break
+			}
+			if err != nil {
+				return false, false, err
+			}
+
+			replayed = true
+			if failed {
+				return true, true, nil
+			}
+		}
+	}
+
+	return false, replayed, nil
+}
+
+// replayInput runs the failing input once, from the corpus of its fuzz target
+// in the package directory, and reports whether it failed. An input already
+// part of the corpus is left in place.
+func (s *Store) replayInput(ctx context.Context, cfg *config.Config,
+	pkgDir string, input Input) (bool, error) {
+
+	inputPath := filepath.Join(pkgDir, "testdata", "fuzz", input.Target,
+		input.ID)
+	if _, err := os.Stat(inputPath); errors.Is(err, os.ErrNotExist) {
+		err := copy.Copy(filepath.Join(s.dir, input.Path), inputPath)
+		if err != nil {
+			return false, fmt.Errorf("failed to copy failing "+
+				"input: %w", err)
+		}
+		defer os.Remove(inputPath)
+	}
+
+	return runInput(ctx, pkgDir, input.Target, input.ID,
+		runOptions(cfg, input.Package, input.Target))
+}
+
+// applyReplay moves every replayed crash to its new state, given whether its
+// inputs still reproduce it at the commit, and returns the state changes.
+func (s *Store) applyReplay(results map[string]bool,
+	commit string) ([]Transition, error) {
+
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	records, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var transitions []Transition
+	now := time.Now().UTC()
+	for signature, reproduced := range results {
+		record, ok := records[signature]
+		if !ok || record.State == StateFixed {
+			continue
+		}
+
+		from := record.State
+		if reproduced {
+			record.State = StateReproducing
+			record.ReproducedCommit = commit
+		} else {
+			record.State = StateFixed
+			record.FixedCommit = commit
+			record.FixedAt = now
+		}
+
+		if record.State != from {
+			transitions = append(transitions, Transition{
+				Record: record,
+				From:   from,
+				To:     record.State,
+			})
+		}
+	}
+
+	if err := s.save(records); err != nil {
+		return nil, err
+	}
+	sort.Slice(transitions, func(i, j int) bool {
+		return transitions[i].Record.Signature <
+			transitions[j].Record.Signature
+	})
+
+	return transitions, nil
+}
@@ -0,0 +1,106 @@
+package crash
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReplay verifies that replaying the failing inputs of the open crashes
+// moves them through their lifecycle, and that a fixed crash found again is
+// reproducing.
+func TestReplay(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	// Set up a project whose fuzz target only panics on "boom".
+	pkgDir := filepath.Join(config.DefaultProjectDir, "foo")
+	require.NoError(t, os.MkdirAll(pkgDir, 0755))
+	writeFile(t, config.DefaultProjectDir, "go.mod",
+		"module example.com/project\n\ngo 1.21\n")
+	writeFile(t, pkgDir, "foo_test.go", fmt.Sprintf(fuzzTestSource, "boom"))
+
+	store := NewStore(&config.Config{FuzzResultsPath: "results"})
+	inputDir := t.TempDir()
+	add := func(signature, input string) {
+		f := &Finding{
+			Signature: signature,
+			Package:   "foo",
+			Target:    "FuzzFoo",
+		}
+		if input != "" {
+			f.InputID = signature
+			f.InputPath = writeFile(t, inputDir, signature,
+				fmt.Sprintf("go test fuzz v1\nstring(%q)\n",
+					input))
+		}
+		_, err := store.Add(f)
+		require.NoError(t, err)
+	}
+	add("reproducing", "boom")
+	add("fixed", "bye")
+	add("hang", "")
+
+	state := func(signature string) *Record {
+		record, err := store.Record(signature)
+		require.NoError(t, err)
+		require.NotNil(t, record)
+
+		return record
+	}
+	for _, signature := range []string{"reproducing", "fixed", "hang"} {
+		assert.Equal(t, StateNew, state(signature).State)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	transitions, err := store.Replay(context.Background(), logger,
+		&config.Config{}, "0a1b2c3d")
+	require.NoError(t, err)
+	require.Len(t, transitions, 2)
+	assert.Equal(t, "fixed", transitions[0].Record.Signature)
+	assert.Equal(t, StateNew, transitions[0].From)
+	assert.Equal(t, StateFixed, transitions[0].To)
+	assert.Equal(t, "reproducing", transitions[1].Record.Signature)
+	assert.Equal(t, StateReproducing, transitions[1].To)
+
+	assert.Equal(t, "0a1b2c3d", state("reproducing").ReproducedCommit)
+	assert.Equal(t, "0a1b2c3d", state("fixed").FixedCommit)
+	assert.False(t, state("fixed").FixedAt.IsZero())
+	assert.Equal(t, StateNew, state("hang").State)
+
+	// The replayed inputs are removed from the project.
+	entries, err := os.ReadDir(filepath.Join(pkgDir, "testdata", "fuzz",
+		"FuzzFoo"))
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	// Replaying again changes nothing.
+	transitions, err = store.Replay(context.Background(), logger,
+		&config.Config{}, "4e5f6a7b")
+	require.NoError(t, err)
+	assert.Empty(t, transitions)
+	assert.Equal(t, "4e5f6a7b", state("reproducing").ReproducedCommit)
+	assert.Equal(t, "0a1b2c3d", state("fixed").FixedCommit)
+
+	// Crashes left once the time is up keep their state.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	transitions, err = store.Replay(ctx, logger, &config.Config{},
+		"8a9b0c1d")
+	require.NoError(t, err)
+	assert.Empty(t, transitions)
+	assert.Equal(t, StateReproducing, state("reproducing").State)
+	assert.Equal(t, "4e5f6a7b", state("reproducing").ReproducedCommit)
+
+	// A fixed crash found again has regressed.
+	add("fixed", "bye")
+	assert.Equal(t, StateReproducing, state("fixed").State)
+	assert.Empty(t, state("fixed").FixedCommit)
+}
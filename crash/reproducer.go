@@ -0,0 +1,214 @@
+package crash
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/otiai10/copy"
+)
+
+const (
+	// ReproducerDirName is the directory, relative to the directory of a
+	// crash in the database, holding its reproducer bundle.
+	ReproducerDirName = "reproducer"
+
+	// reproducerFileName is the name of the JSON file of a reproducer
+	// bundle describing the crash and how it was found.
+	reproducerFileName = "reproducer.json"
+
+	// reproducerScriptName is the name of the script of a reproducer
+	// bundle running the failing input.
+	reproducerScriptName = "reproduce.sh"
+)
+
+// templateFS holds the template of the reproducer script, "reproduce.sh".
+//
+//go:embed templates/reproduce.sh
+var templateFS embed.FS
+
+// scriptTemplate is the parsed reproducer script template.
+var scriptTemplate = template.Must(template.New("").Funcs(template.FuncMap{
+	"quote": shellQuote,
+}).ParseFS(templateFS, "templates/reproduce.sh"))
+
+// reproducer describes the crash reproduced by a bundle.
+type reproducer struct {
+	// Signature is the stack based identifier of the crash.
+	Signature string `json:"signature"`
+
+	// Class is the kind of failure, e.g. "panic" or "timeout".
+	Class string `json:"class"`
+
+	// Summary is the normalized one line description of the crash.
+	Summary string `json:"summary"`
+
+	// Repository is the Git repository URL of the fuzzed project,
+	// without credentials.
+	Repository string `json:"repository"`
+
+	// Commit is the project commit being fuzzed when the crash was found,
+	// if known.
+	Commit string `json:"commit,omitempty"`
+
+	// GoVersion is the version of the Go toolchain the crash was found
+	// with, if known.
+	GoVersion string `json:"go_version,omitempty"`
+
+	// Package is the package containing the fuzz target.
+	Package string `json:"package"`
+
+	// Target is the name of the fuzz target.
+	Target string `json:"target"`
+
+	// InputID is the name of the failing input file.
+	InputID string `json:"input_id"`
+
+	// RunPattern is the -run pattern of "go test" running the failing
+	// input alone.
+	RunPattern string `json:"run_pattern"`
+
+	// BuildTags are the build tags the fuzz target was built with.
+	BuildTags []string `json:"build_tags,omitempty"`
+
+	// Race indicates that the fuzz target ran with the race detector.
+	Race bool `json:"race,omitempty"`
+
+	// Env lists the "KEY=VALUE" variables added to the environment of the
+	// fuzz target.
+	Env []string `json:"env,omitempty"`
+}
+
+// BuildFlags returns the flags of "go test" building the package as it was
+// while fuzzing.
+func (r *reproducer) BuildFlags() []string {
+	opts := config.TargetOptions{BuildTags: r.BuildTags, Race: r.Race}
+
+	return opts.BuildFlags()
+}
+
+// Toolchain returns the GOTOOLCHAIN value selecting the Go toolchain the crash
+// was found with, or an empty string for development versions, which cannot
+// be selected that way.
+func (r *reproducer) Toolchain() string {
+	// The version may be followed by the enabled experiments, e.g.
+	// "go1.22.3 X:rangefunc".
+	version, _, _ := strings.Cut(r.GoVersion, " ")
+	if !strings.HasPrefix(version, "go1.") {
+		return ""
+	}
+
+	return version
+}
+
+// writeReproducer writes the reproducer bundle of the crash for the input of
+// the finding, which must already be in the database, to
+// <signature>/reproducer. The bundle is self-contained: it holds the input
+// under testdata/fuzz/<target>, a JSON description of the crash, and a script
+// checking out the fuzzed commit and running the input as a test. It replaces
+// any previous bundle of the crash, so that it reproduces the latest
+// occurrence. The bundle path, relative to the database, is returned.
+func (s *Store) writeReproducer(record *Record, f *Finding,
+	inputPath string) (string, error) {
+
+	opts := s.cfg.Target(f.Package, f.Target)
+	r := &reproducer{
+		Signature:  record.Signature,
+		Class:      record.Class,
+		Summary:    record.Summary,
+		Repository: publicURL(s.repository),
+		Commit:     f.Commit,
+		GoVersion:  f.GoVersion,
+		Package:    f.Package,
+		Target:     f.Target,
+		InputID:    f.InputID,
+		RunPattern: runPattern(f.Target, f.InputID),
+		BuildTags:  opts.BuildTags,
+		Race:       opts.Race,
+		Env:        opts.Env,
+	}
+
+	relDir := filepath.Join(record.Signature, ReproducerDirName)
+	bundleDir := filepath.Join(s.dir, relDir)
+
+	// Build the bundle aside, so that a failure leaves the previous one
+	// untouched.
+	tmpDir := bundleDir + ".tmp"
+	if err := os.RemoveAll(tmpDir); err != nil {
+		return "", fmt.Errorf("failed to clear reproducer: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	err := copy.Copy(filepath.Join(s.dir, inputPath), filepath.Join(tmpDir,
+		"testdata", "fuzz", f.Target, f.InputID))
+	if err != nil {
+		return "", fmt.Errorf("failed to copy reproducer input: %w",
+			err)
+	}
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode reproducer: %w", err)
+	}
+	err = os.WriteFile(filepath.Join(tmpDir, reproducerFileName), data,
+		0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to write reproducer: %w", err)
+	}
+
+	var script strings.Builder
+	err = scriptTemplate.ExecuteTemplate(&script, reproducerScriptName, r)
+	if err != nil {
+		return "", fmt.Errorf("failed to render reproducer script: %w",
+			err)
+	}
+	err = os.WriteFile(filepath.Join(tmpDir, reproducerScriptName),
+		[]byte(script.String()), 0755)
+	if err != nil {
+		return "", fmt.Errorf("failed to write reproducer script: %w",
+			err)
+	}
+
+	if err := os.RemoveAll(bundleDir); err != nil {
+		return "", fmt.Errorf("failed to remove reproducer: %w", err)
+	}
+	if err := os.Rename(tmpDir, bundleDir); err != nil {
+		return "", fmt.Errorf("failed to replace reproducer: %w", err)
+	}
+
+	return relDir, nil
+}
+
+// ReproducerScript returns the path of the script of the reproducer bundle of
+// the crash, or an empty string if the crash has none.
+func (s *Store) ReproducerScript(record *Record) string {
+	if record.Reproducer == "" {
+		return ""
+	}
+
+	return filepath.Join(s.dir, record.Reproducer, reproducerScriptName)
+}
+
+// shellQuote quotes the string as a single POSIX shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// publicURL returns the repository URL without the credentials it may embed,
+// e.g. a GitHub Personal Access Token, which must not end up in a bundle meant
+// to be shared. If the URL cannot be parsed, it is returned as is.
+func publicURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	parsed.User = nil
+
+	return parsed.String()
+}
@@ -0,0 +1,566 @@
+package crash
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/NishantBansal2003/LND-Fuzz/git"
+	"github.com/otiai10/copy"
+)
+
+const (
+	// DirName is the directory, relative to the fuzz results path, where
+	// the crash database is stored.
+	DirName = "crashes"
+
+	// indexFileName is the name of the JSON file holding every known crash
+	// record, keyed by signature.
+	indexFileName = "index.json"
+)
+
+// storeMu serializes every read-modify-write of the crash database. Fuzz
+// targets run concurrently and each reports its failures independently, so
+// the lock is shared by all Store values.
+var storeMu sync.Mutex
+
+// Input is a failing input that reproduces a crash.
+type Input struct {
+	// Package is the package containing the fuzz target.
+	Package string `json:"package"`
+
+	// Target is the name of the fuzz target that found the input.
+	Target string `json:"target"`
+
+	// ID is the name of the input file in the fuzz corpus.
+	ID string `json:"id"`
+
+	// Path is the location of the stored input, relative to the crash
+	// database directory.
+	Path string `json:"path"`
+
+	// FoundAt is the time the input was first recorded.
+	FoundAt time.Time `json:"found_at"`
+
+	// Commit is the project commit being fuzzed when the input was
+	// found, if known.
+	Commit string `json:"commit,omitempty"`
+
+	// GoVersion is the version of the Go toolchain the input was found
+	// with, if known.
+	GoVersion string `json:"go_version,omitempty"`
+}
+
+// Location is the source position of the innermost stack frame of a crash
+// that belongs to the fuzzed project or its dependencies.
+type Location struct {
+	// Function is the fully qualified name of the crashing function.
+	Function string `json:"function"`
+
+	// File is the slash-separated path of the source file, relative to
+	// the root of the project if the file belongs to it, absolute
+	// otherwise.
+	File string `json:"file"`
+
+	// Line is the line number within File.
+	Line int `json:"line"`
+}
+
+// Record holds everything known about a single crash signature.
+type Record struct {
+	// Signature is the stack based identifier of the crash.
+	Signature string `json:"signature"`
+
+	// Class is the kind of failure, e.g. "panic" or "timeout".
+	Class string `json:"class"`
+
+	// Priority is the triage priority of the crash class, 1 being the
+	// most urgent.
+	Priority int `json:"priority"`
+
+	// Summary is the normalized one line description of the crash.
+	Summary string `json:"summary"`
+
+	// FirstSeen is the time the crash was first recorded.
+	FirstSeen time.Time `json:"first_seen"`
+
+	// LastSeen is the time the crash was most recently recorded.
+	LastSeen time.Time `json:"last_seen"`
+
+	// Hits counts how many times the crash has been recorded.
+	Hits int `json:"hits"`
+
+	// Targets lists the "<pkg>/<target>" fuzz targets that hit the crash.
+	Targets []string `json:"targets"`
+
+	// Inputs lists every distinct input that reproduced the crash.
+	Inputs []Input `json:"inputs"`
+
+	// LogPath is the location of the most recent failure log, relative to
+	// the crash database directory.
+	LogPath string `json:"log_path"`
+
+	// Location is where the crash most recently occurred, nil if the
+	// failure had no stack trace.
+	Location *Location `json:"location,omitempty"`
+
+	// Commit is the project commit being fuzzed when the crash was most
+	// recently recorded, if known.
+	Commit string `json:"commit,omitempty"`
+
+	// Issue is the number of the GitHub issue filed for the crash, zero if
+	// none was filed.
+	Issue int `json:"issue,omitempty"`
+
+	// IssueClosed indicates that the issue filed for the crash was closed
+	// once the crash was fixed.
+	IssueClosed bool `json:"issue_closed,omitempty"`
+
+	// LastCommented is the project commit the issue filed for the crash
+	// was last opened or commented on for, so that the issue gets at most
+	// one comment per fuzzed commit however often the crash recurs.
+	LastCommented string `json:"last_commented,omitempty"`
+
+	// State is where the crash stands in its lifecycle, see Replay.
+	State State `json:"state"`
+
+	// ReproducedCommit is the most recent project commit the failing
+	// inputs of the crash were replayed against and still reproduced it,
+	// empty if they never were.
+	ReproducedCommit string `json:"reproduced_commit,omitempty"`
+
+	// FixedCommit is the project commit the crash stopped reproducing at,
+	// set once it is fixed.
+	FixedCommit string `json:"fixed_commit,omitempty"`
+
+	// FixedAt is when the crash was found fixed.
+	FixedAt time.Time `json:"fixed_at,omitzero"`
+
+	// Verification is the outcome of re-running the failing input of the
+	// crash when it was first found, nil if it was not verified.
+	Verification *Verification `json:"verification,omitempty"`
+
+	// Bisection is the outcome of searching the project history for the
+	// commit introducing the crash, nil if it was not bisected.
+	Bisection *git.Bisection `json:"bisection,omitempty"`
+
+	// Reproducer is the location of the reproducer bundle of the most
+	// recent failing input, relative to the crash database directory. It
+	// is empty if the crash never produced an input file.
+	Reproducer string `json:"reproducer,omitempty"`
+}
+
+// Finding describes a single failure observed while fuzzing a target.
+type Finding struct {
+	// Signature is the stack based identifier of the crash.
+	Signature string
+
+	// Class is the kind of failure, e.g. "panic" or "timeout".
+	Class string
+
+	// Priority is the triage priority of the crash class, 1 being the
+	// most urgent.
+	Priority int
+
+	// Summary is the normalized one line description of the crash.
+	Summary string
+
+	// Package is the package containing the fuzz target.
+	Package string
+
+	// Target is the name of the fuzz target.
+	Target string
+
+	// InputID is the name of the failing input file. It is empty if the
+	// failure did not produce an input file.
+	InputID string
+
+	// InputPath is the path of the failing input file. It is empty if the
+	// failure did not produce an input file.
+	InputPath string
+
+	// LogPath is the path of the failure log written for this failure.
+	LogPath string
+
+	// Commit is the project commit being fuzzed, if known.
+	Commit string
+
+	// GoVersion is the version of the Go toolchain fuzzing the target, if
+	// known.
+	GoVersion string
+
+	// Location is where the crash occurred, nil if the failure has no
+	// stack trace.
+	Location *Location
+}
+
+// Store is a crash database kept on disk, which deduplicates failures across
+// fuzz targets and cycles by their stack signature.
+type Store struct {
+	// dir is the directory holding the index and the per-crash data.
+	dir string
+
+	// repository is the Git repository URL of the fuzzed project, which
+	// reproducer bundles clone.
+	repository string
+
+	// cfg is the configuration giving the options the fuzz targets run
+	// with, which reproducer bundles run their input with.
+	cfg *config.Config
+}
+
+// NewStore returns the crash database located under the configured fuzz
+// results path.
+func NewStore(cfg *config.Config) *Store {
+	return &Store{
+		dir:        filepath.Join(cfg.FuzzResultsPath, DirName),
+		repository: cfg.ProjectSrcPath,
+		cfg:        cfg,
+	}
+}
+
+// Dir returns the directory holding the crash database.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Add records the finding in the database, copying its input and failure log
+// next to the crash record, and writes the reproducer bundle of its input, see
+// writeReproducer. It returns true if the signature has never been seen
+// before, i.e. if the finding is a new bug.
+func (s *Store) Add(f *Finding) (bool, error) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	records, err := s.load()
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now().UTC()
+	record, known := records[f.Signature]
+	if !known {
+		record = &Record{
+			Signature: f.Signature,
+			Class:     f.Class,
+			Priority:  f.Priority,
+			Summary:   f.Summary,
+			FirstSeen: now,
+			State:     StateNew,
+		}
+		records[f.Signature] = record
+	} else {
+		// Finding the crash again shows it still reproduces, or that
+		// a fixed crash has regressed.
+		record.State = StateReproducing
+		record.FixedCommit = ""
+		record.FixedAt = time.Time{}
+	}
+	record.LastSeen = now
+	record.Hits++
+	if f.Location != nil {
+		record.Location = f.Location
+	}
+	if f.Commit != "" {
+		record.Commit = f.Commit
+	}
+
+	targetName := fmt.Sprintf("%s/%s", f.Package, f.Target)
+	if !slices.Contains(record.Targets, targetName) {
+		record.Targets = append(record.Targets, targetName)
+	}
+
+	if f.InputPath != "" {
+		inputPath, err := s.addInput(record, f, now)
+		if err != nil {
+			return false, err
+		}
+
+		record.Reproducer, err = s.writeReproducer(record, f,
+			inputPath)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if f.LogPath != "" {
+		logPath := filepath.Join(f.Signature,
+			filepath.Base(f.LogPath))
+		if err := copy.Copy(f.LogPath,
+			filepath.Join(s.dir, logPath)); err != nil {
+
+			return false, fmt.Errorf("failed to copy failure log: "+
+				"%w", err)
+		}
+		record.LogPath = logPath
+	}
+
+	if err := s.save(records); err != nil {
+		return false, err
+	}
+
+	return !known, nil
+}
+
+// addInput copies the failing input of the finding into the crash directory,
+// unless the same input was already recorded. It returns the location of the
+// stored input, relative to the crash database directory.
+func (s *Store) addInput(record *Record, f *Finding,
+	now time.Time) (string, error) {
+
+	relPath := inputPath(f)
+
+	for _, input := range record.Inputs {
+		if input.Path == relPath {
+			return relPath, nil
+		}
+	}
+
+	if err := copy.Copy(f.InputPath, filepath.Join(s.dir,
+		relPath)); err != nil {
+
+		return "", fmt.Errorf("failed to copy failing input: %w", err)
+	}
+
+	record.Inputs = append(record.Inputs, Input{
+		Package:   f.Package,
+		Target:    f.Target,
+		ID:        f.InputID,
+		Path:      relPath,
+		FoundAt:   now,
+		Commit:    f.Commit,
+		GoVersion: f.GoVersion,
+	})
+
+	return relPath, nil
+}
+
+// inputPath returns the location of the copy of the failing input of the
+// finding, relative to the crash database directory.
+func inputPath(f *Finding) string {
+	return filepath.Join(f.Signature, "inputs", f.Package, f.Target,
+		f.InputID)
+}
+
+// InputPath returns the path of the copy of the failing input of the finding
+// kept in the database, which outlives the testdata of the fuzzed package.
+func (s *Store) InputPath(f *Finding) string {
+	return filepath.Join(s.dir, inputPath(f))
+}
+
+// Records returns every crash in the database, most recently seen first.
+func (s *Store) Records() ([]*Record, error) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	records, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*Record, 0, len(records))
+	for _, record := range records {
+		list = append(list, record)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].LastSeen.After(list[j].LastSeen)
+	})
+
+	return list, nil
+}
+
+// Record returns the crash with the given signature, or nil if it is unknown.
+func (s *Store) Record(signature string) (*Record, error) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	records, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return records[signature], nil
+}
+
+// Lookup returns the crash whose signature starts with the given prefix, so
+// that crashes can be named by a short signature like Git commits. It fails if
+// no crash or more than one matches.
+func (s *Store) Lookup(prefix string) (*Record, error) {
+	records, err := s.Records()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*Record
+	for _, record := range records {
+		if strings.HasPrefix(record.Signature, prefix) {
+			matches = append(matches, record)
+		}
+	}
+
+	switch {
+	case prefix == "" || len(matches) == 0:
+		return nil, fmt.Errorf("no crash matches %q", prefix)
+	case len(matches) > 1:
+		return nil, fmt.Errorf("%d crashes match %q, give more of the "+
+			"signature", len(matches), prefix)
+	}
+
+	return matches[0], nil
+}
+
+// SetIssue records the number of the GitHub issue filed for the crash with the
+// given signature.
+func (s *Store) SetIssue(signature string, issue int) error {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	records, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	record, ok := records[signature]
+	if !ok {
+		return fmt.Errorf("unknown crash signature %q", signature)
+	}
+	record.Issue = issue
+
+	return s.save(records)
+}
+
+// SetVerification records the outcome of re-running the failing input of the
+// crash with the given signature.
+func (s *Store) SetVerification(signature string, v *Verification) error {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	records, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	record, ok := records[signature]
+	if !ok {
+		return fmt.Errorf("unknown crash signature %q", signature)
+	}
+	record.Verification = v
+
+	return s.save(records)
+}
+
+// SetBisection records the commit introducing the crash with the given
+// signature, as found by Bisect.
+func (s *Store) SetBisection(signature string, b *git.Bisection) error {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	records, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	record, ok := records[signature]
+	if !ok {
+		return fmt.Errorf("unknown crash signature %q", signature)
+	}
+	record.Bisection = b
+
+	return s.save(records)
+}
+
+// SetLastCommented records the project commit the issue filed for the crash
+// with the given signature was last opened or commented on for.
+func (s *Store) SetLastCommented(signature, commit string) error {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	records, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	record, ok := records[signature]
+	if !ok {
+		return fmt.Errorf("unknown crash signature %q", signature)
+	}
+	record.LastCommented = commit
+
+	return s.save(records)
+}
+
+// SetIssueClosed records whether the GitHub issue filed for the crash with the
+// given signature is closed.
+func (s *Store) SetIssueClosed(signature string, closed bool) error {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	records, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	record, ok := records[signature]
+	if !ok {
+		return fmt.Errorf("unknown crash signature %q", signature)
+	}
+	record.IssueClosed = closed
+
+	return s.save(records)
+}
+
+// load reads the crash index from disk. A missing index yields an empty
+// database. Crashes recorded before their lifecycle was tracked are new.
+func (s *Store) load() (map[string]*Record, error) {
+	records := make(map[string]*Record)
+
+	data, err := os.ReadFile(filepath.Join(s.dir, indexFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return records, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read crash index: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse crash index: %w", err)
+	}
+	for _, record := range records {
+		if record.State == "" {
+			record.State = StateNew
+		}
+	}
+
+	return records, nil
+}
+
+// save atomically writes the crash index to disk.
+func (s *Store) save(records map[string]*Record) error {
+	if err := config.EnsureDirExists(s.dir); err != nil {
+		return fmt.Errorf("failed to create crash directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode crash index: %w", err)
+	}
+
+	indexPath := filepath.Join(s.dir, indexFileName)
+	tmpPath := indexPath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write crash index: %w", err)
+	}
+	if err := os.Rename(tmpPath, indexPath); err != nil {
+		return fmt.Errorf("failed to replace crash index: %w", err)
+	}
+
+	return nil
+}
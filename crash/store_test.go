@@ -0,0 +1,209 @@
+package crash
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFile creates a file with the given content inside dir and returns its
+// path.
+func writeFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	return path
+}
+
+// TestStoreAdd verifies that the crash database deduplicates findings by
+// signature, tracks hit counts and affected targets, and keeps every distinct
+// reproducing input.
+func TestStoreAdd(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewStore(&config.Config{
+		FuzzResultsPath: filepath.Join(tmpDir, "results"),
+	})
+
+	inputPath := writeFile(t, tmpDir, "771e938e4458e983",
+		"go test fuzz v1\nstring(\"0\")\n")
+	logPath := writeFile(t, tmpDir, "FuzzFoo_failure.log", "--- FAIL:\n")
+
+	finding := &Finding{
+		Signature: "0123456789abcdef",
+		Class:     "panic",
+		Priority:  1,
+		Summary:   "panic: boom",
+		Package:   "foo",
+		Target:    "FuzzFoo",
+		InputID:   "771e938e4458e983",
+		InputPath: inputPath,
+		LogPath:   logPath,
+		Commit:    "0a1b2c3d",
+		Location: &Location{
+			Function: "foo.Parse",
+			File:     "foo/parse.go",
+			Line:     42,
+		},
+	}
+
+	// The first occurrence of a signature is a new finding.
+	isNew, err := store.Add(finding)
+	require.NoError(t, err)
+	assert.True(t, isNew)
+
+	// Recording the same failure again is not.
+	isNew, err = store.Add(finding)
+	require.NoError(t, err)
+	assert.False(t, isNew)
+
+	// Neither is the same crash hit by another target without an input.
+	isNew, err = store.Add(&Finding{
+		Signature: finding.Signature,
+		Class:     finding.Class,
+		Summary:   finding.Summary,
+		Package:   "bar",
+		Target:    "FuzzBar",
+	})
+	require.NoError(t, err)
+	assert.False(t, isNew)
+
+	// A different signature is a new finding.
+	isNew, err = store.Add(&Finding{
+		Signature: "fedcba9876543210",
+		Package:   "foo",
+		Target:    "FuzzFoo",
+	})
+	require.NoError(t, err)
+	assert.True(t, isNew)
+
+	records, err := store.Records()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	var record *Record
+	for _, r := range records {
+		if r.Signature == finding.Signature {
+			record = r
+		}
+	}
+	require.NotNil(t, record)
+
+	assert.Equal(t, 3, record.Hits)
+	assert.Equal(t, "panic", record.Class)
+	assert.Equal(t, "panic: boom", record.Summary)
+	assert.Equal(t, []string{"foo/FuzzFoo", "bar/FuzzBar"},
+		record.Targets)
+	assert.False(t, record.LastSeen.Before(record.FirstSeen))
+
+	// The location and commit of the last occurrence with a stack trace
+	// are kept.
+	assert.Equal(t, finding.Location, record.Location)
+	assert.Equal(t, "0a1b2c3d", record.Commit)
+
+	require.Len(t, record.Inputs, 1)
+	assert.Equal(t, filepath.Join(store.Dir(), record.Inputs[0].Path),
+		store.InputPath(finding))
+	data, err := os.ReadFile(store.InputPath(finding))
+	require.NoError(t, err)
+	assert.Equal(t, "go test fuzz v1\nstring(\"0\")\n", string(data))
+
+	data, err = os.ReadFile(filepath.Join(store.Dir(), record.LogPath))
+	require.NoError(t, err)
+	assert.Equal(t, "--- FAIL:\n", string(data))
+}
+
+// TestStoreSetIssue verifies that the issue filed for a crash is kept with its
+// record.
+func TestStoreSetIssue(t *testing.T) {
+	store := NewStore(&config.Config{FuzzResultsPath: t.TempDir()})
+
+	record, err := store.Record("0123456789abcdef")
+	require.NoError(t, err)
+	assert.Nil(t, record)
+	assert.Error(t, store.SetIssue("0123456789abcdef", 42))
+
+	_, err = store.Add(&Finding{
+		Signature: "0123456789abcdef",
+		Package:   "foo",
+		Target:    "FuzzFoo",
+	})
+	require.NoError(t, err)
+	require.NoError(t, store.SetIssue("0123456789abcdef", 42))
+
+	record, err = store.Record("0123456789abcdef")
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, 42, record.Issue)
+	assert.Equal(t, 1, record.Hits)
+	assert.Empty(t, record.LastCommented)
+
+	require.NoError(t, store.SetLastCommented("0123456789abcdef",
+		"0a1b2c3d"))
+	record, err = store.Record("0123456789abcdef")
+	require.NoError(t, err)
+	assert.Equal(t, "0a1b2c3d", record.LastCommented)
+}
+
+// TestStoreLookup verifies that a crash is found by a unique prefix of its
+// signature.
+func TestStoreLookup(t *testing.T) {
+	store := NewStore(&config.Config{FuzzResultsPath: t.TempDir()})
+	for _, signature := range []string{"0123aaaa", "0123bbbb", "4567cccc"} {
+		_, err := store.Add(&Finding{
+			Signature: signature,
+			Package:   "foo",
+			Target:    "FuzzFoo",
+		})
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name      string
+		prefix    string
+		signature string
+		wantErr   bool
+	}{
+		{
+			name:      "full signature",
+			prefix:    "0123aaaa",
+			signature: "0123aaaa",
+		},
+		{
+			name:      "unique prefix",
+			prefix:    "45",
+			signature: "4567cccc",
+		},
+		{
+			name:    "ambiguous prefix",
+			prefix:  "0123",
+			wantErr: true,
+		},
+		{
+			name:    "unknown prefix",
+			prefix:  "89",
+			wantErr: true,
+		},
+		{
+			name:    "empty prefix",
+			prefix:  "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record, err := store.Lookup(tt.prefix)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.signature, record.Signature)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+#!/bin/sh
+#
+# Reproduces crash {{.Signature}} ({{.Class}}) found by {{.Target}} in
+# {{.Package}}.
+#
+# Usage: ./reproduce.sh [PROJECT_DIR]
+#
+# PROJECT_DIR is a clone of the fuzzed project. Without it, the project is
+# cloned from $REPOSITORY into a temporary directory. The commit the crash was
+# found at is checked out, the failing input is copied into the testdata of the
+# package and run as a regular test, with the Go toolchain used while fuzzing
+# unless GOTOOLCHAIN is set, and the build flags and environment of the fuzz
+# target.
+set -eu
+
+BUNDLE_DIR=$(cd "$(dirname "$0")" && pwd)
+REPOSITORY=${REPOSITORY:-{{quote .Repository}}}
+COMMIT={{quote .Commit}}
+PACKAGE={{quote .Package}}
+TARGET={{quote .Target}}
+INPUT={{quote .InputID}}
+{{- if .Toolchain}}
+export GOTOOLCHAIN="${GOTOOLCHAIN:-{{.Toolchain}}}"
+{{- end}}
+{{- range .Env}}
+export {{quote .}}
+{{- end}}
+
+if [ $# -ge 1 ]; then
+	PROJECT_DIR=$1
+else
+	PROJECT_DIR=$(mktemp -d)
+	git clone --quiet "$REPOSITORY" "$PROJECT_DIR"
+fi
+
+cd "$PROJECT_DIR"
+if [ -n "$COMMIT" ]; then
+	git checkout --quiet "$COMMIT"
+fi
+
+mkdir -p "$PACKAGE/testdata/fuzz/$TARGET"
+cp "$BUNDLE_DIR/testdata/fuzz/$TARGET/$INPUT" "$PACKAGE/testdata/fuzz/$TARGET/"
+
+cd "$PACKAGE"
+go version
+exec go test -run={{quote .RunPattern}}{{range .BuildFlags}} {{quote .}}{{end}} .
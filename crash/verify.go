@@ -0,0 +1,189 @@
+package crash
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"time"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+)
+
+const (
+	// verifyTimeout bounds each verification run, so that an input
+	// hanging the fuzz target fails it instead of blocking the cycle.
+	verifyTimeout = time.Minute
+
+	// verifyWaitDelay is how long a verification run may keep its output
+	// open once the go command has been killed, e.g. by a test binary it
+	// left behind.
+	verifyWaitDelay = 10 * time.Second
+)
+
+var (
+	// errBuildFailed is returned when the package of the fuzz target does
+	// not build, so the failing input could not be run.
+	errBuildFailed = errors.New("package does not build")
+
+	// errInputNotRun is returned when "go test" did not run the failing
+	// input, e.g. because the fuzz target does not exist.
+	errInputNotRun = errors.New("input was not run")
+)
+
+// Reproducibility labels how reliably the failing input of a crash fails
+// again when run deterministically.
+type Reproducibility string
+
+const (
+	// Reproducible is a crash whose input failed every verification run.
+	Reproducible Reproducibility = "reproducible"
+
+	// Flaky is a crash whose input failed some verification runs only.
+	Flaky Reproducibility = "flaky"
+
+	// NonReproducible is a crash whose input never failed again.
+	NonReproducible Reproducibility = "non-reproducible"
+)
+
+// Verification is the outcome of re-running the failing input of a crash.
+type Verification struct {
+	// Runs is the number of verification runs.
+	Runs int `json:"runs"`
+
+	// Failures is the number of runs in which the input failed.
+	Failures int `json:"failures"`
+
+	// Race indicates that the runs used the race detector.
+	Race bool `json:"race,omitempty"`
+
+	// Label classifies the crash by the share of failed runs.
+	Label Reproducibility `json:"label"`
+
+	// Time is when the verification finished.
+	Time time.Time `json:"time"`
+}
+
+// Rate returns the share of verification runs in which the input failed.
+func (v *Verification) Rate() float64 {
+	if v.Runs == 0 {
+		return 0
+	}
+
+	return float64(v.Failures) / float64(v.Runs)
+}
+
+// Verify re-runs the failing input of a crash of the fuzz target of the
+// package, stored in the testdata of the package in pkgDir, as many times as
+// configured, with "go test -run" and the options of the fuzz target, see
+// runOptions. Every run is a separate process, so that a crash aborting the
+// test binary does not cut the others short. It returns nil if verification is
+// disabled, and an error if the input could not be run.
+func Verify(ctx context.Context, cfg *config.Config, pkgDir, pkg, target,
+	inputID string) (*Verification, error) {
+
+	if cfg.VerifyRuns == 0 {
+		return nil, nil
+	}
+
+	opts := runOptions(cfg, pkg, target)
+	v := &Verification{Runs: cfg.VerifyRuns, Race: opts.Race}
+	for range cfg.VerifyRuns {
+		failed, err := runInput(ctx, pkgDir, target, inputID, opts)
+		if err != nil {
+			return nil, err
+		}
+		if failed {
+			v.Failures++
+		}
+	}
+
+	switch v.Failures {
+	case v.Runs:
+		v.Label = Reproducible
+	case 0:
+		v.Label = NonReproducible
+	default:
+		v.Label = Flaky
+	}
+	v.Time = time.Now().UTC()
+
+	return v, nil
+}
+
+// runOptions returns the options the failing inputs of the fuzz target of the
+// package are run with: its build tags and environment, and the race detector
+// if it is fuzzed with it or if enabled for the verification runs.
+func runOptions(cfg *config.Config, pkg, target string) config.TargetOptions {
+	opts := cfg.Target(pkg, target)
+	opts.Race = opts.Race || cfg.VerifyRace
+
+	return opts
+}
+
+// runInput runs the failing input once as a regular test of the package in
+// pkgDir, with the build tags, environment and race detector of the options,
+// and reports whether it failed.
+func runInput(ctx context.Context, pkgDir, target, inputID string,
+	opts config.TargetOptions) (bool, error) {
+
+	// Test results are never cached, every run must execute the input.
+	args := []string{
+		"test",
+		"-run=" + runPattern(target, inputID),
+		"-count=1",
+		"-v",
+		fmt.Sprintf("-timeout=%s", verifyTimeout),
+	}
+	args = append(args, opts.BuildFlags()...)
+	args = append(args, ".")
+
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = pkgDir
+	if len(opts.Env) > 0 {
+		cmd.Env = append(os.Environ(), opts.Env...)
+	}
+	cmd.WaitDelay = verifyWaitDelay
+
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return false, fmt.Errorf("verification interrupted: %w",
+			ctx.Err())
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		// A pattern matching nothing passes too, make sure the input
+		// did run.
+		if !bytes.Contains(output, []byte("=== RUN   "+target+"/"+
+			inputID)) {
+
+			return false, fmt.Errorf("%w: %s/%s", errInputNotRun,
+				target, inputID)
+		}
+
+		return false, nil
+
+	case !errors.As(err, &exitErr):
+		return false, fmt.Errorf("go test failed to run: %w", err)
+
+	case bytes.Contains(output, []byte("[build failed]")),
+		bytes.Contains(output, []byte("[setup failed]")):
+
+		return false, fmt.Errorf("%w: %s", errBuildFailed,
+			bytes.TrimSpace(output))
+	}
+
+	return true, nil
+}
+
+// runPattern returns the -run pattern of "go test" running the failing input
+// of the fuzz target alone.
+func runPattern(target, inputID string) string {
+	return fmt.Sprintf("^%s$/^%s$", regexp.QuoteMeta(target),
+		regexp.QuoteMeta(inputID))
+}
@@ -0,0 +1,158 @@
+package crash
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// verifyTestSource is a package whose fuzz target runs the given body on
+// every input.
+const verifyTestSource = `package foo
+
+import (
+	"os"
+	"testing"
+)
+
+var _ = os.ReadFile
+
+func FuzzFoo(f *testing.F) {
+	f.Fuzz(func(t *testing.T, s string) {
+		%s
+	})
+}
+`
+
+// newVerifyPackage creates a module holding a package whose fuzz target runs
+// the given body, and a failing input of the target, and returns the package
+// directory.
+func newVerifyPackage(t *testing.T, body string) string {
+	dir := t.TempDir()
+	writeFile(t, dir, "go.mod", "module example.com/project\n\ngo 1.21\n")
+	writeFile(t, dir, "foo_test.go", fmt.Sprintf(verifyTestSource, body))
+
+	inputDir := filepath.Join(dir, "testdata", "fuzz", "FuzzFoo")
+	require.NoError(t, os.MkdirAll(inputDir, 0755))
+	writeFile(t, inputDir, "771e938e4458e983",
+		"go test fuzz v1\nstring(\"0\")\n")
+
+	return dir
+}
+
+// TestVerify verifies that failing inputs are labelled by the share of
+// verification runs they fail.
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		inputID  string
+		runs     int
+		env      map[string]string
+		wantErr  string
+		wantNil  bool
+		failures int
+		label    Reproducibility
+	}{
+		{
+			name:     "reproducible",
+			body:     `panic("boom")`,
+			runs:     2,
+			failures: 2,
+			label:    Reproducible,
+		},
+		{
+			name: "flaky",
+			// Fail every other run, using a marker file.
+			body: `if _, err := os.Stat("marker"); err == nil {
+			os.Remove("marker")
+			panic("boom")
+		}
+		os.WriteFile("marker", nil, 0644)`,
+			runs:     4,
+			failures: 2,
+			label:    Flaky,
+		},
+		{
+			name: "target environment",
+			body: `if os.Getenv("FOO_CRASH") == "1" {
+			panic("boom")
+		}`,
+			runs:     2,
+			env:      map[string]string{"FOO_CRASH": "1"},
+			failures: 2,
+			label:    Reproducible,
+		},
+		{
+			name:     "non-reproducible",
+			body:     `_ = s`,
+			runs:     2,
+			failures: 0,
+			label:    NonReproducible,
+		},
+		{
+			name:    "disabled",
+			body:    `panic("boom")`,
+			runs:    0,
+			wantNil: true,
+		},
+		{
+			name:    "missing input",
+			body:    `_ = s`,
+			inputID: "0000000000000000",
+			runs:    1,
+			wantErr: "was not run",
+		},
+		{
+			name:    "build failure",
+			body:    `undefined()`,
+			runs:    1,
+			wantErr: "package does not build",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkgDir := newVerifyPackage(t, tt.body)
+			inputID := tt.inputID
+			if inputID == "" {
+				inputID = "771e938e4458e983"
+			}
+
+			cfg := &config.Config{
+				VerifyRuns: tt.runs,
+				Overrides: map[string]*config.PackageOverride{
+					"foo": {
+						Override: config.Override{
+							Env: tt.env,
+						},
+					},
+				},
+			}
+			v, err := Verify(context.Background(), cfg, pkgDir,
+				"foo", "FuzzFoo", inputID)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			if tt.wantNil {
+				assert.Nil(t, v)
+				return
+			}
+
+			require.NotNil(t, v)
+			assert.Equal(t, tt.runs, v.Runs)
+			assert.Equal(t, tt.failures, v.Failures)
+			assert.Equal(t, tt.label, v.Label)
+			assert.InDelta(t, float64(tt.failures)/float64(tt.runs),
+				v.Rate(), 1e-9)
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package fuzz
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+)
+
+const (
+	// logsDirName is the directory, relative to the fuzz results path,
+	// where the complete output of every fuzz target run is archived.
+	logsDirName = "logs"
+
+	// cycleIDLayout is the time layout used to name each fuzzing cycle.
+	cycleIDLayout = "20060102T150405Z"
+
+	// logTailBytes bounds how much of the end of a target log is read to
+	// extract its last lines.
+	logTailBytes = 64 << 10
+)
+
+// newCycleID returns the identifier of a fuzzing cycle starting at the given
+// time. Identifiers sort chronologically.
+func newCycleID(start time.Time) string {
+	return start.UTC().Format(cycleIDLayout)
+}
+
+// createTargetLog creates the file archiving the complete output of a fuzz
+// target run: <FuzzResultsPath>/logs/<cycle>/<pkg>/<name>.log. The name is the
+// fuzz target name, suffixed for restarts of the target.
+func createTargetLog(cfg *config.Config, cycleID, pkg,
+	name string) (*os.File, error) {
+
+	logDir := filepath.Join(cfg.FuzzResultsPath, logsDirName, cycleID,
+		pkg)
+	if err := config.EnsureDirExists(logDir); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w",
+			err)
+	}
+
+	logFile, err := os.Create(filepath.Join(logDir, name+".log"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create target log: %w", err)
+	}
+
+	return logFile, nil
+}
+
+// readLogTail returns the last n lines of the target log, or an empty string if
+// it cannot be read.
+func readLogTail(path string, n int) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return ""
+	}
+
+	offset := max(info.Size()-logTailBytes, 0)
+	data, err := io.ReadAll(io.NewSectionReader(f, offset,
+		info.Size()-offset))
+	if err != nil {
+		return ""
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	return strings.Join(lines[max(len(lines)-n, 0):], "\n")
+}
+
+// archivedCycle is the log directory of a past fuzzing cycle.
+type archivedCycle struct {
+	path  string
+	start time.Time
+	size  int64
+}
+
+// rotateLogs applies the retention policy to the logs of past cycles: every
+// log of a cycle other than the current one is compressed, cycles older than
+// LogMaxAge are removed, and the oldest cycles are removed until the archive
+// fits in LogMaxSize. Errors are logged and do not interrupt fuzzing.
+func rotateLogs(logger *slog.Logger, cfg *config.Config,
+	currentCycleID string) {
+
+	logsDir := filepath.Join(cfg.FuzzResultsPath, logsDirName)
+	entries, err := os.ReadDir(logsDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Error("Failed to list archived logs", "error",
+				err)
+		}
+		return
+	}
+
+	var (
+		cycles    []archivedCycle
+		totalSize int64
+	)
+	for _, entry := range entries {
+		start, err := time.Parse(cycleIDLayout, entry.Name())
+		if !entry.IsDir() || err != nil ||
+			entry.Name() == currentCycleID {
+
+			continue
+		}
+
+		cyclePath := filepath.Join(logsDir, entry.Name())
+		size, err := compressLogs(cyclePath)
+		if err != nil {
+			logger.Error("Failed to compress archived logs", "path",
+				cyclePath, "error", err)
+		}
+
+		cycles = append(cycles, archivedCycle{
+			path:  cyclePath,
+			start: start,
+			size:  size,
+		})
+		totalSize += size
+	}
+
+	// Remove the oldest cycles first.
+	sort.Slice(cycles, func(i, j int) bool {
+		return cycles[i].start.Before(cycles[j].start)
+	})
+
+	now := time.Now()
+	for _, cycle := range cycles {
+		expired := cfg.LogMaxAge > 0 &&
+			now.Sub(cycle.start) > cfg.LogMaxAge
+		oversized := cfg.LogMaxSize > 0 && totalSize > cfg.LogMaxSize
+		if !expired && !oversized {
+			continue
+		}
+
+		if err := os.RemoveAll(cycle.path); err != nil {
+			logger.Error("Failed to remove archived logs", "path",
+				cycle.path, "error", err)
+			continue
+		}
+		totalSize -= cycle.size
+
+		logger.Info("Removed archived logs", "path", cycle.path,
+			"expired", expired, "oversized", oversized)
+	}
+}
+
+// compressLogs gzips every uncompressed log file under dir, replacing the
+// original, and returns the total size of the directory afterwards.
+func compressLogs(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry,
+		err error) error {
+
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		if strings.HasSuffix(path, ".log") {
+			if err := gzipFile(path); err != nil {
+				return err
+			}
+			path += ".gz"
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+
+		return nil
+	})
+
+	return size, err
+}
+
+// gzipFile compresses the file at path into path.gz and removes the original.
+func gzipFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open log: %w", err)
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path + ".gz")
+	if err != nil {
+		return fmt.Errorf("failed to create compressed log: %w", err)
+	}
+	defer dst.Close()
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		return fmt.Errorf("failed to compress log: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to flush compressed log: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		return fmt.Errorf("failed to close compressed log: %w", err)
+	}
+
+	return os.Remove(path)
+}
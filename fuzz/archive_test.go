@@ -0,0 +1,72 @@
+package fuzz
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRotateLogs verifies that the logs of past cycles are compressed and
+// removed according to the age and size retention policy, while the logs of
+// the current cycle are left untouched.
+func TestRotateLogs(t *testing.T) {
+	cfg := &config.Config{
+		FuzzResultsPath: t.TempDir(),
+		LogMaxAge:       48 * time.Hour,
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	now := time.Now()
+	expiredCycle := newCycleID(now.Add(-72 * time.Hour))
+	oldCycle := newCycleID(now.Add(-24 * time.Hour))
+	recentCycle := newCycleID(now.Add(-time.Hour))
+	currentCycle := newCycleID(now)
+
+	content := strings.Repeat("fuzz: elapsed: 3s, execs: 1234\n", 1000)
+	for _, cycleID := range []string{expiredCycle, oldCycle, recentCycle,
+		currentCycle} {
+
+		logFile, err := createTargetLog(cfg, cycleID, "foo/bar",
+			"FuzzFoo")
+		require.NoError(t, err)
+		_, err = logFile.WriteString(content)
+		require.NoError(t, err)
+		require.NoError(t, logFile.Close())
+	}
+
+	logPath := func(cycleID string) string {
+		return filepath.Join(cfg.FuzzResultsPath, logsDirName, cycleID,
+			"foo", "bar", "FuzzFoo.log")
+	}
+
+	rotateLogs(logger, cfg, currentCycle)
+
+	// The expired cycle is removed, the others are compressed, except
+	// for the current one.
+	assert.NoDirExists(t, filepath.Dir(filepath.Dir(filepath.Dir(
+		logPath(expiredCycle)))))
+	assert.NoFileExists(t, logPath(oldCycle))
+	assert.FileExists(t, logPath(oldCycle)+".gz")
+	assert.FileExists(t, logPath(recentCycle)+".gz")
+	assert.FileExists(t, logPath(currentCycle))
+
+	// With a size limit that only fits a single compressed cycle, the
+	// oldest one is removed.
+	info, err := os.Stat(logPath(recentCycle) + ".gz")
+	require.NoError(t, err)
+	cfg.LogMaxSize = info.Size()
+
+	rotateLogs(logger, cfg, currentCycle)
+
+	assert.NoFileExists(t, logPath(oldCycle)+".gz")
+	assert.FileExists(t, logPath(recentCycle)+".gz")
+	assert.FileExists(t, logPath(currentCycle))
+}
@@ -0,0 +1,70 @@
+package fuzz
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/NishantBansal2003/LND-Fuzz/crash"
+	"github.com/NishantBansal2003/LND-Fuzz/notify"
+	"github.com/NishantBansal2003/LND-Fuzz/parser"
+)
+
+// bisectCrashes searches the commits introducing the new crashes of the
+// cycle, see crash.Bisect, records them and announces the crashes. It runs
+// once the fuzz targets of the cycle are done, for at most
+// config.BisectBudget. Crashes whose search fails, or is cut short by the
+// budget or the end of the cycle, are announced without it.
+func bisectCrashes(ctx context.Context, logger *slog.Logger,
+	cfg *config.Config, pending []*parser.PendingCrash) {
+
+	if len(pending) == 0 {
+		return
+	}
+
+	n := notify.FromContext(ctx)
+	ctx, cancel := context.WithTimeout(ctx, config.BisectBudget)
+	defer cancel()
+
+	store := crash.NewStore(cfg)
+	for _, p := range pending {
+		if ctx.Err() == nil {
+			bisectCrash(ctx, logger, cfg, store, p)
+		} else {
+			logger.Warn("No time left to bisect crash", "signature",
+				p.Finding.Signature)
+		}
+		n.Notify(p.Event)
+	}
+}
+
+// bisectCrash searches the commit introducing the crash, running the copy of
+// its failing input kept in the crash database, as the testdata of the package
+// no longer holds it. The outcome is recorded with the crash and added to its
+// announcement. A failed search is only logged.
+func bisectCrash(ctx context.Context, logger *slog.Logger, cfg *config.Config,
+	store *crash.Store, p *parser.PendingCrash) {
+
+	finding := *p.Finding
+	finding.InputPath = store.InputPath(p.Finding)
+
+	b, err := crash.Bisect(ctx, logger, cfg, &finding)
+	if err != nil {
+		logger.Warn("Failed to bisect crash", "signature",
+			finding.Signature, "error", err)
+		return
+	}
+	if b == nil {
+		return
+	}
+	p.Event.Bisection = b
+
+	if err := store.SetBisection(finding.Signature, b); err != nil {
+		logger.Error("Failed to record crash bisection", "signature",
+			finding.Signature, "error", err)
+	}
+
+	logger.Info("Crash bisected", "signature", finding.Signature,
+		"first_bad", b.FirstBad, "candidates", len(b.Candidates),
+		"tested", b.Tested)
+}
@@ -11,17 +11,64 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/NishantBansal2003/LND-Fuzz/control"
+	"github.com/NishantBansal2003/LND-Fuzz/git"
+	"github.com/NishantBansal2003/LND-Fuzz/metrics"
+	"github.com/NishantBansal2003/LND-Fuzz/notify"
 	"github.com/NishantBansal2003/LND-Fuzz/parser"
+	"github.com/NishantBansal2003/LND-Fuzz/report"
 	"golang.org/x/sync/errgroup"
 )
 
+const (
+	// failureExcerptLines is the number of stack frames, or of failure
+	// output lines if there is no stack trace, kept in the outcome of a
+	// failing fuzz target.
+	failureExcerptLines = 30
+
+	// errorOutputLines is the number of trailing output lines reported
+	// when a fuzz target cannot be run.
+	errorOutputLines = 20
+)
+
 // RunFuzzing iterates over the configured fuzz packages and executes all
 // fuzz targets found in each package. It spawns parallel goroutines for each
 // target but will cease launching new work as soon as the context is canceled.
+// It returns the statistics of the cycle, even if it failed.
 func RunFuzzing(ctx context.Context, logger *slog.Logger,
-	cfg *config.Config) error {
+	cfg *config.Config) (*CycleStats, error) {
+
+	// Resolve the project commit under test, which crashes are reported
+	// against.
+	commit, err := git.HeadCommit(config.DefaultProjectDir)
+	if err != nil {
+		logger.Warn("Failed to resolve the fuzzed commit", "error",
+			err)
+	}
+
+	// Resolve the Go toolchain the project is fuzzed with, which crash
+	// reproducers select.
+	goVersion, err := projectGoVersion(ctx)
+	if err != nil {
+		logger.Warn("Failed to resolve the Go version", "error", err)
+	}
+
+	// Find out which of the known crashes the new commit fixed, before
+	// its packages are fuzzed.
+	replayCrashes(ctx, logger, cfg, commit)
+
+	// Track this cycle, so that its logs and statistics are kept apart
+	// from those of previous cycles, then apply the log retention policy
+	// to the latter.
+	c := newCycle(commit, goVersion)
+	rotateLogs(logger, cfg, c.id)
+
+	// Report the fuzz targets of this cycle to the controller, if any.
+	ctl := control.FromContext(ctx)
+	ctl.StartCycle(c.id, c.start)
 
 	// Create an errgroup that shares this context. Any error or
 	// cancellation will cancel all in-flight fuzz runs.
@@ -50,8 +97,11 @@ func RunFuzzing(ctx context.Context, logger *slog.Logger,
 			}
 
 			// Discover all fuzz targets in this package (pkg)
-			targets, err := listFuzzTargets(goCtx, logger, pkg)
+			targets, err := ListFuzzTargets(goCtx, logger,
+				config.DefaultProjectDir, pkg,
+				cfg.Target(pkg, "").BuildTags)
 			if err != nil {
+				c.addPackageError(pkg, err)
 				return fmt.Errorf("failed to list targets for"+
 					" package %q: %w", pkg, err)
 			}
@@ -73,19 +123,58 @@ func RunFuzzing(ctx context.Context, logger *slog.Logger,
 					case <-ctx.Done():
 						// Context canceled: stop
 						// processing further packages.
+						c.addTargetStats(
+							skippedTarget(pkg,
+								target),
+						)
 						return nil
 					case <-goCtx.Done():
 						// error already encountered:
 						// stop processing further
+						c.addTargetStats(
+							skippedTarget(pkg,
+								target),
+						)
 						return nil
 					default:
 						// Context still active: proceed
 						// to list and run fuzz targets.
 					}
 
-					if err := executeFuzzTarget(goCtx,
-						logger, pkg, target,
-						cfg); err != nil {
+					// Leave out the targets excluded by
+					// the configuration file.
+					if cfg.Target(pkg, target).Exclude {
+						c.addTargetStats(
+							excludedTarget(pkg,
+								target),
+						)
+						return nil
+					}
+
+					// The target runs until the end
+					// of the cycle, unless stopped
+					// through the controller.
+					targetCtx, cancel := ctl.StartTarget(
+						goCtx, pkg, target,
+					)
+					defer cancel()
+
+					stats, err := executeFuzzTarget(
+						targetCtx, logger, pkg, target,
+						cfg, c,
+					)
+					if stats.Outcome == OutcomePassed &&
+						targetCtx.Err() != nil &&
+						goCtx.Err() == nil {
+
+						stats.Outcome = OutcomeSkipped
+						stats.Message = "stopped " +
+							"through the control " +
+							"API"
+					}
+					c.addTargetStats(stats)
+					ctl.FinishTarget(pkg, target, err)
+					if err != nil {
 						return fmt.Errorf("fuzzing "+
 							"failed for %q/%q: %w",
 							pkg, target, err)
@@ -98,28 +187,56 @@ func RunFuzzing(ctx context.Context, logger *slog.Logger,
 	}
 
 	// Wait for all fuzz target executions to finish or any to error/cancel.
-	if err := g.Wait(); err != nil {
-		return fmt.Errorf("error during fuzzing: %w", err)
+	err = g.Wait()
+
+	// Search the commits introducing the new crashes, now that the fuzz
+	// targets are done and their corpus saved, then announce them.
+	bisectCrashes(ctx, logger, cfg, c.pendingCrashes())
+
+	// Save the statistics and the test report of the targets that ran,
+	// even if the cycle was interrupted.
+	stats := c.stats()
+	if err := saveStats(logger, cfg, stats); err != nil {
+		logger.Error("Failed to save cycle statistics", "error", err)
+	}
+	if err := saveJUnit(logger, cfg, stats); err != nil {
+		logger.Error("Failed to save JUnit report", "error", err)
+	}
+
+	// Export the crash database for code scanning tools.
+	if sarifPath, err := report.SaveSARIF(cfg); err != nil {
+		logger.Error("Failed to export findings as SARIF", "error", err)
+	} else {
+		logger.Info("Findings exported as SARIF", "path", sarifPath)
 	}
 
-	return nil
+	if err != nil {
+		return stats, fmt.Errorf("error during fuzzing: %w", err)
+	}
+
+	return stats, nil
 }
 
-// listFuzzTargets discovers and returns a list of fuzz targets for the given
-// package. It uses "go test -list=^Fuzz" to list the functions and filters
-// those that start with "Fuzz".
-func listFuzzTargets(ctx context.Context, logger *slog.Logger,
-	pkg string) ([]string, error) {
+// ListFuzzTargets discovers and returns a list of fuzz targets for the given
+// package of the project checked out in projectDir, built with the given build
+// tags. It uses "go test -list=^Fuzz" to list the functions and filters those
+// that start with "Fuzz".
+func ListFuzzTargets(ctx context.Context, logger *slog.Logger, projectDir,
+	pkg string, tags []string) ([]string, error) {
 
 	logger.Info("Discovering fuzz targets", "package", pkg)
 
 	// Construct the absolute path to the package directory within the
-	// default project directory.
-	pkgPath := filepath.Join(config.DefaultProjectDir, pkg)
+	// project directory.
+	pkgPath := filepath.Join(projectDir, pkg)
 
 	// Prepare the command to list all test functions matching the pattern
 	// "^Fuzz". This leverages Go's testing tool to identify fuzz targets.
-	cmd := exec.CommandContext(ctx, "go", "test", "-list=^Fuzz", ".")
+	args := []string{"test", "-list=^Fuzz"}
+	if len(tags) > 0 {
+		args = append(args, "-tags="+strings.Join(tags, ","))
+	}
+	cmd := exec.CommandContext(ctx, "go", append(args, ".")...)
 
 	// Set the working directory to the package path.
 	cmd.Dir = pkgPath
@@ -158,13 +275,117 @@ func listFuzzTargets(ctx context.Context, logger *slog.Logger,
 	return targets, nil
 }
 
-// executeFuzzTarget runs the specified fuzz target for a package using the
-// "go test" command. It sets up the necessary environment, starts the command,
-// streams its output and log the failure (if any) in the log file.
+// projectGoVersion returns the version of the Go toolchain building the fuzzed
+// project, which may differ from the installed one if the project requires a
+// newer toolchain.
+func projectGoVersion(ctx context.Context) (string, error) {
+	cmd := exec.CommandContext(ctx, "go", "env", "GOVERSION")
+	cmd.Dir = config.DefaultProjectDir
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("go env failed: %w", err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// skippedTarget returns the statistics of a fuzz target the cycle ended before
+// it started.
+func skippedTarget(pkg, target string) *TargetStats {
+	return &TargetStats{
+		Package: pkg,
+		Target:  target,
+		Outcome: OutcomeSkipped,
+		Message: "the cycle ended before the fuzz target started",
+	}
+}
+
+// excludedTarget returns the statistics of a fuzz target the configuration
+// excludes from fuzzing.
+func excludedTarget(pkg, target string) *TargetStats {
+	return &TargetStats{
+		Package: pkg,
+		Target:  target,
+		Outcome: OutcomeSkipped,
+		Message: "excluded by the configuration",
+	}
+}
+
+// executeFuzzTarget runs the specified fuzz target for a package, see
+// runFuzzTarget. If the target stalls, it is restarted, up to maxStallRestarts
+// times, unless the configured stall action is to skip it. It returns the
+// outcome and progress statistics of all the runs.
 func executeFuzzTarget(ctx context.Context, logger *slog.Logger, pkg string,
-	target string, cfg *config.Config) error {
+	target string, cfg *config.Config, c *cycle) (*TargetStats, error) {
+
+	start := time.Now()
+	stats := &TargetStats{
+		Package: pkg,
+		Target:  target,
+		Outcome: OutcomePassed,
+	}
+	defer func() {
+		stats.Duration = time.Since(start)
+	}()
+
+	for attempt := 0; ; attempt++ {
+		state, err := runFuzzTarget(ctx, logger, pkg, target, cfg, c,
+			attempt)
+		if state != nil {
+			stats.Samples = append(stats.Samples, state.Stats...)
+		}
+		if state != nil && state.SeenFailure && stats.Failure == nil {
+			stats.Outcome = OutcomeFailed
+			stats.Failure = &TargetFailure{
+				Class:     string(state.Class),
+				Signature: state.Signature,
+				Summary:   state.Summary,
+				Excerpt: state.FailureExcerpt(
+					failureExcerptLines,
+				),
+				New: state.NewFinding,
+			}
+		}
+		if err != nil {
+			stats.Outcome = OutcomeError
+			stats.Message = err.Error()
+			return stats, err
+		}
+
+		if !state.Stalled || ctx.Err() != nil {
+			break
+		}
+
+		if cfg.StallAction == config.StallActionSkip ||
+			attempt >= maxStallRestarts {
+
+			logger.Warn("Skipping stalled fuzz target for the "+
+				"rest of the cycle", "package", pkg,
+				"target", target, "restarts", attempt)
+			break
+		}
+
+		logger.Warn("Restarting stalled fuzz target", "package", pkg,
+			"target", target, "restart", attempt+1)
+		metrics.TargetRestarts.WithLabelValues(pkg, target).Inc()
+	}
+
+	return stats, nil
+}
+
+// runFuzzTarget runs the specified fuzz target for a package using the
+// "go test" command. It sets up the necessary environment, starts the command,
+// streams its output and log the failure (if any) in the log file. The
+// complete output is archived in the logs directory of the cycle, restarts
+// being archived next to the first run. A watchdog interrupts the target if it
+// stops making progress. It returns the final processing state of the output.
+func runFuzzTarget(ctx context.Context, logger *slog.Logger, pkg string,
+	target string, cfg *config.Config, c *cycle,
+	attempt int) (*parser.ProcessState, error) {
 
-	logger.Info("Executing fuzz target", "package", pkg, "target", target)
+	logger.Info("Executing fuzz target", "package", pkg, "target", target,
+		"attempt", attempt)
 
 	// Construct the absolute path to the package directory within the
 	// default project directory.
@@ -173,7 +394,8 @@ func executeFuzzTarget(ctx context.Context, logger *slog.Logger, pkg string,
 	// Retrieve the current working directory.
 	cwd, err := os.Getwd()
 	if err != nil {
-		return fmt.Errorf("failed to get working directory: %w", err)
+		return nil, fmt.Errorf("failed to get working directory: %w",
+			err)
 	}
 
 	// Define the path to store the corpus data generated during fuzzing.
@@ -186,52 +408,108 @@ func executeFuzzTarget(ctx context.Context, logger *slog.Logger, pkg string,
 	maybeFailingCorpusPath := filepath.Join(pkgPath, "testdata", "fuzz")
 
 	// Prepare the arguments for the 'go test' command to run the specific
-	// fuzz target.
+	// fuzz target, with the options configured for it.
+	opts := cfg.Target(pkg, target)
 	args := []string{
 		"test",
 		fmt.Sprintf("-fuzz=^%s$", target),
 		fmt.Sprintf("-test.fuzzcachedir=%s", corpusPath),
-		fmt.Sprintf("-fuzztime=%s", cfg.FuzzTime),
-		fmt.Sprintf("-parallel=%d", cfg.NumProcesses),
+		fmt.Sprintf("-fuzztime=%s", opts.FuzzTime),
+		fmt.Sprintf("-parallel=%d", opts.Parallelism),
 	}
+	args = append(args, opts.BuildFlags()...)
 
 	// Initialize the 'go test' command with the specified arguments and
 	// context.
 	cmd := exec.CommandContext(ctx, "go", args...)
 	// Set the working directory for the command.
 	cmd.Dir = pkgPath
+	// Add the configured variables to the environment of the command.
+	if len(opts.Env) > 0 {
+		cmd.Env = append(os.Environ(), opts.Env...)
+	}
 
-	// Obtain a pipe to read the standard output of the command.
+	// Run the command in its own process group, so that the test binary
+	// and its workers can be signalled, and are killed along with the go
+	// command when the context is canceled.
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error {
+		return killProcessGroup(cmd)
+	}
+
+	// Obtain a pipe to read the standard output of the command. Standard
+	// error, where build failures are reported, goes to the same pipe.
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		return fmt.Errorf("stdout pipe failed: %w", err)
+		return nil, fmt.Errorf("stdout pipe failed: %w", err)
+	}
+	cmd.Stderr = cmd.Stdout
+
+	// Archive the complete output of the run.
+	logName := target
+	if attempt > 0 {
+		logName = fmt.Sprintf("%s.restart%d", target, attempt)
+	}
+	targetLog, err := createTargetLog(cfg, c.id, pkg, logName)
+	if err != nil {
+		return nil, fmt.Errorf("target log creation failed: %w", err)
 	}
+	defer targetLog.Close()
 
 	// Start the execution of the 'go test' command.
-	if err := cmd.Start(); err != nil && ctx.Err() == nil {
-		return fmt.Errorf("command start failed: %w", err)
+	if err := cmd.Start(); err != nil {
+		if ctx.Err() != nil {
+			return &parser.ProcessState{}, nil
+		}
+		return nil, fmt.Errorf("command start failed: %w", err)
 	}
 
-	// Channel to signal if the fuzz target encountered a failure.
-	fuzzTargetFailingChan := make(chan bool, 1)
+	targetLogger := logger.With("target", target).With("package", pkg)
+
+	// Initialize a new FuzzProcessor to handle and parse the fuzzing
+	// output.
+	processor := parser.NewFuzzProcessor(targetLogger, cfg,
+		maybeFailingCorpusPath, pkg, target, c.commit, c.goVersion,
+		notify.FromContext(ctx))
+
+	// Channel to pass the final processing state, which tells whether the
+	// fuzz target encountered a failure.
+	stateChan := make(chan *parser.ProcessState, 1)
 
 	var wg sync.WaitGroup
 	wg.Add(1)
 
 	// Stream and process the standard output of 'go test', which may
 	// include both stdout and stderr content.
-	go streamFuzzOutput(logger.With("target", target).With("package", pkg),
-		&wg, stdout, maybeFailingCorpusPath, cfg, target,
-		fuzzTargetFailingChan)
+	go streamFuzzOutput(targetLogger, &wg, io.TeeReader(stdout, targetLog),
+		processor, stateChan)
+
+	// Interrupt the fuzz target if it stops making progress.
+	done := make(chan struct{})
+	if cfg.StallTimeout > 0 {
+		go watchForStall(targetLogger, cmd, processor,
+			cfg.StallTimeout, done)
+	}
 
 	// Wait for the output streaming to complete.
 	wg.Wait()
 
 	// Wait for the 'go test' command to finish execution.
 	err = cmd.Wait()
-
-	// Check if the fuzz target encountered a failure.
-	isFailing := <-fuzzTargetFailingChan
+	close(done)
+
+	// Check if the fuzz target encountered a failure, which includes
+	// stalling.
+	state := <-stateChan
+	isFailing := state.SeenFailure
+
+	// Announce the crash, if any, now that the fuzzing process has exited
+	// and before its failing input is removed, as the input is re-run to
+	// verify it. A new crash to be bisected is announced once the fuzz
+	// targets of the cycle are done.
+	if pending := processor.ReportCrash(ctx); pending != nil {
+		c.addPendingCrash(pending)
+	}
 
 	// Proceed to return an error only if the fuzz target did not fail
 	// (i.e., no failure was detected during fuzzing), and the command
@@ -239,7 +517,9 @@ func executeFuzzTarget(ctx context.Context, logger *slog.Logger, pkg string,
 	// cancellation of the context.
 	if err != nil {
 		if ctx.Err() == nil && !isFailing {
-			return fmt.Errorf("fuzz execution failed: %w", err)
+			return state, fmt.Errorf("fuzz execution failed: %w "+
+				"(output: %q)", err, readLogTail(
+				targetLog.Name(), errorOutputLines))
 		}
 	}
 
@@ -252,8 +532,8 @@ func executeFuzzTarget(ctx context.Context, logger *slog.Logger, pkg string,
 		failingInputPath := filepath.Join(pkgPath, "testdata", "fuzz",
 			target)
 		if err := os.RemoveAll(failingInputPath); err != nil {
-			return fmt.Errorf("failing input cleanup failed: %w",
-				err)
+			return state, fmt.Errorf("failing input cleanup "+
+				"failed: %w", err)
 		}
 	}
 
@@ -265,31 +545,30 @@ func executeFuzzTarget(ctx context.Context, logger *slog.Logger, pkg string,
 	// directory.
 	config.SaveFuzzCorpus(logger, cfg, pkg, target)
 
-	return nil
+	return state, nil
 }
 
 // streamFuzzOutput reads and processes the standard output of a fuzzing
-// process. It utilizes a FuzzProcessor to parse each line of output,
+// process. It utilizes the FuzzProcessor to parse each line of output,
 // identifying any errors or failures that occur during fuzzing. If a failure is
 // detected, it logs the error details and the corresponding failing test case
 // into the log file for analysis. The function signals completion through the
-// provided WaitGroup and communicates whether a failure was encountered via the
-// fuzzTargetFailingChan channel.
+// provided WaitGroup and communicates the final processing state, including
+// whether a failure was encountered, via the stateChan channel.
 func streamFuzzOutput(logger *slog.Logger, wg *sync.WaitGroup, r io.Reader,
-	corpusPath string, cfg *config.Config, target string,
-	fuzzTargetFailingChan chan bool) {
+	processor *parser.FuzzProcessor,
+	stateChan chan *parser.ProcessState) {
 
 	defer wg.Done()
 
-	// Initialize a new FuzzProcessor to handle and parse the fuzzing output
-	processor := parser.NewFuzzProcessor(logger, cfg, corpusPath, target)
-
 	// Start processing the output stream from the fuzzing process. This
 	// will parse each line, log relevant information, and detect any
 	// failures
-	processor.ProcessStream(r)
+	if err := processor.ProcessStream(r); err != nil {
+		logger.Error("Fuzzer output processing failed", "error", err)
+	}
 
-	// Send the result of the failure detection back through the channel
-	// If a failure was seen during processing, this will be true
-	fuzzTargetFailingChan <- processor.State.SeenFailure
+	// Send the final state back through the channel, including the result
+	// of the failure detection.
+	stateChan <- processor.State
 }
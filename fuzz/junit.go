@@ -0,0 +1,266 @@
+package fuzz
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+)
+
+const (
+	// JUnitDirName is the directory, relative to the fuzz results path,
+	// where the JUnit XML report of every fuzzing cycle is saved.
+	JUnitDirName = "junit"
+
+	// listTestCaseName names the test case reporting that the fuzz targets
+	// of a package could not be listed.
+	listTestCaseName = "(list fuzz targets)"
+)
+
+// junitTestSuites is the root element of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName xml.Name `xml:"testsuites"`
+
+	// Name is the name of the report.
+	Name string `xml:"name,attr"`
+
+	// Tests, Failures, Errors and Skipped count the test cases of all
+	// suites by outcome.
+	Tests    int `xml:"tests,attr"`
+	Failures int `xml:"failures,attr"`
+	Errors   int `xml:"errors,attr"`
+	Skipped  int `xml:"skipped,attr"`
+
+	// Time is the duration of the cycle, in seconds.
+	Time string `xml:"time,attr"`
+
+	// Timestamp is the start time of the cycle.
+	Timestamp string `xml:"timestamp,attr"`
+
+	// Suites holds a test suite per package.
+	Suites []*junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite reports the fuzz targets of a package.
+type junitTestSuite struct {
+	// Name is the package.
+	Name string `xml:"name,attr"`
+
+	// Tests, Failures, Errors and Skipped count the test cases of the
+	// suite by outcome.
+	Tests    int `xml:"tests,attr"`
+	Failures int `xml:"failures,attr"`
+	Errors   int `xml:"errors,attr"`
+	Skipped  int `xml:"skipped,attr"`
+
+	// Time is the total time spent running the fuzz targets of the
+	// package, in seconds.
+	Time string `xml:"time,attr"`
+
+	// Timestamp is the start time of the cycle.
+	Timestamp string `xml:"timestamp,attr"`
+
+	// Properties holds the cycle, the fuzzed commit and the Go version.
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+
+	// TestCases holds a test case per fuzz target.
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+// junitProperty is a named value of a test suite.
+type junitProperty struct {
+	// Name is the name of the property.
+	Name string `xml:"name,attr"`
+
+	// Value is the value of the property.
+	Value string `xml:"value,attr"`
+}
+
+// junitTestCase reports a fuzz target.
+type junitTestCase struct {
+	// Name is the fuzz target.
+	Name string `xml:"name,attr"`
+
+	// ClassName is the package.
+	ClassName string `xml:"classname,attr"`
+
+	// Time is the time spent running the fuzz target, in seconds.
+	Time string `xml:"time,attr"`
+
+	// Failure describes the crash found by the fuzz target, if any.
+	Failure *junitResult `xml:"failure,omitempty"`
+
+	// Error describes why the fuzz target could not be run, if so.
+	Error *junitResult `xml:"error,omitempty"`
+
+	// Skipped describes why the fuzz target was skipped, if so.
+	Skipped *junitResult `xml:"skipped,omitempty"`
+
+	// SystemOut summarizes the fuzzing progress of the target.
+	SystemOut string `xml:"system-out,omitempty"`
+}
+
+// junitResult is the failure, error or skip of a test case.
+type junitResult struct {
+	// Message is a one line description of the result.
+	Message string `xml:"message,attr"`
+
+	// Type is the failure class, if any.
+	Type string `xml:"type,attr,omitempty"`
+
+	// Body holds the details of the result.
+	Body string `xml:",chardata"`
+}
+
+// newJUnitReport converts the statistics of a cycle to a JUnit report, with a
+// test suite per package and a test case per fuzz target. Crashes are
+// failures, fuzz targets that could not be run, including packages whose fuzz
+// targets could not be listed, are errors, and fuzz targets stopped through
+// the control API or not started before the end of the cycle are skipped.
+func newJUnitReport(stats *CycleStats) *junitTestSuites {
+	timestamp := stats.Start.UTC().Format("2006-01-02T15:04:05")
+	report := &junitTestSuites{
+		Name:      "go-continuous-fuzz " + stats.Cycle,
+		Time:      seconds(stats.End.Sub(stats.Start)),
+		Timestamp: timestamp,
+	}
+
+	suites := make(map[string]*junitTestSuite)
+	durations := make(map[string]time.Duration)
+	suite := func(pkg string) *junitTestSuite {
+		if s, ok := suites[pkg]; ok {
+			return s
+		}
+
+		s := &junitTestSuite{
+			Name:      pkg,
+			Timestamp: timestamp,
+			Properties: []junitProperty{
+				{Name: "cycle", Value: stats.Cycle},
+			},
+		}
+		if stats.Commit != "" {
+			s.Properties = append(s.Properties, junitProperty{
+				Name:  "commit",
+				Value: stats.Commit,
+			})
+		}
+		if stats.GoVersion != "" {
+			s.Properties = append(s.Properties, junitProperty{
+				Name:  "go_version",
+				Value: stats.GoVersion,
+			})
+		}
+		suites[pkg] = s
+		report.Suites = append(report.Suites, s)
+
+		return s
+	}
+
+	for _, pe := range stats.PackageErrors {
+		s := suite(pe.Package)
+		s.Errors++
+		s.TestCases = append(s.TestCases, junitTestCase{
+			Name:      listTestCaseName,
+			ClassName: pe.Package,
+			Time:      seconds(0),
+			Error: &junitResult{
+				Message: "failed to list fuzz targets",
+				Body:    pe.Message,
+			},
+		})
+	}
+
+	for _, ts := range stats.Targets {
+		s := suite(ts.Package)
+		durations[ts.Package] += ts.Duration
+
+		tc := junitTestCase{
+			Name:      ts.Target,
+			ClassName: ts.Package,
+			Time:      seconds(ts.Duration),
+			SystemOut: fmt.Sprintf("execs: %d, average execs/sec: "+
+				"%.0f, corpus size: %d, new interesting: %d",
+				ts.Summary.Execs, ts.Summary.AvgExecsPerSec,
+				ts.Summary.CorpusSize,
+				ts.Summary.NewInteresting),
+		}
+
+		switch ts.Outcome {
+		case OutcomeFailed:
+			s.Failures++
+			tc.Failure = &junitResult{
+				Message: ts.Failure.Summary,
+				Type:    ts.Failure.Class,
+				Body: fmt.Sprintf("signature: %s\n\n%s",
+					ts.Failure.Signature,
+					ts.Failure.Excerpt),
+			}
+
+		case OutcomeError:
+			s.Errors++
+			tc.Error = &junitResult{
+				Message: "failed to run fuzz target",
+				Body:    ts.Message,
+			}
+
+		case OutcomeSkipped:
+			s.Skipped++
+			tc.Skipped = &junitResult{Message: ts.Message}
+		}
+
+		s.TestCases = append(s.TestCases, tc)
+	}
+
+	sort.Slice(report.Suites, func(i, j int) bool {
+		return report.Suites[i].Name < report.Suites[j].Name
+	})
+	for _, s := range report.Suites {
+		s.Tests = len(s.TestCases)
+		s.Time = seconds(durations[s.Name])
+
+		report.Tests += s.Tests
+		report.Failures += s.Failures
+		report.Errors += s.Errors
+		report.Skipped += s.Skipped
+	}
+
+	return report
+}
+
+// seconds formats the duration in seconds, as JUnit reports expect.
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// saveJUnit writes the JUnit XML report of the cycle to
+// <FuzzResultsPath>/junit/<cycle>.xml.
+func saveJUnit(logger *slog.Logger, cfg *config.Config,
+	stats *CycleStats) error {
+
+	junitDir := filepath.Join(cfg.FuzzResultsPath, JUnitDirName)
+	if err := config.EnsureDirExists(junitDir); err != nil {
+		return fmt.Errorf("failed to create JUnit directory: %w", err)
+	}
+
+	data, err := xml.MarshalIndent(newJUnitReport(stats), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JUnit report: %w", err)
+	}
+	data = append([]byte(xml.Header), data...)
+
+	junitPath := filepath.Join(junitDir, stats.Cycle+".xml")
+	if err := os.WriteFile(junitPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+
+	logger.Info("JUnit report saved", "cycle", stats.Cycle, "path",
+		junitPath)
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package fuzz
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSaveJUnit verifies that the JUnit report of a cycle has a test suite per
+// package and a test case per fuzz target, reporting crashes as failures,
+// listing and run errors as errors, and skipped targets as skips.
+func TestSaveJUnit(t *testing.T) {
+	cfg := &config.Config{FuzzResultsPath: t.TempDir()}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	c := newCycle("0123abcd", "go1.22.3")
+	c.addTargetStats(&TargetStats{
+		Package:  "foo",
+		Target:   "FuzzPassed",
+		Outcome:  OutcomePassed,
+		Duration: 2 * time.Second,
+	})
+	c.addTargetStats(&TargetStats{
+		Package:  "foo",
+		Target:   "FuzzFailed",
+		Outcome:  OutcomeFailed,
+		Duration: 1500 * time.Millisecond,
+		Failure: &TargetFailure{
+			Class:     "panic",
+			Signature: "deadbeef",
+			Summary:   "runtime error: index out of range",
+			Excerpt:   "panic: runtime error: index out of range",
+		},
+	})
+	c.addTargetStats(&TargetStats{
+		Package: "foo",
+		Target:  "FuzzBroken",
+		Outcome: OutcomeError,
+		Message: "exit status 2",
+	})
+	c.addTargetStats(skippedTarget("bar", "FuzzLate"))
+	c.addPackageError("baz", errors.New("undefined: foo"))
+
+	stats := c.stats()
+	require.NoError(t, saveJUnit(logger, cfg, stats))
+
+	data, err := os.ReadFile(filepath.Join(cfg.FuzzResultsPath,
+		JUnitDirName, c.id+".xml"))
+	require.NoError(t, err)
+
+	var report junitTestSuites
+	require.NoError(t, xml.Unmarshal(data, &report))
+
+	assert.Equal(t, 5, report.Tests)
+	assert.Equal(t, 1, report.Failures)
+	assert.Equal(t, 2, report.Errors)
+	assert.Equal(t, 1, report.Skipped)
+
+	// Suites are sorted by package.
+	require.Len(t, report.Suites, 3)
+	bar, baz, foo := report.Suites[0], report.Suites[1], report.Suites[2]
+
+	assert.Equal(t, "bar", bar.Name)
+	require.Len(t, bar.TestCases, 1)
+	require.NotNil(t, bar.TestCases[0].Skipped)
+	assert.Equal(t, "the cycle ended before the fuzz target started",
+		bar.TestCases[0].Skipped.Message)
+
+	assert.Equal(t, "baz", baz.Name)
+	assert.Equal(t, 1, baz.Errors)
+	require.Len(t, baz.TestCases, 1)
+	assert.Equal(t, listTestCaseName, baz.TestCases[0].Name)
+	require.NotNil(t, baz.TestCases[0].Error)
+	assert.Equal(t, "undefined: foo", baz.TestCases[0].Error.Body)
+
+	assert.Equal(t, "foo", foo.Name)
+	assert.Equal(t, 3, foo.Tests)
+	assert.Equal(t, 1, foo.Failures)
+	assert.Equal(t, 1, foo.Errors)
+	assert.Equal(t, 0, foo.Skipped)
+	assert.Equal(t, "3.500", foo.Time)
+	assert.Equal(t, []junitProperty{
+		{Name: "cycle", Value: c.id},
+		{Name: "commit", Value: "0123abcd"},
+		{Name: "go_version", Value: "go1.22.3"},
+	}, foo.Properties)
+
+	// Test cases are sorted by target name.
+	require.Len(t, foo.TestCases, 3)
+	broken, failed, passed := foo.TestCases[0], foo.TestCases[1],
+		foo.TestCases[2]
+
+	assert.Equal(t, "FuzzBroken", broken.Name)
+	require.NotNil(t, broken.Error)
+	assert.Equal(t, "exit status 2", broken.Error.Body)
+
+	assert.Equal(t, "FuzzFailed", failed.Name)
+	assert.Equal(t, "foo", failed.ClassName)
+	assert.Equal(t, "1.500", failed.Time)
+	require.NotNil(t, failed.Failure)
+	assert.Equal(t, "runtime error: index out of range",
+		failed.Failure.Message)
+	assert.Equal(t, "panic", failed.Failure.Type)
+	assert.Contains(t, failed.Failure.Body, "signature: deadbeef")
+	assert.Contains(t, failed.Failure.Body,
+		"panic: runtime error: index out of range")
+
+	assert.Equal(t, "FuzzPassed", passed.Name)
+	assert.Nil(t, passed.Failure)
+	assert.Nil(t, passed.Error)
+	assert.Nil(t, passed.Skipped)
+}
@@ -0,0 +1,56 @@
+package fuzz
+
+// CycleOutcome is how a fuzzing cycle ended, as reported to CI jobs running a
+// single cycle.
+type CycleOutcome string
+
+const (
+	// CycleNoFindings is a cycle which found no new crash. Known crashes
+	// found again are not findings.
+	CycleNoFindings CycleOutcome = "no-findings"
+
+	// CycleNewCrash is a cycle in which a fuzz target found a crash whose
+	// signature was never seen before.
+	CycleNewCrash CycleOutcome = "new-crash"
+
+	// CycleBuildFailure is a cycle in which the fuzz targets of a package
+	// could not be listed, or a fuzz target could not be run, most often
+	// because the package does not build.
+	CycleBuildFailure CycleOutcome = "build-failure"
+
+	// CycleInfraError is a cycle which failed for reasons unrelated to the
+	// fuzzed code, e.g. because a repository could not be cloned.
+	CycleInfraError CycleOutcome = "infrastructure-error"
+)
+
+// Outcome classifies a cycle from its statistics, nil if the cycle did not get
+// to fuzzing, and the error which ended it, if any. A build failure takes
+// precedence over a new crash, as it means that part of the code was not
+// fuzzed. An error not explained by a build failure is an infrastructure
+// error.
+func Outcome(stats *CycleStats, err error) CycleOutcome {
+	if stats != nil && len(stats.PackageErrors) > 0 {
+		return CycleBuildFailure
+	}
+
+	newCrash := false
+	if stats != nil {
+		for _, ts := range stats.Targets {
+			if ts.Outcome == OutcomeError {
+				return CycleBuildFailure
+			}
+			if ts.Failure != nil && ts.Failure.New {
+				newCrash = true
+			}
+		}
+	}
+
+	switch {
+	case err != nil:
+		return CycleInfraError
+	case newCrash:
+		return CycleNewCrash
+	default:
+		return CycleNoFindings
+	}
+}
@@ -0,0 +1,84 @@
+package fuzz
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestOutcome verifies that cycles are classified by their most severe
+// outcome.
+func TestOutcome(t *testing.T) {
+	passed := &TargetStats{Outcome: OutcomePassed}
+	knownCrash := &TargetStats{
+		Outcome: OutcomeFailed,
+		Failure: &TargetFailure{Signature: "0123"},
+	}
+	newCrash := &TargetStats{
+		Outcome: OutcomeFailed,
+		Failure: &TargetFailure{Signature: "4567", New: true},
+	}
+	notRun := &TargetStats{Outcome: OutcomeError}
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name     string
+		stats    *CycleStats
+		err      error
+		expected CycleOutcome
+	}{
+		{
+			name: "no findings",
+			stats: &CycleStats{
+				Targets: []*TargetStats{passed, knownCrash},
+			},
+			expected: CycleNoFindings,
+		},
+		{
+			name: "new crash",
+			stats: &CycleStats{
+				Targets: []*TargetStats{passed, newCrash},
+			},
+			expected: CycleNewCrash,
+		},
+		{
+			name: "package not building",
+			stats: &CycleStats{
+				Targets: []*TargetStats{newCrash},
+				PackageErrors: []PackageError{
+					{Package: "foo"},
+				},
+			},
+			err:      errFailed,
+			expected: CycleBuildFailure,
+		},
+		{
+			name: "target not run",
+			stats: &CycleStats{
+				Targets: []*TargetStats{notRun, newCrash},
+			},
+			err:      errFailed,
+			expected: CycleBuildFailure,
+		},
+		{
+			name:     "clone failure",
+			err:      errFailed,
+			expected: CycleInfraError,
+		},
+		{
+			name: "error after fuzzing",
+			stats: &CycleStats{
+				Targets: []*TargetStats{newCrash},
+			},
+			err:      errFailed,
+			expected: CycleInfraError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Outcome(tt.stats, tt.err))
+		})
+	}
+}
@@ -0,0 +1,23 @@
+//go:build !unix
+
+package fuzz
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// setProcessGroup is a no-op on platforms without process groups.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// quitProcessGroup fails on platforms without SIGQUIT, where goroutine dumps
+// cannot be requested.
+func quitProcessGroup(cmd *exec.Cmd) error {
+	return errors.New("goroutine dumps are not supported on this " +
+		"platform")
+}
+
+// killProcessGroup kills the command. Its child processes are left running.
+func killProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
@@ -0,0 +1,38 @@
+//go:build unix
+
+package fuzz
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts the command in a new process group, so that signals
+// reach the test binary and its fuzzing workers and not only the go command.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// quitProcessGroup sends SIGQUIT to the process group of the command, making
+// every Go program in it dump its goroutines and exit.
+func quitProcessGroup(cmd *exec.Cmd) error {
+	return signalProcessGroup(cmd, syscall.SIGQUIT)
+}
+
+// killProcessGroup kills every process in the process group of the command.
+func killProcessGroup(cmd *exec.Cmd) error {
+	return signalProcessGroup(cmd, syscall.SIGKILL)
+}
+
+// signalProcessGroup sends the signal to the process group of the command. It
+// returns os.ErrProcessDone if the group has already exited.
+func signalProcessGroup(cmd *exec.Cmd, sig syscall.Signal) error {
+	err := syscall.Kill(-cmd.Process.Pid, sig)
+	if errors.Is(err, syscall.ESRCH) {
+		return os.ErrProcessDone
+	}
+
+	return err
+}
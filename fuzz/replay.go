@@ -0,0 +1,63 @@
+package fuzz
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/NishantBansal2003/LND-Fuzz/crash"
+	"github.com/NishantBansal2003/LND-Fuzz/notify"
+)
+
+// replayCrashes replays the failing inputs of the open crashes against the
+// fuzzed commit, see crash.Store.Replay, for at most config.ReplayBudget, and
+// notifies the crashes found fixed. Failures are only logged, fuzzing goes on
+// without the replay.
+func replayCrashes(ctx context.Context, logger *slog.Logger,
+	cfg *config.Config, commit string) {
+
+	if commit == "" {
+		logger.Warn("Fuzzed commit unknown, not replaying crashes")
+		return
+	}
+
+	replayCtx, cancel := context.WithTimeout(ctx, config.ReplayBudget)
+	defer cancel()
+
+	transitions, err := crash.NewStore(cfg).Replay(replayCtx, logger, cfg,
+		commit)
+	if err != nil {
+		logger.Error("Failed to replay crashes", "error", err)
+		return
+	}
+
+	n := notify.FromContext(ctx)
+	for _, t := range transitions {
+		logger.Info("Crash state changed", "signature",
+			t.Record.Signature, "from", t.From, "to", t.To,
+			"commit", commit)
+
+		if t.To == crash.StateFixed {
+			n.Notify(fixedEvent(t.Record))
+		}
+	}
+}
+
+// fixedEvent builds the notification announcing that the crash is fixed.
+func fixedEvent(record *crash.Record) *notify.Event {
+	input := record.Inputs[0]
+
+	return &notify.Event{
+		Signature: record.Signature,
+		Class:     record.Class,
+		Priority:  record.Priority,
+		Summary:   record.Summary,
+		Package:   input.Package,
+		Target:    input.Target,
+		InputID:   input.ID,
+		Commit:    record.FixedCommit,
+		State:     crash.StateFixed,
+		Time:      time.Now().UTC(),
+	}
+}
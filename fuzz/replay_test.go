@@ -0,0 +1,34 @@
+package fuzz
+
+import (
+	"testing"
+
+	"github.com/NishantBansal2003/LND-Fuzz/crash"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFixedEvent verifies that the notification of a fixed crash names the
+// commit it stopped reproducing at.
+func TestFixedEvent(t *testing.T) {
+	e := fixedEvent(&crash.Record{
+		Signature: "0123456789abcdef",
+		Class:     "panic",
+		Priority:  1,
+		Summary:   "panic: boom",
+		Inputs: []crash.Input{{
+			Package: "foo",
+			Target:  "FuzzFoo",
+			ID:      "771e938e4458e983",
+		}},
+		State:       crash.StateFixed,
+		FixedCommit: "0a1b2c3d",
+	})
+
+	assert.Equal(t, "0123456789abcdef", e.Signature)
+	assert.Equal(t, "foo", e.Package)
+	assert.Equal(t, "FuzzFoo", e.Target)
+	assert.Equal(t, "771e938e4458e983", e.InputID)
+	assert.Equal(t, "0a1b2c3d", e.Commit)
+	assert.Equal(t, crash.StateFixed, e.State)
+	assert.False(t, e.Time.IsZero())
+}
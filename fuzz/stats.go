@@ -0,0 +1,343 @@
+package fuzz
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/NishantBansal2003/LND-Fuzz/parser"
+)
+
+// StatsDirName is the directory, relative to the fuzz results path, where the
+// statistics of every fuzzing cycle are saved.
+const StatsDirName = "stats"
+
+// TargetOutcome is how the run of a fuzz target over a cycle ended.
+type TargetOutcome string
+
+const (
+	// OutcomePassed is a fuzz target that ran without failing.
+	OutcomePassed TargetOutcome = "passed"
+
+	// OutcomeFailed is a fuzz target that found a crash.
+	OutcomeFailed TargetOutcome = "failed"
+
+	// OutcomeError is a fuzz target that could not be run, e.g. because
+	// its package does not build.
+	OutcomeError TargetOutcome = "error"
+
+	// OutcomeSkipped is a fuzz target that was stopped through the
+	// control API, or that the cycle ended before it started.
+	OutcomeSkipped TargetOutcome = "skipped"
+)
+
+// TargetFailure describes the crash found by a fuzz target.
+type TargetFailure struct {
+	// Class is the kind of failure, e.g. "panic".
+	Class string `json:"class"`
+
+	// Signature identifies the crash.
+	Signature string `json:"signature"`
+
+	// Summary is the normalized one line description of the crash.
+	Summary string `json:"summary"`
+
+	// Excerpt is an excerpt of the stack trace, or of the failure output
+	// if the failure has no stack trace.
+	Excerpt string `json:"excerpt"`
+
+	// New indicates that the crash signature was seen for the first time.
+	New bool `json:"new,omitempty"`
+}
+
+// TargetSummary condenses the progress reports of a fuzz target over a cycle.
+type TargetSummary struct {
+	// Execs is the total number of executions of the fuzz function.
+	Execs int64 `json:"execs"`
+
+	// AvgExecsPerSec is the average execution rate over the cycle.
+	AvgExecsPerSec float64 `json:"avg_execs_per_sec"`
+
+	// CorpusSize is the size of the corpus at the end of the cycle.
+	CorpusSize int64 `json:"corpus_size"`
+
+	// NewInteresting is the number of inputs that expanded coverage
+	// during the cycle.
+	NewInteresting int64 `json:"new_interesting"`
+
+	// Starved indicates that the target made no progress: it never
+	// reported any, or its execution rate dropped to zero.
+	Starved bool `json:"starved"`
+}
+
+// TargetStats holds the time series of progress reports of a fuzz target over
+// a cycle.
+type TargetStats struct {
+	// Package is the package containing the fuzz target.
+	Package string `json:"package"`
+
+	// Target is the name of the fuzz target.
+	Target string `json:"target"`
+
+	// Outcome is how the run of the fuzz target ended.
+	Outcome TargetOutcome `json:"outcome"`
+
+	// Duration is the time spent running the fuzz target, restarts
+	// included.
+	Duration time.Duration `json:"duration"`
+
+	// Failure describes the first crash found by the fuzz target, if any.
+	Failure *TargetFailure `json:"failure,omitempty"`
+
+	// Message explains why the fuzz target could not be run or was
+	// skipped.
+	Message string `json:"message,omitempty"`
+
+	// Summary condenses the samples.
+	Summary TargetSummary `json:"summary"`
+
+	// Samples are the progress reports of the fuzzer, oldest first.
+	Samples []parser.StatsSample `json:"samples"`
+}
+
+// summarize computes the summary of the samples.
+func (ts *TargetStats) summarize() {
+	if len(ts.Samples) == 0 {
+		ts.Summary = TargetSummary{Starved: true}
+		return
+	}
+
+	last := ts.Samples[len(ts.Samples)-1]
+	ts.Summary = TargetSummary{
+		Execs:          last.Execs,
+		CorpusSize:     last.CorpusSize,
+		NewInteresting: last.NewInteresting,
+		Starved:        last.ExecsPerSec == 0,
+	}
+	if secs := last.Elapsed.Seconds(); secs > 0 {
+		ts.Summary.AvgExecsPerSec = float64(last.Execs) / secs
+	}
+}
+
+// PackageError is a package whose fuzz targets could not be listed, most often
+// because it does not build.
+type PackageError struct {
+	// Package is the package whose fuzz targets could not be listed.
+	Package string `json:"package"`
+
+	// Message is the error output.
+	Message string `json:"message"`
+}
+
+// CycleStats holds the statistics of every fuzz target run in a cycle.
+type CycleStats struct {
+	// Cycle is the identifier of the cycle.
+	Cycle string `json:"cycle"`
+
+	// Start is the time the cycle started.
+	Start time.Time `json:"start"`
+
+	// End is the time the last fuzz target of the cycle finished.
+	End time.Time `json:"end"`
+
+	// Commit is the project commit fuzzed during the cycle, if known.
+	Commit string `json:"commit,omitempty"`
+
+	// GoVersion is the version of the Go toolchain the project was fuzzed
+	// with, if known.
+	GoVersion string `json:"go_version,omitempty"`
+
+	// Targets lists the statistics of each fuzz target, sorted by package
+	// and target name.
+	Targets []*TargetStats `json:"targets"`
+
+	// PackageErrors lists the packages whose fuzz targets could not be
+	// listed, sorted by package.
+	PackageErrors []PackageError `json:"package_errors,omitempty"`
+}
+
+// cycle tracks a single fuzzing cycle and collects the results of its fuzz
+// targets, which run concurrently.
+type cycle struct {
+	// id identifies the cycle, see newCycleID.
+	id string
+
+	// start is the time the cycle started.
+	start time.Time
+
+	// commit is the project commit fuzzed during the cycle, empty if it
+	// could not be resolved.
+	commit string
+
+	// goVersion is the version of the Go toolchain the project is fuzzed
+	// with, empty if it could not be resolved.
+	goVersion string
+
+	mu            sync.Mutex
+	targets       []*TargetStats
+	packageErrors []PackageError
+	pending       []*parser.PendingCrash
+}
+
+// newCycle starts tracking a cycle beginning now, fuzzing the given project
+// commit with the given Go toolchain version.
+func newCycle(commit, goVersion string) *cycle {
+	start := time.Now().UTC()
+	return &cycle{
+		id:        newCycleID(start),
+		start:     start,
+		commit:    commit,
+		goVersion: goVersion,
+	}
+}
+
+// addTargetStats records the outcome and progress reports of a fuzz target
+// run.
+func (c *cycle) addTargetStats(stats *TargetStats) {
+	stats.summarize()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.targets = append(c.targets, stats)
+}
+
+// addPackageError records that the fuzz targets of the package could not be
+// listed.
+func (c *cycle) addPackageError(pkg string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.packageErrors = append(c.packageErrors, PackageError{
+		Package: pkg,
+		Message: err.Error(),
+	})
+}
+
+// addPendingCrash records a new crash to be bisected once the fuzz targets of
+// the cycle are done.
+func (c *cycle) addPendingCrash(p *parser.PendingCrash) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pending = append(c.pending, p)
+}
+
+// pendingCrashes returns the new crashes waiting to be bisected.
+func (c *cycle) pendingCrashes() []*parser.PendingCrash {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]*parser.PendingCrash(nil), c.pending...)
+}
+
+// stats returns the statistics collected so far.
+func (c *cycle) stats() *CycleStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	targets := append([]*TargetStats(nil), c.targets...)
+	sort.Slice(targets, func(i, j int) bool {
+		if targets[i].Package != targets[j].Package {
+			return targets[i].Package < targets[j].Package
+		}
+		return targets[i].Target < targets[j].Target
+	})
+
+	packageErrors := slices.Clone(c.packageErrors)
+	sort.Slice(packageErrors, func(i, j int) bool {
+		return packageErrors[i].Package < packageErrors[j].Package
+	})
+
+	return &CycleStats{
+		Cycle:         c.id,
+		Start:         c.start,
+		End:           time.Now().UTC(),
+		Commit:        c.commit,
+		GoVersion:     c.goVersion,
+		Targets:       targets,
+		PackageErrors: packageErrors,
+	}
+}
+
+// saveStats writes the statistics of the cycle to
+// <FuzzResultsPath>/stats/<cycle>.json and logs a summary of each target.
+func saveStats(logger *slog.Logger, cfg *config.Config,
+	stats *CycleStats) error {
+
+	for _, ts := range stats.Targets {
+		logger.Info("Fuzz target statistics", "package", ts.Package,
+			"target", ts.Target, "outcome", ts.Outcome,
+			"execs", ts.Summary.Execs,
+			"avgExecsPerSec", int64(ts.Summary.AvgExecsPerSec),
+			"corpusSize", ts.Summary.CorpusSize, "newInteresting",
+			ts.Summary.NewInteresting, "starved",
+			ts.Summary.Starved)
+	}
+
+	statsDir := filepath.Join(cfg.FuzzResultsPath, StatsDirName)
+	if err := config.EnsureDirExists(statsDir); err != nil {
+		return fmt.Errorf("failed to create stats directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode stats: %w", err)
+	}
+
+	statsPath := filepath.Join(statsDir, stats.Cycle+".json")
+	if err := os.WriteFile(statsPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write stats: %w", err)
+	}
+
+	logger.Info("Cycle statistics saved", "cycle", stats.Cycle, "path",
+		statsPath, "targets", len(stats.Targets))
+
+	return nil
+}
+
+// LoadStats reads the statistics of every cycle saved under the fuzz results
+// path, newest first.
+func LoadStats(cfg *config.Config) ([]*CycleStats, error) {
+	statsDir := filepath.Join(cfg.FuzzResultsPath, StatsDirName)
+	entries, err := os.ReadDir(statsDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stats: %w", err)
+	}
+
+	var cycles []*CycleStats
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(statsDir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read stats: %w", err)
+		}
+
+		stats := &CycleStats{}
+		if err := json.Unmarshal(data, stats); err != nil {
+			return nil, fmt.Errorf("failed to decode stats %q: %w",
+				entry.Name(), err)
+		}
+		cycles = append(cycles, stats)
+	}
+
+	// Cycle identifiers sort chronologically.
+	sort.Slice(cycles, func(i, j int) bool {
+		return cycles[i].Cycle > cycles[j].Cycle
+	})
+
+	return cycles, nil
+}
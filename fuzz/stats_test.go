@@ -0,0 +1,116 @@
+package fuzz
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/NishantBansal2003/LND-Fuzz/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSaveStats verifies that the statistics of a cycle are summarized per
+// target and saved as JSON in the fuzz results directory.
+func TestSaveStats(t *testing.T) {
+	cfg := &config.Config{FuzzResultsPath: t.TempDir()}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	c := newCycle("", "")
+	c.addTargetStats(&TargetStats{
+		Package: "foo",
+		Target:  "FuzzHealthy",
+		Outcome: OutcomePassed,
+		Samples: []parser.StatsSample{{
+			Elapsed:     3 * time.Second,
+			Execs:       300,
+			ExecsPerSec: 100,
+			CorpusSize:  10,
+		}, {
+			Elapsed:        6 * time.Second,
+			Execs:          1200,
+			ExecsPerSec:    300,
+			NewInteresting: 2,
+			CorpusSize:     12,
+		}},
+	})
+	c.addTargetStats(&TargetStats{
+		Package: "foo",
+		Target:  "FuzzStalled",
+		Outcome: OutcomePassed,
+		Samples: []parser.StatsSample{{
+			Elapsed:     3 * time.Second,
+			Execs:       30,
+			ExecsPerSec: 0,
+		}},
+	})
+	c.addTargetStats(&TargetStats{
+		Package: "bar",
+		Target:  "FuzzSilent",
+		Outcome: OutcomeSkipped,
+		Message: "the cycle ended before the fuzz target started",
+	})
+
+	require.NoError(t, saveStats(logger, cfg, c.stats()))
+
+	data, err := os.ReadFile(filepath.Join(cfg.FuzzResultsPath,
+		StatsDirName, c.id+".json"))
+	require.NoError(t, err)
+
+	var stats CycleStats
+	require.NoError(t, json.Unmarshal(data, &stats))
+
+	assert.Equal(t, c.id, stats.Cycle)
+	require.Len(t, stats.Targets, 3)
+
+	// Targets are sorted by package, then by name.
+	assert.Equal(t, "FuzzSilent", stats.Targets[0].Target)
+	assert.Equal(t, OutcomeSkipped, stats.Targets[0].Outcome)
+	assert.Equal(t, TargetSummary{Starved: true},
+		stats.Targets[0].Summary)
+
+	assert.Equal(t, "FuzzHealthy", stats.Targets[1].Target)
+	assert.Equal(t, TargetSummary{
+		Execs:          1200,
+		AvgExecsPerSec: 200,
+		CorpusSize:     12,
+		NewInteresting: 2,
+	}, stats.Targets[1].Summary)
+	assert.Len(t, stats.Targets[1].Samples, 2)
+
+	assert.Equal(t, "FuzzStalled", stats.Targets[2].Target)
+	assert.True(t, stats.Targets[2].Summary.Starved)
+}
+
+// TestLoadStats verifies that the saved statistics of every cycle are loaded,
+// newest first.
+func TestLoadStats(t *testing.T) {
+	cfg := &config.Config{FuzzResultsPath: t.TempDir()}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	cycles, err := LoadStats(cfg)
+	require.NoError(t, err)
+	assert.Empty(t, cycles)
+
+	now := time.Now()
+	for _, start := range []time.Time{now.Add(-time.Hour), now} {
+		c := &cycle{id: newCycleID(start), start: start}
+		c.addTargetStats(&TargetStats{
+			Package: "foo",
+			Target:  "FuzzFoo",
+		})
+		require.NoError(t, saveStats(logger, cfg, c.stats()))
+	}
+
+	cycles, err = LoadStats(cfg)
+	require.NoError(t, err)
+	require.Len(t, cycles, 2)
+	assert.Equal(t, newCycleID(now), cycles[0].Cycle)
+	assert.Equal(t, newCycleID(now.Add(-time.Hour)), cycles[1].Cycle)
+	assert.Equal(t, "FuzzFoo", cycles[0].Targets[0].Target)
+}
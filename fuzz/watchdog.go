@@ -0,0 +1,73 @@
+package fuzz
+
+import (
+	"log/slog"
+	"os/exec"
+	"time"
+
+	"github.com/NishantBansal2003/LND-Fuzz/parser"
+)
+
+const (
+	// maxStallCheckInterval is the longest time between two progress
+	// checks of the stall watchdog.
+	maxStallCheckInterval = 10 * time.Second
+
+	// stallDumpGracePeriod is how long a stalled fuzz target is given to
+	// dump its goroutines before it is killed.
+	stallDumpGracePeriod = 10 * time.Second
+
+	// maxStallRestarts is the number of times a stalled fuzz target is
+	// restarted within a cycle before it is skipped.
+	maxStallRestarts = 2
+)
+
+// watchForStall monitors the progress of the fuzz target run by cmd, as seen
+// by the processor of its output. Once the target has made no progress for
+// the given timeout, it marks the processor as stalled, makes the processes
+// dump their goroutines with SIGQUIT, and kills them if they are still
+// running after a grace period. It returns when done is closed, which must
+// happen once the command has exited.
+func watchForStall(logger *slog.Logger, cmd *exec.Cmd,
+	processor *parser.FuzzProcessor, timeout time.Duration,
+	done <-chan struct{}) {
+
+	ticker := time.NewTicker(min(timeout/4, maxStallCheckInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		// The build and test setup preceding fuzzing are not watched.
+		lastProgress := processor.LastProgress()
+		if lastProgress.IsZero() || time.Since(lastProgress) < timeout {
+			continue
+		}
+
+		logger.Warn("Fuzz target stalled, dumping goroutines",
+			"lastProgress", lastProgress, "timeout", timeout)
+
+		processor.MarkStalled()
+		if err := quitProcessGroup(cmd); err != nil {
+			logger.Error("Failed to dump goroutines of "+
+				"stalled fuzz target", "error", err)
+		}
+
+		select {
+		case <-done:
+		case <-time.After(stallDumpGracePeriod):
+			logger.Warn("Stalled fuzz target did not exit, " +
+				"killing it")
+			if err := killProcessGroup(cmd); err != nil {
+				logger.Error("Failed to kill stalled fuzz "+
+					"target", "error", err)
+			}
+		}
+
+		return
+	}
+}
@@ -0,0 +1,63 @@
+//go:build unix
+
+package fuzz
+
+import (
+	"io"
+	"log/slog"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/NishantBansal2003/LND-Fuzz/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWatchForStall verifies that a process group that stops reporting
+// progress is interrupted, including its child processes, and that the
+// interruption is recorded as a hang.
+func TestWatchForStall(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &config.Config{FuzzResultsPath: t.TempDir()}
+
+	// The shell reports progress once, then waits on a child process that
+	// never exits by itself.
+	cmd := exec.Command("sh", "-c", "echo 'fuzz: elapsed: 0s, execs: 1 "+
+		"(1/sec), new interesting: 0 (total: 1)'; sleep 60")
+	setProcessGroup(cmd)
+	stdout, err := cmd.StdoutPipe()
+	require.NoError(t, err)
+	require.NoError(t, cmd.Start())
+
+	processor := parser.NewFuzzProcessor(logger, cfg, t.TempDir(), "foo",
+		"FuzzFoo", "", "", nil)
+	streamDone := make(chan error, 1)
+	go func() {
+		streamDone <- processor.ProcessStream(stdout)
+	}()
+
+	done := make(chan struct{})
+	watchdogDone := make(chan struct{})
+	go func() {
+		watchForStall(logger, cmd, processor, 200*time.Millisecond,
+			done)
+		close(watchdogDone)
+	}()
+
+	// The pipe is only closed once the sleeping child has exited too.
+	select {
+	case err := <-streamDone:
+		require.NoError(t, err)
+	case <-time.After(30 * time.Second):
+		t.Fatal("stalled process group was not interrupted")
+	}
+
+	assert.Error(t, cmd.Wait())
+	close(done)
+	<-watchdogDone
+
+	assert.True(t, processor.State.Stalled)
+	assert.Equal(t, parser.ClassHang, processor.State.Class)
+}
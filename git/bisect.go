@@ -0,0 +1,261 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Verdict is the outcome of testing a commit while bisecting.
+type Verdict int
+
+const (
+	// VerdictGood is a commit without the bug.
+	VerdictGood Verdict = iota
+
+	// VerdictBad is a commit with the bug.
+	VerdictBad
+
+	// VerdictSkip is a commit that cannot be tested, e.g. because it does
+	// not build.
+	VerdictSkip
+)
+
+// String returns the name of the verdict, as used by "git bisect".
+func (v Verdict) String() string {
+	switch v {
+	case VerdictGood:
+		return "good"
+	case VerdictBad:
+		return "bad"
+	default:
+		return "skip"
+	}
+}
+
+// BisectTest tests the commit checked out in the worktree at dir.
+type BisectTest func(ctx context.Context, dir string) (Verdict, error)
+
+// Bisection is the outcome of searching the history of a repository for the
+// commit introducing a bug.
+type Bisection struct {
+	// FirstBad is the commit introducing the bug, empty if it could not be
+	// narrowed down to a single commit.
+	FirstBad string `json:"first_bad,omitempty"`
+
+	// LastGood is the most recent commit found without the bug, empty if
+	// none of the searched commits is free of it.
+	LastGood string `json:"last_good,omitempty"`
+
+	// Candidates lists the commits which may have introduced the bug,
+	// oldest first, when untestable commits prevent narrowing it down to
+	// a single one.
+	Candidates []string `json:"candidates,omitempty"`
+
+	// Searched is the number of commits the search was limited to.
+	Searched int `json:"searched"`
+
+	// Tested is the number of commits tested.
+	Tested int `json:"tested"`
+
+	// Skipped lists the commits that could not be tested.
+	Skipped []string `json:"skipped,omitempty"`
+
+	// Time is when the search finished.
+	Time time.Time `json:"time"`
+}
+
+// Found reports whether the search narrowed the bug down to a commit or to
+// a range of candidates.
+func (b *Bisection) Found() bool {
+	return b.FirstBad != "" || len(b.Candidates) > 0
+}
+
+// Bisect searches the first-parent history of the repository at repoPath,
+// from the bad commit back at most maxCommits commits, for the commit
+// introducing a bug. The history is checked out in a temporary clone, so that
+// the worktree of the repository is left untouched, and test is run against
+// each candidate: first against the oldest testable commit, which must be
+// good, then by binary search. Commits the test skips are left out of the
+// search. The bad commit itself is not tested.
+func Bisect(ctx context.Context, logger *slog.Logger, repoPath, bad string,
+	maxCommits int, test BisectTest) (*Bisection, error) {
+
+	history, err := firstParentHistory(repoPath, bad, maxCommits)
+	if err != nil {
+		return nil, err
+	}
+
+	absPath, err := filepath.Abs(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve repository path: %w",
+			err)
+	}
+	cloneDir, err := os.MkdirTemp("", "bisect-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bisect worktree: %w",
+			err)
+	}
+	defer os.RemoveAll(cloneDir)
+
+	clone, err := git.PlainCloneContext(ctx, cloneDir, false,
+		&git.CloneOptions{URL: absPath})
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone %q for bisecting: %w",
+			repoPath, err)
+	}
+	worktree, err := clone.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bisect worktree: %w",
+			err)
+	}
+
+	b := &Bisection{Searched: len(history)}
+	verdicts := make([]*Verdict, len(history))
+	testAt := func(i int) (Verdict, error) {
+		err := worktree.Checkout(&git.CheckoutOptions{
+			Hash:  history[i],
+			Force: true,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to check out %s: %w",
+				history[i], err)
+		}
+
+		verdict, err := test(ctx, cloneDir)
+		if err != nil {
+			return 0, fmt.Errorf("failed to test %s: %w",
+				history[i], err)
+		}
+
+		b.Tested++
+		verdicts[i] = &verdict
+		if verdict == VerdictSkip {
+			b.Skipped = append(b.Skipped, history[i].String())
+		}
+		logger.Info("Bisect step", "commit", history[i], "verdict",
+			verdict)
+
+		return verdict, nil
+	}
+
+	// Find a good commit to start from, the oldest testable one.
+	good, badIndex := -1, len(history)-1
+	for i := 0; i < badIndex && good < 0; i++ {
+		verdict, err := testAt(i)
+		if err != nil {
+			return nil, err
+		}
+
+		switch verdict {
+		case VerdictGood:
+			good = i
+		case VerdictBad:
+			// The bug predates the searched history.
+			b.Time = time.Now().UTC()
+			return b, nil
+		}
+	}
+	if good < 0 {
+		b.Time = time.Now().UTC()
+		return b, nil
+	}
+
+	// Narrow the range down, testing the untested commit closest to its
+	// middle.
+	for {
+		next := -1
+		mid := (good + badIndex) / 2
+		for i := good + 1; i < badIndex; i++ {
+			if verdicts[i] != nil {
+				continue
+			}
+			if next < 0 || abs(i-mid) < abs(next-mid) {
+				next = i
+			}
+		}
+		if next < 0 {
+			break
+		}
+
+		verdict, err := testAt(next)
+		if err != nil {
+			return nil, err
+		}
+
+		switch verdict {
+		case VerdictGood:
+			good = next
+		case VerdictBad:
+			badIndex = next
+		}
+	}
+
+	b.LastGood = history[good].String()
+	if badIndex-good == 1 {
+		b.FirstBad = history[badIndex].String()
+	} else {
+		for _, hash := range history[good+1 : badIndex+1] {
+			b.Candidates = append(b.Candidates, hash.String())
+		}
+	}
+	b.Time = time.Now().UTC()
+
+	return b, nil
+}
+
+// firstParentHistory returns the commits of the repository following the
+// first parents of the given commit, at most maxCommits of them including it,
+// oldest first.
+func firstParentHistory(repoPath, commit string,
+	maxCommits int) ([]plumbing.Hash, error) {
+
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository %q: %w",
+			repoPath, err)
+	}
+
+	c, err := repo.CommitObject(plumbing.NewHash(commit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find commit %s: %w", commit,
+			err)
+	}
+
+	var history []plumbing.Hash
+	for len(history) < maxCommits {
+		history = append(history, c.Hash)
+
+		c, err = c.Parent(0)
+		if errors.Is(err, object.ErrParentNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk history: %w",
+				err)
+		}
+	}
+
+	for i, j := 0, len(history)-1; i < j; i, j = i+1, j-1 {
+		history[i], history[j] = history[j], history[i]
+	}
+
+	return history, nil
+}
+
+// abs returns the absolute value of n.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
@@ -0,0 +1,160 @@
+package git
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newHistory creates a repository with a commit per state, each committing
+// the state to the "state" file, and returns its path and the commit hashes,
+// oldest first.
+func newHistory(t *testing.T, states ...string) (string, []string) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	var hashes []string
+	for i, state := range states {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "state"),
+			[]byte(state), 0644))
+		_, err = worktree.Add("state")
+		require.NoError(t, err)
+
+		hash, err := worktree.Commit(state, &git.CommitOptions{
+			Author: &object.Signature{
+				Name:  "fuzz",
+				Email: "fuzz@example.com",
+				When:  time.Unix(int64(i), 0),
+			},
+			AllowEmptyCommits: true,
+		})
+		require.NoError(t, err)
+		hashes = append(hashes, hash.String())
+	}
+
+	return dir, hashes
+}
+
+// stateTest judges a commit by the content of its "state" file.
+func stateTest(ctx context.Context, dir string) (Verdict, error) {
+	state, err := os.ReadFile(filepath.Join(dir, "state"))
+	if err != nil {
+		return 0, err
+	}
+
+	switch string(state) {
+	case "good":
+		return VerdictGood, nil
+	case "bad":
+		return VerdictBad, nil
+	default:
+		return VerdictSkip, nil
+	}
+}
+
+// TestBisect verifies that the commit introducing a bug is found, or the
+// range of candidates if untestable commits hide it.
+func TestBisect(t *testing.T) {
+	tests := []struct {
+		name       string
+		states     []string
+		maxCommits int
+
+		// Indexes of the expected commits, -1 if none.
+		firstBad   int
+		lastGood   int
+		candidates []int
+		skipped    int
+	}{
+		{
+			name: "single commit",
+			states: []string{"good", "good", "good", "good",
+				"bad", "bad", "bad", "bad", "bad", "bad"},
+			maxCommits: 100,
+			firstBad:   4,
+			lastGood:   3,
+		},
+		{
+			name: "untestable commits",
+			states: []string{"good", "good", "good", "good",
+				"broken", "broken", "bad", "bad", "bad"},
+			maxCommits: 100,
+			firstBad:   -1,
+			lastGood:   3,
+			candidates: []int{4, 5, 6},
+			skipped:    2,
+		},
+		{
+			name: "untestable oldest commit",
+			states: []string{"broken", "good", "good", "bad",
+				"bad"},
+			maxCommits: 100,
+			firstBad:   3,
+			lastGood:   2,
+			skipped:    1,
+		},
+		{
+			name:       "bug predates the history",
+			states:     []string{"bad", "bad", "bad"},
+			maxCommits: 100,
+			firstBad:   -1,
+			lastGood:   -1,
+		},
+		{
+			name: "bug predates the searched commits",
+			states: []string{"good", "bad", "bad", "bad",
+				"bad"},
+			maxCommits: 3,
+			firstBad:   -1,
+			lastGood:   -1,
+		},
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, hashes := newHistory(t, tt.states...)
+			head := hashes[len(hashes)-1]
+
+			b, err := Bisect(context.Background(), logger, dir,
+				head, tt.maxCommits, stateTest)
+			require.NoError(t, err)
+
+			hash := func(i int) string {
+				if i < 0 {
+					return ""
+				}
+				return hashes[i]
+			}
+			assert.Equal(t, hash(tt.firstBad), b.FirstBad)
+			assert.Equal(t, hash(tt.lastGood), b.LastGood)
+			var candidates []string
+			for _, i := range tt.candidates {
+				candidates = append(candidates, hashes[i])
+			}
+			assert.Equal(t, candidates, b.Candidates)
+			assert.Equal(t, tt.firstBad >= 0 || candidates != nil,
+				b.Found())
+			assert.Len(t, b.Skipped, tt.skipped)
+			assert.Equal(t, min(len(hashes), tt.maxCommits),
+				b.Searched)
+
+			// The worktree of the repository is left untouched.
+			commit, err := HeadCommit(dir)
+			require.NoError(t, err)
+			assert.Equal(t, head, commit)
+		})
+	}
+}
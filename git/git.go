@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"log/slog"
 	"net/url"
+	"time"
 
 	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/NishantBansal2003/LND-Fuzz/metrics"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/go-git/go-git/v5"
@@ -70,6 +72,7 @@ func (bc *BaseCloner) Clone(ctx context.Context, logger *slog.Logger) error {
 	logger.Info("Cloning repository", "url", sanitizeURL(bc.URL),
 		"path", bc.Path, "desc", bc.Desc)
 
+	start := time.Now()
 	_, err := git.PlainCloneContext(ctx, bc.Path, false, &git.CloneOptions{
 		URL: bc.URL,
 	})
@@ -78,9 +81,31 @@ func (bc *BaseCloner) Clone(ctx context.Context, logger *slog.Logger) error {
 			err)
 	}
 
+	metrics.CloneDuration.WithLabelValues(bc.Desc).Observe(
+		time.Since(start).Seconds(),
+	)
+
 	return nil
 }
 
+// HeadCommit returns the hash of the commit checked out in the repository at
+// the given path.
+func HeadCommit(path string) (string, error) {
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository %q: %w", path,
+			err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD of %q: %w", path,
+			err)
+	}
+
+	return head.Hash().String(), nil
+}
+
 // ProjectCloner is responsible for cloning the project repository.
 type ProjectCloner struct {
 	*BaseCloner
@@ -1,9 +1,15 @@
 package git
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestSanitizeURL verifies that the sanitizeURL function correctly masks
@@ -41,3 +47,33 @@ func TestSanitizeURL(t *testing.T) {
 		})
 	}
 }
+
+// TestHeadCommit verifies that the commit checked out in a repository is
+// resolved, and that a directory without a repository is an error.
+func TestHeadCommit(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README"),
+		[]byte("fuzz"), 0644))
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = worktree.Add("README")
+	require.NoError(t, err)
+	hash, err := worktree.Commit("initial commit", &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  "fuzz",
+			Email: "fuzz@example.com",
+			When:  time.Now(),
+		},
+	})
+	require.NoError(t, err)
+
+	commit, err := HeadCommit(dir)
+	require.NoError(t, err)
+	assert.Equal(t, hash.String(), commit)
+
+	_, err = HeadCommit(t.TempDir())
+	assert.Error(t, err)
+}
@@ -2,35 +2,126 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"log/slog"
 	"os"
+	"os/exec"
 	"os/signal"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
 	"syscall"
-	"time"
-
-	"log/slog"
+	"text/tabwriter"
 
 	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/NishantBansal2003/LND-Fuzz/control"
+	"github.com/NishantBansal2003/LND-Fuzz/corpus"
+	"github.com/NishantBansal2003/LND-Fuzz/crash"
+	"github.com/NishantBansal2003/LND-Fuzz/fuzz"
+	"github.com/NishantBansal2003/LND-Fuzz/git"
+	"github.com/NishantBansal2003/LND-Fuzz/notify"
+	"github.com/NishantBansal2003/LND-Fuzz/report"
 	"github.com/NishantBansal2003/LND-Fuzz/scheduler"
+	"github.com/NishantBansal2003/LND-Fuzz/server"
 )
 
-// main is the entry point of the application. It sets up signal handling for
-// graceful shutdown, loads configuration, and starts the continuous fuzzing
-// cycles.
+// handler runs a command with its parsed flags and arguments.
+type handler func(ctx context.Context, logger *slog.Logger,
+	flags *flag.FlagSet) error
+
+// handlers maps the name of each command of config.Commands to its handler.
+var handlers = map[string]handler{
+	"run":             runCommand,
+	"list-targets":    listTargetsCommand,
+	"reproduce":       reproduceCommand,
+	"corpus stats":    corpusStatsCommand,
+	"corpus minimize": corpusMinimizeCommand,
+	"sarif":           sarifCommand,
+	"validate-config": validateConfigCommand,
+	"version":         versionCommand,
+	"help":            helpCommand,
+}
+
+// errUsage is returned by a command given invalid arguments, after its usage
+// has been printed.
+var errUsage = errors.New("invalid arguments")
+
+const (
+	// exitUsage is the exit status of a command given invalid arguments.
+	exitUsage = 2
+
+	// exitInterrupted is the exit status of a single fuzzing cycle
+	// interrupted by a signal, as reported by shells for SIGINT.
+	exitInterrupted = 130
+)
+
+// onceExitStatus maps the outcome of a single fuzzing cycle to the exit status
+// of "run --once", which tells CI jobs how the cycle ended.
+var onceExitStatus = map[fuzz.CycleOutcome]int{
+	fuzz.CycleNoFindings:   0,
+	fuzz.CycleNewCrash:     1,
+	fuzz.CycleBuildFailure: 3,
+	fuzz.CycleInfraError:   4,
+}
+
+// exitStatusError ends the fuzzer with the given exit status, after printing
+// the wrapped error, if any.
+type exitStatusError struct {
+	status int
+	err    error
+}
+
+// Error returns the message of the wrapped error, or the exit status.
+func (e *exitStatusError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit status %d", e.status)
+	}
+
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *exitStatusError) Unwrap() error {
+	return e.err
+}
+
+// main is the entry point of the application. It looks up the command named
+// by the arguments, defaulting to "run", parses its flags as defined in
+// config.Commands, and runs it until it completes or an interrupt signal is
+// received.
 func main() {
-	// Display help text if "help" argument is provided.
-	if len(os.Args) > 1 && os.Args[1] == "help" {
-		fmt.Println(config.HelpText)
-		os.Exit(0)
+	cmd, args := config.LookupCommand(os.Args[1:])
+	if cmd == nil {
+		if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+			fmt.Fprintf(os.Stderr, "Unknown command %q.\n\n%s\n",
+				strings.Join(args, " "), config.HelpText)
+			os.Exit(exitUsage)
+		}
+
+		// Without a command, start the fuzzing cycles.
+		cmd, _ = config.LookupCommand([]string{"run"})
+	}
+
+	flags := newFlagSet(cmd)
+	_ = flags.Parse(args)
+
+	// Initialize a structured logger that outputs logs in text format. The
+	// logs of the fuzzing cycles go to the standard output, those of the
+	// other commands to the standard error, which leaves the standard
+	// output to their results.
+	logOutput := os.Stderr
+	if cmd.Name == "run" {
+		logOutput = os.Stdout
 	}
+	logger := slog.New(slog.NewTextHandler(logOutput, nil))
 
 	// Create a cancellable context to manage the application's lifecycle.
 	appCtx, cancelApp := context.WithCancel(context.Background())
 	defer cancelApp()
 
-	// Initialize a structured logger that outputs logs in text format.
-	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-
 	// Set up signal handling for graceful shutdown on SIGINT and SIGTERM.
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -41,30 +132,561 @@ func main() {
 		cancelApp()
 	}()
 
+	err := handlers[cmd.Name](appCtx, logger, flags)
+
+	var statusErr *exitStatusError
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.Is(err, errUsage):
+		os.Exit(exitUsage)
+	case errors.As(err, &statusErr):
+		if statusErr.err != nil {
+			fmt.Fprintln(os.Stderr, statusErr.err)
+		}
+		os.Exit(statusErr.status)
+	case errors.As(err, &exitErr):
+		// The command ran a process, e.g. a reproducer script, whose
+		// exit status is the outcome.
+		os.Exit(exitErr.ExitCode())
+	default:
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// newFlagSet returns the flag set of the command, built from its definition,
+// with a flag mirroring every environment variable if the command reads the
+// configuration. Boolean flags are those without a value placeholder, the
+// others are strings defaulting to empty, their documented default being
+// resolved by the command.
+func newFlagSet(cmd *config.Command) *flag.FlagSet {
+	flags := flag.NewFlagSet(cmd.Name, flag.ExitOnError)
+	cmdFlags := cmd.Flags
+	if cmd.ConfigFlags {
+		for _, v := range config.EnvVars {
+			cmdFlags = append(cmdFlags, v.Flag())
+		}
+	}
+	for _, f := range cmdFlags {
+		if f.Arg == "" {
+			flags.Bool(f.Name, false, f.Usage)
+		} else {
+			flags.String(f.Name, "", f.Usage)
+		}
+	}
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), cmd.Help())
+	}
+
+	return flags
+}
+
+// usageError prints the usage of the command and returns errUsage.
+func usageError(flags *flag.FlagSet, format string, args ...any) error {
+	fmt.Fprintf(flags.Output(), format+"\n\n", args...)
+	flags.Usage()
+
+	return errUsage
+}
+
+// stringFlag returns the value of the string flag.
+func stringFlag(flags *flag.FlagSet, name string) string {
+	return flags.Lookup(name).Value.String()
+}
+
+// boolFlag returns the value of the boolean flag.
+func boolFlag(flags *flag.FlagSet, name string) bool {
+	return flags.Lookup(name).Value.String() == "true"
+}
+
+// configFlags returns the values of the flags mirroring environment variables
+// given on the command line, by the name of their variable.
+func configFlags(flags *flag.FlagSet) config.Flags {
+	names := make(map[string]string, len(config.EnvVars))
+	for _, v := range config.EnvVars {
+		names[v.Flag().Name] = v.Name
+	}
+
+	values := make(config.Flags)
+	flags.Visit(func(f *flag.Flag) {
+		if name, ok := names[f.Name]; ok {
+			values[name] = f.Value.String()
+		}
+	})
+
+	return values
+}
+
+// runCommand starts the continuous fuzzing cycles or, with --once, runs a
+// single cycle and exits with the status of its outcome, see onceExitStatus.
+// With --print-config, it prints the configuration instead.
+func runCommand(ctx context.Context, logger *slog.Logger,
+	flags *flag.FlagSet) error {
+
+	if flags.NArg() > 0 {
+		return usageError(flags, "Unexpected arguments: %s",
+			strings.Join(flags.Args(), " "))
+	}
+	once := boolFlag(flags, "once")
+
 	// Load environment variables from a .env file.
 	if err := config.LoadEnv(); err != nil {
-		logger.Error("Failed to load environment variables", "error",
+		return runError(once, fmt.Errorf("failed to load environment "+
+			"variables: %w", err))
+	}
+
+	// Load configuration settings from the flags, environment variables
+	// and configuration file.
+	cfg, err := config.LoadConfig(configFlags(flags))
+	if err != nil {
+		return runError(once, fmt.Errorf("failed to load "+
+			"configuration: %w", err))
+	}
+	if boolFlag(flags, "print-config") {
+		return config.PrintConfig(os.Stdout, cfg, configFlags(flags))
+	}
+
+	// A fuzzing cycle lasts at most CYCLE_DURATION, which covers its
+	// longest fuzz target and the clone and build overhead.
+	cycleDuration := cfg.CycleDuration
+
+	// Let the fuzzing cycles be steered through the HTTP API.
+	ctl := control.New()
+	ctx = control.NewContext(ctx, ctl)
+
+	// Announce new crashes to the configured webhooks, if any.
+	notifier := notify.New(logger, cfg)
+	ctx = notify.NewContext(ctx, notifier)
+	notifierDone := make(chan struct{})
+	go func() {
+		notifier.Run(ctx)
+		close(notifierDone)
+	}()
+
+	// Expose the metrics and the API over HTTP, if enabled.
+	if cfg.HTTPAddr != "" {
+		err := server.Start(ctx, logger, cfg, ctl)
+		if err != nil {
+			return runError(once, fmt.Errorf("failed to start "+
+				"HTTP server: %w", err))
+		}
+	}
+
+	if !once {
+		// Start the continuous fuzzing cycles.
+		scheduler.RunFuzzingCycles(ctx, logger, cfg, cycleDuration)
+		logger.Info("Program exited.")
+
+		return nil
+	}
+
+	// Run a single fuzzing cycle, then send the pending notifications.
+	stats, err := scheduler.RunFuzzingOnce(ctx, logger, cfg)
+	notifier.Close()
+	<-notifierDone
+
+	if ctx.Err() != nil {
+		logger.Info("Program interrupted.")
+		return &exitStatusError{status: exitInterrupted}
+	}
+
+	outcome := fuzz.Outcome(stats, err)
+	if err != nil {
+		logger.Error("Fuzzing cycle failed", "error", err)
+	}
+	logger.Info("Program exited.", "outcome", outcome, "exitStatus",
+		onceExitStatus[outcome])
+	if outcome == fuzz.CycleNoFindings {
+		return nil
+	}
+
+	return &exitStatusError{status: onceExitStatus[outcome]}
+}
+
+// runError returns the error preventing the fuzzing cycles from starting. For
+// a single cycle, the error ends the fuzzer with the exit status of an
+// infrastructure error.
+func runError(once bool, err error) error {
+	if !once {
+		return err
+	}
+
+	return &exitStatusError{
+		status: onceExitStatus[fuzz.CycleInfraError],
+		err:    err,
+	}
+}
+
+// listTargetsCommand prints the fuzz targets of the given packages, or of
+// FUZZ_PKG, one "<package>/<target>" per line.
+func listTargetsCommand(ctx context.Context, logger *slog.Logger,
+	flags *flag.FlagSet) error {
+
+	if err := config.LoadEnv(); err != nil {
+		return err
+	}
+	cfg, err := config.LoadTargetConfig(configFlags(flags))
+	if err != nil {
+		return err
+	}
+
+	pkgs := flags.Args()
+	if len(pkgs) == 0 {
+		pkgs = cfg.FuzzPkgs
+	}
+	if len(pkgs) == 0 {
+		return usageError(flags, "No package given, and FUZZ_PKG is "+
+			"not set.")
+	}
+
+	projectDir, cleanup, err := checkoutProject(ctx, logger, flags)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	for _, pkg := range pkgs {
+		targets, err := fuzz.ListFuzzTargets(ctx, logger, projectDir,
+			pkg, cfg.Target(pkg, "").BuildTags)
+		if err != nil {
+			return err
+		}
+		for _, target := range targets {
+			fmt.Printf("%s/%s\n", pkg, target)
+		}
+	}
+
+	return nil
+}
+
+// reproduceCommand runs the reproducer bundle of the crash named by a prefix
+// of its signature. The exit status of the bundle script is the outcome.
+func reproduceCommand(ctx context.Context, logger *slog.Logger,
+	flags *flag.FlagSet) error {
+
+	if flags.NArg() != 1 {
+		return usageError(flags, "Expected the signature of a crash.")
+	}
+
+	resultsPath, err := resultsDir(flags)
+	if err != nil {
+		return err
+	}
+	store := crash.NewStore(&config.Config{FuzzResultsPath: resultsPath})
+	record, err := store.Lookup(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	script := store.ReproducerScript(record)
+	if script == "" {
+		return fmt.Errorf("crash %s has no reproducer bundle, e.g. "+
+			"because it has no failing input", record.Signature)
+	}
+
+	args := []string{script}
+	if project := stringFlag(flags, "project"); project != "" {
+		args = append(args, project)
+	}
+	logger.Info("Reproducing crash", "signature", record.Signature,
+		"script", script)
+
+	cmd := exec.CommandContext(ctx, "sh", args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
+
+// corpusStatsCommand prints the number of inputs and the size of the corpus of
+// every fuzz target in the results directory.
+func corpusStatsCommand(_ context.Context, _ *slog.Logger,
+	flags *flag.FlagSet) error {
+
+	if flags.NArg() > 0 {
+		return usageError(flags, "Unexpected arguments: %s",
+			strings.Join(flags.Args(), " "))
+	}
+
+	resultsPath, err := resultsDir(flags)
+	if err != nil {
+		return err
+	}
+	targets, err := corpus.Targets(resultsPath)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PACKAGE\tTARGET\tINPUTS\tBYTES")
+	var inputs int
+	var bytes int64
+	for _, target := range targets {
+		stats, err := corpus.Stat(target)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\n", target.Package, target.Name,
+			stats.Inputs, stats.Bytes)
+
+		inputs += stats.Inputs
+		bytes += stats.Bytes
+	}
+	fmt.Fprintf(w, "total\t\t%d\t%d\n", inputs, bytes)
+
+	return w.Flush()
+}
+
+// corpusMinimizeCommand minimizes the corpus of every fuzz target in the
+// results directory, see corpus.Minimize.
+func corpusMinimizeCommand(ctx context.Context, logger *slog.Logger,
+	flags *flag.FlagSet) error {
+
+	if flags.NArg() > 0 {
+		return usageError(flags, "Unexpected arguments: %s",
+			strings.Join(flags.Args(), " "))
+	}
+
+	resultsPath, err := resultsDir(flags)
+	if err != nil {
+		return err
+	}
+	targets, err := corpus.Targets(resultsPath)
+	if err != nil {
+		return err
+	}
+
+	projectDir, cleanup, err := checkoutProject(ctx, logger, flags)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	dryRun := boolFlag(flags, "dry-run")
+	verb := "removed"
+	if dryRun {
+		verb = "would remove"
+	}
+	for _, target := range targets {
+		pkgDir := filepath.Join(projectDir, target.Package)
+		if _, err := os.Stat(pkgDir); errors.Is(err, os.ErrNotExist) {
+			logger.Warn("Package not found in the project, "+
+				"skipping its corpus", "package",
+				target.Package, "target", target.Name)
+			continue
+		}
+
+		logger.Info("Minimizing corpus", "package", target.Package,
+			"target", target.Name)
+		m, err := corpus.Minimize(ctx, logger, projectDir, target,
+			dryRun)
+		if err != nil {
+			return fmt.Errorf("failed to minimize corpus of "+
+				"%s/%s: %w", target.Package, target.Name, err)
+		}
+
+		fmt.Printf("%s/%s: kept %d, failing %d, %s %d of %d bytes "+
+			"(%d inputs)\n", target.Package, target.Name,
+			len(m.Kept), len(m.Failing), verb, m.RemovedBytes,
+			m.Bytes, len(m.Removed))
+	}
+
+	return nil
+}
+
+// sarifCommand writes the SARIF log of the crash database of a results
+// directory to the file given with -o, or to the standard output. The results
+// directory is given as argument, and defaults to the one derived from
+// FUZZ_RESULTS_PATH.
+func sarifCommand(_ context.Context, _ *slog.Logger,
+	flags *flag.FlagSet) error {
+
+	var resultsPath string
+	switch flags.NArg() {
+	case 0:
+		dir, err := defaultResultsDir(flags)
+		if err != nil {
+			return err
+		}
+		resultsPath = dir
+	case 1:
+		resultsPath = flags.Arg(0)
+	default:
+		return usageError(flags, "Expected at most one results "+
+			"directory.")
+	}
+
+	store := crash.NewStore(&config.Config{FuzzResultsPath: resultsPath})
+	if _, err := os.Stat(store.Dir()); err != nil {
+		return fmt.Errorf("no crash database in %q: %w", resultsPath,
+			err)
+	}
+
+	output := stringFlag(flags, "o")
+	if output == "" {
+		return report.WriteSARIF(os.Stdout, store)
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("failed to create SARIF log: %w", err)
+	}
+	if err := report.WriteSARIF(f, store); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// validateConfigCommand checks the configuration the fuzzing cycles would run
+// with.
+func validateConfigCommand(_ context.Context, _ *slog.Logger,
+	flags *flag.FlagSet) error {
+
+	if flags.NArg() > 0 {
+		return usageError(flags, "Unexpected arguments: %s",
+			strings.Join(flags.Args(), " "))
+	}
+
+	if err := config.LoadEnv(); err != nil {
+		return fmt.Errorf("failed to load environment variables: %w",
 			err)
-		os.Exit(1)
+	}
+	if _, err := config.LoadConfig(configFlags(flags)); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	fmt.Println("Configuration is valid.")
+
+	return nil
+}
+
+// versionCommand prints the version of the fuzzer, from the build information
+// embedded in the binary, and of the Go toolchain it was built with.
+func versionCommand(_ context.Context, _ *slog.Logger,
+	flags *flag.FlagSet) error {
+
+	if flags.NArg() > 0 {
+		return usageError(flags, "Unexpected arguments: %s",
+			strings.Join(flags.Args(), " "))
+	}
+
+	version, revision := "unknown", ""
+	if info, ok := debug.ReadBuildInfo(); ok {
+		version = info.Main.Version
+		for _, setting := range info.Settings {
+			switch {
+			case setting.Key == "vcs.revision":
+				revision = setting.Value
+			case setting.Key == "vcs.modified" &&
+				setting.Value == "true":
+
+				revision += "-dirty"
+			}
+		}
+	}
+
+	fmt.Printf("go-continuous-fuzz %s", version)
+	if revision != "" {
+		fmt.Printf(" (%s)", revision)
+	}
+	fmt.Printf("\n%s %s/%s\n", runtime.Version(), runtime.GOOS,
+		runtime.GOARCH)
+
+	return nil
+}
+
+// helpCommand prints the help message, or the help of the given command.
+func helpCommand(_ context.Context, _ *slog.Logger,
+	flags *flag.FlagSet) error {
+
+	if flags.NArg() == 0 {
+		fmt.Println(config.HelpText)
+		return nil
 	}
 
-	// Load configuration settings from environment variables.
-	cfg, err := config.LoadConfig()
+	cmd, rest := config.LookupCommand(flags.Args())
+	if cmd == nil || len(rest) > 0 {
+		return usageError(flags, "Unknown command %q.",
+			strings.Join(flags.Args(), " "))
+	}
+	fmt.Println(cmd.Help())
+
+	return nil
+}
+
+// resultsDir returns the results directory given with --results, defaulting
+// to the one derived from FUZZ_RESULTS_PATH.
+func resultsDir(flags *flag.FlagSet) (string, error) {
+	if dir := stringFlag(flags, "results"); dir != "" {
+		return dir, nil
+	}
+
+	return defaultResultsDir(flags)
+}
+
+// defaultResultsDir returns the results directory derived from
+// FUZZ_RESULTS_PATH, given as flag, in the environment or in the
+// configuration file.
+func defaultResultsDir(flags *flag.FlagSet) (string, error) {
+	if err := config.LoadEnv(); err != nil {
+		return "", err
+	}
+	path, err := config.Setting(configFlags(flags), "FUZZ_RESULTS_PATH")
 	if err != nil {
-		logger.Error("Failed to load configuration", "error", err)
-		os.Exit(1)
+		return "", err
 	}
 
-	// Parse the fuzzing cycle duration from configuration (e.g., "20s").
-	cycleDuration, err := time.ParseDuration(cfg.FuzzTime)
+	return filepath.Join(path, config.DefaultReportName), nil
+}
+
+// checkoutProject returns the project checkout given with --project or, if
+// none is given, clones PROJECT_SRC_PATH into a temporary directory. The
+// returned function removes the clone, if any. The workspace of the fuzzing
+// cycles is never used, as they may be running in the same directory.
+func checkoutProject(ctx context.Context, logger *slog.Logger,
+	flags *flag.FlagSet) (string, func(), error) {
+
+	if dir := stringFlag(flags, "project"); dir != "" {
+		return dir, func() {}, nil
+	}
+
+	if err := config.LoadEnv(); err != nil {
+		return "", nil, err
+	}
+	url, err := config.Setting(configFlags(flags), "PROJECT_SRC_PATH")
 	if err != nil {
-		logger.Error("Error parsing cycle duration", "durationString",
-			cfg.FuzzTime, "error", err)
-		os.Exit(1)
+		return "", nil, err
+	}
+	if url == "" {
+		return "", nil, errors.New("PROJECT_SRC_PATH environment " +
+			"variable required, or give --project")
+	}
+	tmpDir, err := os.MkdirTemp("", "go-continuous-fuzz-project-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create project "+
+			"directory: %w", err)
+	}
+	cleanup := func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			logger.Error("Project cleanup failed", "path", tmpDir,
+				"error", err)
+		}
 	}
 
-	// Start the continuous fuzzing cycles.
-	scheduler.RunFuzzingCycles(appCtx, logger, cfg, cycleDuration)
+	projectDir := filepath.Join(tmpDir, "project")
+	cloner := &git.BaseCloner{
+		URL:  url,
+		Path: projectDir,
+		Desc: "project",
+	}
+	if err := cloner.Clone(ctx, logger); err != nil {
+		cleanup()
+		return "", nil, err
+	}
 
-	logger.Info("Program exited.")
+	return projectDir, cleanup, nil
 }
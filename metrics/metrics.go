@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// namespace prefixes the name of every metric exported by the fuzzer.
+const namespace = "continuous_fuzz"
+
+var (
+	// CyclesCompleted counts the fuzzing cycles that ran for their full
+	// duration.
+	CyclesCompleted = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cycles_completed_total",
+		Help:      "Number of fuzzing cycles completed.",
+	})
+
+	// CycleStartTime is the start time of the current fuzzing cycle.
+	CycleStartTime = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "cycle_start_time_seconds",
+		Help: "Start time of the current fuzzing cycle, in seconds " +
+			"since the Unix epoch.",
+	})
+
+	// TargetExecsPerSecond is the latest execution rate reported by each
+	// fuzz target.
+	TargetExecsPerSecond = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "target_execs_per_second",
+		Help:      "Latest execution rate reported by the fuzz target.",
+	}, []string{"package", "target"})
+
+	// TargetCorpusSize is the latest corpus size reported by each fuzz
+	// target.
+	TargetCorpusSize = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "target_corpus_size",
+		Help:      "Latest corpus size reported by the fuzz target.",
+	}, []string{"package", "target"})
+
+	// CrashesFound counts the failures recorded in the crash database.
+	CrashesFound = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "crashes_found_total",
+		Help: "Number of failures found, by class and fuzz " +
+			"target.",
+	}, []string{"class", "package", "target"})
+
+	// CloneDuration observes how long each repository clone takes.
+	CloneDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "clone_duration_seconds",
+		Help:      "Time taken to clone a repository.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 10),
+	}, []string{"repository"})
+
+	// TargetRestarts counts the restarts of stalled fuzz targets.
+	TargetRestarts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "target_restarts_total",
+		Help:      "Number of restarts of stalled fuzz targets.",
+	}, []string{"package", "target"})
+)
@@ -0,0 +1,292 @@
+package notify
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/NishantBansal2003/LND-Fuzz/crash"
+)
+
+const (
+	// githubAPIVersion is the version of the GitHub REST API the requests
+	// are made against.
+	githubAPIVersion = "2022-11-28"
+
+	// maxIssueTitle is the maximum length of the title of a GitHub issue.
+	maxIssueTitle = 256
+)
+
+// templateFS holds the markdown templates of the issue filed for a new crash,
+// "issue.md", of the comment added to it whenever the crash recurs,
+// "comment.md", and of the comment closing it once the crash is fixed,
+// "fixed.md".
+//
+//go:embed templates/*.md
+var templateFS embed.FS
+
+// issueTemplates are the parsed issue and comment templates.
+var issueTemplates = template.Must(template.New("").Funcs(template.FuncMap{
+	"codeBlock":  codeBlock,
+	"reproducer": reproducer,
+}).ParseFS(templateFS, "templates/*.md"))
+
+// issueTracker files the crashes as issues of a GitHub repository.
+type issueTracker struct {
+	// apiURL is the base URL of the GitHub REST API.
+	apiURL string
+
+	// repo is the "owner/name" repository the issues are filed in.
+	repo string
+
+	// header holds the authentication and versioning headers sent with
+	// every request.
+	header http.Header
+
+	// store is the crash database, which keeps the issue filed for each
+	// crash.
+	store *crash.Store
+
+	// queue holds the crashes waiting to be filed.
+	queue chan *Event
+}
+
+// newIssueTracker creates the issue tracker of the configured repository.
+func newIssueTracker(cfg *config.Config) *issueTracker {
+	header := make(http.Header)
+	header.Set("Accept", "application/vnd.github+json")
+	header.Set("Authorization", "Bearer "+cfg.GitHubToken)
+	header.Set("X-GitHub-Api-Version", githubAPIVersion)
+
+	return &issueTracker{
+		apiURL: cfg.GitHubAPIURL,
+		repo:   cfg.GitHubRepo,
+		header: header,
+		store:  crash.NewStore(cfg),
+		queue:  make(chan *Event, queueSize),
+	}
+}
+
+// fileIssue opens an issue for the crash, or comments on the issue already
+// filed for it, reopening the issue if the crash was fixed. A crash recurring
+// at the commit its open issue was last updated for, e.g. hit again by another
+// fuzz target or in a later cycle of the same commit, is not commented on
+// again; neither is a crash recurring at an unknown commit. A fixed crash is
+// commented on, then its issue is closed.
+func (n *Notifier) fileIssue(ctx context.Context, e *Event) error {
+	t := n.issues
+
+	record, err := t.store.Record(e.Signature)
+	if err != nil {
+		return err
+	}
+
+	if e.State == crash.StateFixed {
+		if record == nil || record.Issue == 0 || record.IssueClosed {
+			return nil
+		}
+
+		if err := n.commentIssue(ctx, record.Issue, "fixed.md",
+			e); err != nil {
+
+			return err
+		}
+
+		return n.setIssueState(ctx, e.Signature, record.Issue, true)
+	}
+
+	if record != nil && record.Issue != 0 {
+		if !record.IssueClosed && (e.Commit == "" ||
+			e.Commit == record.LastCommented) {
+
+			return nil
+		}
+
+		if err := n.commentIssue(ctx, record.Issue, "comment.md",
+			e); err != nil {
+
+			return err
+		}
+		err := t.store.SetLastCommented(e.Signature, e.Commit)
+		if err != nil {
+			return fmt.Errorf("failed to record comment on issue "+
+				"%d: %w", record.Issue, err)
+		}
+
+		n.logger.Info("Crash recurrence added to GitHub issue",
+			"repository", t.repo, "issue", record.Issue,
+			"signature", e.Signature)
+
+		if !record.IssueClosed {
+			return nil
+		}
+
+		return n.setIssueState(ctx, e.Signature, record.Issue, false)
+	}
+
+	body, err := render("issue.md", e)
+	if err != nil {
+		return err
+	}
+
+	var issue struct {
+		Number  int    `json:"number"`
+		HTMLURL string `json:"html_url"`
+	}
+	endpoint := fmt.Sprintf("%s/repos/%s/issues", t.apiURL, t.repo)
+	err = n.callGitHub(ctx, http.MethodPost, endpoint, map[string]string{
+		"title": issueTitle(e),
+		"body":  body,
+	}, &issue)
+	if err != nil {
+		return fmt.Errorf("failed to open issue: %w", err)
+	}
+
+	if err := t.store.SetIssue(e.Signature, issue.Number); err != nil {
+		return fmt.Errorf("failed to record issue %d: %w",
+			issue.Number, err)
+	}
+	err = t.store.SetLastCommented(e.Signature, e.Commit)
+	if err != nil {
+		return fmt.Errorf("failed to record issue %d: %w",
+			issue.Number, err)
+	}
+
+	n.logger.Info("GitHub issue filed for crash", "repository", t.repo,
+		"issue", issue.Number, "url", issue.HTMLURL, "signature",
+		e.Signature)
+
+	return nil
+}
+
+// commentIssue adds a comment to the issue, rendered from the named template.
+func (n *Notifier) commentIssue(ctx context.Context, issue int, name string,
+	e *Event) error {
+
+	body, err := render(name, e)
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("%s/repos/%s/issues/%d/comments",
+		n.issues.apiURL, n.issues.repo, issue)
+	err = n.callGitHub(ctx, http.MethodPost, endpoint, map[string]string{
+		"body": body,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to comment on issue %d: %w", issue,
+			err)
+	}
+
+	return nil
+}
+
+// setIssueState closes or reopens the issue filed for the crash with the given
+// signature, and records its state.
+func (n *Notifier) setIssueState(ctx context.Context, signature string,
+	issue int, closed bool) error {
+
+	state, reason := "open", "reopened"
+	if closed {
+		state, reason = "closed", "completed"
+	}
+
+	endpoint := fmt.Sprintf("%s/repos/%s/issues/%d", n.issues.apiURL,
+		n.issues.repo, issue)
+	err := n.callGitHub(ctx, http.MethodPatch, endpoint,
+		map[string]string{"state": state, "state_reason": reason}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to update issue %d: %w", issue, err)
+	}
+
+	if err := n.issues.store.SetIssueClosed(signature,
+		closed); err != nil {
+
+		return fmt.Errorf("failed to record state of issue %d: %w",
+			issue, err)
+	}
+
+	n.logger.Info("GitHub issue state updated", "repository",
+		n.issues.repo, "issue", issue, "state", state, "signature",
+		signature)
+
+	return nil
+}
+
+// callGitHub sends the request to the GitHub API with the given method,
+// retrying failed attempts, and decodes the response into out, unless it is
+// nil.
+func (n *Notifier) callGitHub(ctx context.Context, method, endpoint string,
+	request any, out any) error {
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	return n.retry(ctx, n.issues.repo, func() (time.Duration, error) {
+		return n.send(ctx, method, endpoint, n.issues.header, body,
+			out)
+	})
+}
+
+// issueTitle returns the title of the issue filed for the crash.
+func issueTitle(e *Event) string {
+	title := []rune(fmt.Sprintf("%s in %s: %s", e.Class, e.Target,
+		e.Summary))
+	if len(title) > maxIssueTitle {
+		title = append(title[:maxIssueTitle-1], '…')
+	}
+
+	return string(title)
+}
+
+// reproducer returns the shell commands reproducing the crash from a checkout
+// of the fuzzed project: running the saved failing input as a test, or fuzzing
+// the target if there is none.
+func reproducer(e *Event) string {
+	var b strings.Builder
+	if e.Commit != "" {
+		fmt.Fprintf(&b, "git checkout %s\n", e.Commit)
+	}
+	fmt.Fprintf(&b, "cd %s\n", e.Package)
+
+	if e.InputID != "" && e.Input != "" {
+		fmt.Fprintf(&b, "go test -run='^%s$/^%s$' .\n", e.Target,
+			e.InputID)
+	} else {
+		fmt.Fprintf(&b, "go test -run='^$' -fuzz='^%s$' .\n",
+			e.Target)
+	}
+
+	return b.String()
+}
+
+// render executes the named issue template with the event.
+func render(name string, e *Event) (string, error) {
+	var b strings.Builder
+	if err := issueTemplates.ExecuteTemplate(&b, name, e); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+
+	return b.String(), nil
+}
+
+// codeBlock formats the text as a markdown code block of the given language.
+// The fence is longer than any run of backticks in the text, so that the
+// text cannot end the block early.
+func codeBlock(lang, text string) string {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+
+	return fence + lang + "\n" + strings.TrimSuffix(text, "\n") + "\n" +
+		fence
+}
@@ -0,0 +1,307 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/NishantBansal2003/LND-Fuzz/crash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// githubRequest is a request received by the GitHub API stand-in.
+type githubRequest struct {
+	method string
+	path   string
+	header http.Header
+	body   map[string]string
+}
+
+// githubServer is a stand-in for the issues endpoints of the GitHub REST API.
+// Its first responses are rate limits, as many as requested.
+type githubServer struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	rateLimited int
+	requests    []githubRequest
+}
+
+// newGitHubServer starts a GitHub API stand-in rate limiting the given number
+// of requests before accepting any.
+func newGitHubServer(t *testing.T, rateLimited int) *githubServer {
+	s := &githubServer{rateLimited: rateLimited}
+	s.Server = httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			if s.rateLimited > 0 {
+				s.rateLimited--
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.Header().Set("X-RateLimit-Reset", "0")
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			req := githubRequest{
+				method: r.Method,
+				path:   r.URL.Path,
+				header: r.Header,
+			}
+			if err := json.NewDecoder(r.Body).Decode(
+				&req.body); err != nil {
+
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			s.requests = append(s.requests, req)
+
+			w.WriteHeader(http.StatusCreated)
+			if strings.HasSuffix(r.URL.Path, "/issues") {
+				_, _ = io.WriteString(w, `{"number": 7, `+
+					`"html_url": "https://github.com/`+
+					`OWNER/REPO/issues/7"}`)
+				return
+			}
+			_, _ = io.WriteString(w, `{"id": 1}`)
+		},
+	))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// received returns the requests accepted so far.
+func (s *githubServer) received() []githubRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]githubRequest(nil), s.requests...)
+}
+
+// TestFileIssue verifies that an issue is filed for a new crash, after
+// waiting out the rate limit, that recurrences are added as comments, and that
+// the issue is closed once the crash is fixed and reopened if it regresses.
+func TestFileIssue(t *testing.T) {
+	srv := newGitHubServer(t, 1)
+	cfg := &config.Config{
+		FuzzResultsPath: t.TempDir(),
+		GitHubRepo:      "OWNER/REPO",
+		GitHubToken:     "ghp_secret",
+		GitHubAPIURL:    srv.URL,
+	}
+
+	event := testEvent()
+	event.Commit = "0a1b2c3d"
+	_, err := crash.NewStore(cfg).Add(&crash.Finding{
+		Signature: event.Signature,
+		Package:   event.Package,
+		Target:    event.Target,
+	})
+	require.NoError(t, err)
+
+	n := New(slog.New(slog.NewTextHandler(io.Discard, nil)), cfg)
+	require.NotNil(t, n)
+	n.minInterval = time.Millisecond
+	n.retryDelay = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		n.Run(ctx)
+		close(done)
+	}()
+
+	fixed := testEvent()
+	fixed.Commit = "4e5f6a7b"
+	fixed.State = crash.StateFixed
+
+	recurrence := testEvent()
+	recurrence.Commit = "1c2d3e4f"
+	regression := testEvent()
+	regression.Commit = "8a9b0c1d"
+
+	// Recurrences at the commit the issue was last updated for are not
+	// commented on.
+	n.Notify(event)
+	n.NotifyRecurrence(event)
+	n.NotifyRecurrence(recurrence)
+	n.NotifyRecurrence(recurrence)
+	n.Notify(fixed)
+	n.NotifyRecurrence(regression)
+
+	require.Eventually(t, func() bool {
+		return len(srv.received()) == 6
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+
+	requests := srv.received()
+	require.Len(t, requests, 6)
+
+	// The new crash is filed as an issue.
+	issue := requests[0]
+	assert.Equal(t, "/repos/OWNER/REPO/issues", issue.path)
+	assert.Equal(t, "Bearer ghp_secret",
+		issue.header.Get("Authorization"))
+	assert.Equal(t, githubAPIVersion,
+		issue.header.Get("X-GitHub-Api-Version"))
+	assert.Equal(t, "panic in FuzzFoo: runtime error: index out of range",
+		issue.body["title"])
+	for _, want := range []string{
+		"while fuzzing commit 0a1b2c3d",
+		"**Signature:** `3f2a9c1d`",
+		"**Reproducibility:** flaky, the failing input failed 3 of 5 " +
+			"verification runs\n**Introduced by:** 9e8d7c6b, " +
+			"the last good commit being 5a4b3c2d\n\n" +
+			"### Stack trace",
+		"```\nfoo.Parse\n\tfoo/parse.go:42\n```",
+		"`testdata/fuzz/FuzzFoo/771e938e4458e983`",
+		"```\ngo test fuzz v1\nstring(\"0\")\n```",
+		"git checkout 0a1b2c3d\ncd foo/bar\n" +
+			"go test -run='^FuzzFoo$/^771e938e4458e983$' .",
+	} {
+		assert.Contains(t, issue.body["body"], want)
+	}
+
+	// Its number is kept with the crash, and the recurrence at another
+	// commit is added as a comment, once.
+	record, err := crash.NewStore(cfg).Record(event.Signature)
+	require.NoError(t, err)
+	assert.Equal(t, 7, record.Issue)
+
+	comment := requests[1]
+	assert.Equal(t, "/repos/OWNER/REPO/issues/7/comments", comment.path)
+	assert.Contains(t, comment.body["body"], "reproduced again by "+
+		"`FuzzFoo` in `foo/bar` while fuzzing commit 1c2d3e4f")
+
+	// The fix is commented on, and the issue closed.
+	comment = requests[2]
+	assert.Equal(t, "/repos/OWNER/REPO/issues/7/comments", comment.path)
+	assert.Contains(t, comment.body["body"], "replayed against commit "+
+		"4e5f6a7b")
+	update := requests[3]
+	assert.Equal(t, http.MethodPatch, update.method)
+	assert.Equal(t, "/repos/OWNER/REPO/issues/7", update.path)
+	assert.Equal(t, "closed", update.body["state"])
+
+	// The regression is commented on, and the issue reopened.
+	comment = requests[4]
+	assert.Equal(t, "/repos/OWNER/REPO/issues/7/comments", comment.path)
+	assert.Contains(t, comment.body["body"], "while fuzzing commit "+
+		"8a9b0c1d")
+	update = requests[5]
+	assert.Equal(t, http.MethodPatch, update.method)
+	assert.Equal(t, "open", update.body["state"])
+
+	record, err = crash.NewStore(cfg).Record(event.Signature)
+	require.NoError(t, err)
+	assert.False(t, record.IssueClosed)
+	assert.Equal(t, "8a9b0c1d", record.LastCommented)
+}
+
+// TestIssueTitle verifies that long issue titles are truncated.
+func TestIssueTitle(t *testing.T) {
+	e := testEvent()
+	e.Summary = strings.Repeat("x", 2*maxIssueTitle)
+
+	title := issueTitle(e)
+	assert.Len(t, []rune(title), maxIssueTitle)
+	assert.True(t, strings.HasPrefix(title, "panic in FuzzFoo: xxx"))
+}
+
+// TestTruncatedInputIssue verifies that an issue never presents a truncated
+// failing input as a file to save, but points to the reproducer bundle.
+func TestTruncatedInputIssue(t *testing.T) {
+	e := testEvent()
+	e.Input = strings.Repeat("x", 64)
+	e.InputTruncated = true
+
+	body, err := render("issue.md", e)
+	require.NoError(t, err)
+	assert.Contains(t, body, "`771e938e4458e983` is too large to be "+
+		"shown here")
+	assert.Contains(t, body, "go run main.go reproduce 3f2a9c1d")
+	assert.NotContains(t, body, e.Input)
+	assert.NotContains(t, body, "Save the failing input")
+}
+
+// TestCodeBlock verifies that backticks in the text cannot close the code
+// block early.
+func TestCodeBlock(t *testing.T) {
+	assert.Equal(t, "```go\nx := 1\n```", codeBlock("go", "x := 1\n"))
+	assert.Equal(t, "````\nstring(\"```\")\n````",
+		codeBlock("", "string(\"```\")"))
+}
+
+// TestRateLimitWait verifies how long rate limited requests wait before being
+// retried.
+func TestRateLimitWait(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		header      map[string]string
+		wantWait    time.Duration
+		wantLimited bool
+	}{
+		{
+			name:        "too many requests",
+			status:      http.StatusTooManyRequests,
+			wantLimited: true,
+		},
+		{
+			name:        "retry after",
+			status:      http.StatusForbidden,
+			header:      map[string]string{"Retry-After": "60"},
+			wantWait:    time.Minute,
+			wantLimited: true,
+		},
+		{
+			name:   "exhausted quota",
+			status: http.StatusForbidden,
+			header: map[string]string{
+				"X-RateLimit-Remaining": "0",
+				"X-RateLimit-Reset":     "0",
+			},
+			wantLimited: true,
+		},
+		{
+			name:   "forbidden",
+			status: http.StatusForbidden,
+			header: map[string]string{
+				"X-RateLimit-Remaining": "4999",
+			},
+		},
+		{
+			name:   "server error",
+			status: http.StatusInternalServerError,
+			header: map[string]string{"Retry-After": "60"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: tt.status,
+				Header:     make(http.Header),
+			}
+			for name, value := range tt.header {
+				resp.Header.Set(name, value)
+			}
+
+			wait, limited := rateLimitWait(resp)
+			assert.Equal(t, tt.wantLimited, limited)
+			assert.Equal(t, tt.wantWait, wait)
+		})
+	}
+}
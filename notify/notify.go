@@ -0,0 +1,440 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/NishantBansal2003/LND-Fuzz/crash"
+	"github.com/NishantBansal2003/LND-Fuzz/git"
+)
+
+const (
+	// queueSize is the number of notifications waiting to be sent to a
+	// webhook before new ones are dropped.
+	queueSize = 100
+
+	// defaultMinInterval is the minimum time between two notifications
+	// sent to the same webhook.
+	defaultMinInterval = 5 * time.Second
+
+	// defaultRetryDelay is the delay before the first retry of a failed
+	// notification. It doubles with every further attempt.
+	defaultRetryDelay = time.Second
+
+	// defaultMaxAttempts is the number of times a notification is tried
+	// before it is dropped.
+	defaultMaxAttempts = 4
+
+	// requestTimeout bounds each attempt to send a notification.
+	requestTimeout = 30 * time.Second
+)
+
+// Event describes a recorded crash, or a crash found fixed.
+type Event struct {
+	// Signature identifies the crash.
+	Signature string `json:"signature"`
+
+	// Class is the kind of failure, e.g. "panic".
+	Class string `json:"class"`
+
+	// Priority is the triage priority of the crash class, 1 being the
+	// most urgent.
+	Priority int `json:"priority"`
+
+	// Summary is the normalized one line description of the crash.
+	Summary string `json:"summary"`
+
+	// Package is the package containing the fuzz target.
+	Package string `json:"package"`
+
+	// Target is the name of the fuzz target that found the crash.
+	Target string `json:"target"`
+
+	// Stack is an excerpt of the stack trace, or of the failure output if
+	// the failure has no stack trace.
+	Stack string `json:"stack"`
+
+	// InputID is the name of the failing input file, if any.
+	InputID string `json:"input_id,omitempty"`
+
+	// Input is the failing input in the "go test fuzz v1" format, if any.
+	Input string `json:"input,omitempty"`
+
+	// InputTruncated tells that Input holds only the beginning of a
+	// larger failing input, which cannot be run as is.
+	InputTruncated bool `json:"input_truncated,omitempty"`
+
+	// Commit is the hash of the fuzzed project commit, if known.
+	Commit string `json:"commit,omitempty"`
+
+	// Verification tells how reliably the failing input reproduces the
+	// crash, if it was verified.
+	Verification *crash.Verification `json:"verification,omitempty"`
+
+	// Bisection tells which commit introduced the crash, if it was
+	// bisected.
+	Bisection *git.Bisection `json:"bisection,omitempty"`
+
+	// State is the lifecycle state of the crash. It is crash.StateFixed
+	// when the event announces that the crash no longer reproduces at
+	// Commit, and empty otherwise.
+	State crash.State `json:"state,omitempty"`
+
+	// Time is when the crash was found.
+	Time time.Time `json:"time"`
+}
+
+// webhook is a notified endpoint along with its pending notifications.
+type webhook struct {
+	config.Webhook
+	queue chan *Event
+}
+
+// Notifier posts new and fixed crashes to the configured webhooks, and files a
+// GitHub issue for each new crash, commenting on it whenever the crash recurs
+// and closing it once the crash is fixed. Each webhook, and the issue tracker,
+// is notified in order, no more often than once per minimum interval, and
+// failed notifications are retried with an exponential backoff. A nil Notifier
+// is valid and sends nothing.
+type Notifier struct {
+	logger   *slog.Logger
+	client   *http.Client
+	webhooks []*webhook
+	issues   *issueTracker
+
+	// closing is closed by Close, so that Run returns once the queues
+	// are empty.
+	closing chan struct{}
+
+	minInterval time.Duration
+	retryDelay  time.Duration
+	maxAttempts int
+}
+
+// New creates a Notifier for the webhooks and the GitHub repository of the
+// configuration. It returns nil if neither is configured. Notifications are
+// only sent once Run is called.
+func New(logger *slog.Logger, cfg *config.Config) *Notifier {
+	if len(cfg.Webhooks) == 0 && cfg.GitHubRepo == "" {
+		return nil
+	}
+
+	n := &Notifier{
+		logger:      logger,
+		client:      &http.Client{Timeout: requestTimeout},
+		closing:     make(chan struct{}),
+		minInterval: defaultMinInterval,
+		retryDelay:  defaultRetryDelay,
+		maxAttempts: defaultMaxAttempts,
+	}
+	for _, wh := range cfg.Webhooks {
+		n.webhooks = append(n.webhooks, &webhook{
+			Webhook: wh,
+			queue:   make(chan *Event, queueSize),
+		})
+	}
+	if cfg.GitHubRepo != "" {
+		n.issues = newIssueTracker(cfg)
+	}
+
+	return n
+}
+
+// contextKey is the key of the Notifier in a context.
+type contextKey struct{}
+
+// NewContext returns a copy of the context carrying the notifier.
+func NewContext(ctx context.Context, n *Notifier) context.Context {
+	return context.WithValue(ctx, contextKey{}, n)
+}
+
+// FromContext returns the notifier carried by the context, or nil.
+func FromContext(ctx context.Context) *Notifier {
+	n, _ := ctx.Value(contextKey{}).(*Notifier)
+	return n
+}
+
+// Notify queues the event of a new or fixed crash for every webhook and for
+// the issue tracker. It never blocks: if a queue is full, the event is dropped
+// for that destination.
+func (n *Notifier) Notify(e *Event) {
+	if n == nil {
+		return
+	}
+
+	for _, wh := range n.webhooks {
+		n.enqueue(wh.queue, redactURL(wh.URL), e)
+	}
+	n.NotifyRecurrence(e)
+}
+
+// NotifyRecurrence queues the event of a known crash for the issue tracker,
+// which comments on the issue filed for the crash. It never blocks.
+func (n *Notifier) NotifyRecurrence(e *Event) {
+	if n == nil || n.issues == nil {
+		return
+	}
+
+	n.enqueue(n.issues.queue, n.issues.repo, e)
+}
+
+// enqueue adds the event to the queue of the named destination, unless the
+// queue is full.
+func (n *Notifier) enqueue(queue chan *Event, dest string, e *Event) {
+	select {
+	case queue <- e:
+	default:
+		n.logger.Warn("Notification queue full, dropping "+
+			"notification", "destination", dest, "signature",
+			e.Signature)
+	}
+}
+
+// Close makes Run return once the notifications queued so far are sent. No
+// notification may be queued after Close.
+func (n *Notifier) Close() {
+	if n == nil {
+		return
+	}
+
+	close(n.closing)
+}
+
+// Run sends the queued notifications until the context is canceled, or until
+// the queues are empty once Close is called.
+func (n *Notifier) Run(ctx context.Context) {
+	if n == nil {
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, wh := range n.webhooks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n.serve(ctx, redactURL(wh.URL), wh.queue,
+				func(e *Event) error {
+					return n.deliver(ctx, wh, e)
+				})
+		}()
+	}
+	if n.issues != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n.serve(ctx, n.issues.repo, n.issues.queue,
+				func(e *Event) error {
+					return n.fileIssue(ctx, e)
+				})
+		}()
+	}
+	wg.Wait()
+}
+
+// serve sends the notifications queued for the named destination, waiting at
+// least the minimum interval between two of them.
+func (n *Notifier) serve(ctx context.Context, dest string, queue chan *Event,
+	send func(*Event) error) {
+
+	var last time.Time
+	for {
+		var e *Event
+		select {
+		case <-ctx.Done():
+			return
+		case e = <-queue:
+		case <-n.closing:
+			// Send what is left in the queue, then stop.
+			select {
+			case e = <-queue:
+			default:
+				return
+			}
+		}
+
+		if wait := n.minInterval - time.Since(last); wait > 0 {
+			if !sleep(ctx, wait) {
+				return
+			}
+		}
+		last = time.Now()
+
+		if err := send(e); err != nil {
+			n.logger.Error("Failed to send notification",
+				"destination", dest, "signature", e.Signature,
+				"error", err)
+		}
+	}
+}
+
+// deliver sends the event to the webhook, retrying failed attempts.
+func (n *Notifier) deliver(ctx context.Context, wh *webhook, e *Event) error {
+	body, err := payload(wh.Format, e)
+	if err != nil {
+		return err
+	}
+
+	err = n.retry(ctx, redactURL(wh.URL), func() (time.Duration, error) {
+		return n.send(ctx, http.MethodPost, wh.URL, nil, body,
+			nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	n.logger.Info("Notification sent", "webhook", redactURL(wh.URL),
+		"signature", e.Signature)
+
+	return nil
+}
+
+// retry calls send until it succeeds, it fails with an error that must not be
+// retried, or the maximum number of attempts is reached. The delay between two
+// attempts doubles every time, unless send asks to wait longer.
+func (n *Notifier) retry(ctx context.Context, dest string,
+	send func() (time.Duration, error)) error {
+
+	delay := n.retryDelay
+	for attempt := 1; ; attempt++ {
+		retryAfter, err := send()
+		if err == nil {
+			return nil
+		}
+		if retryAfter < 0 || attempt == n.maxAttempts {
+			return fmt.Errorf("attempt %d: %w", attempt, err)
+		}
+
+		n.logger.Warn("Notification failed, retrying", "destination",
+			dest, "attempt", attempt, "error", err)
+
+		if !sleep(ctx, max(delay, retryAfter)) {
+			return ctx.Err()
+		}
+		delay *= 2
+	}
+}
+
+// send sends the JSON body to the endpoint once with the given method, along
+// with the given headers, and decodes the JSON response into out, unless it is
+// nil. On failure, it returns how long to wait before retrying as requested by
+// the server, or a negative duration if the request must not be retried.
+func (n *Notifier) send(ctx context.Context, method, endpoint string,
+	header http.Header, body []byte, out any) (time.Duration, error) {
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint,
+		bytes.NewReader(body))
+	if err != nil {
+		return -1, fmt.Errorf("failed to create request: %w",
+			redactError(err))
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %w", redactError(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 300 {
+		if out == nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			return 0, nil
+		}
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return -1, fmt.Errorf("failed to decode response: %w",
+				err)
+		}
+		return 0, nil
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if wait, limited := rateLimitWait(resp); limited {
+		return wait, fmt.Errorf("rate limited: %s", resp.Status)
+	}
+	if resp.StatusCode >= 500 {
+		return 0, fmt.Errorf("server error: %s", resp.Status)
+	}
+
+	return -1, fmt.Errorf("rejected: %s", resp.Status)
+}
+
+// rateLimitWait reports whether the response rejects a rate limited request,
+// and how long the server asks to wait before retrying. Besides "429 Too Many
+// Requests", GitHub answers "403 Forbidden" with either a Retry-After header
+// or an exhausted X-RateLimit-Remaining quota, which resets at the Unix time
+// given by X-RateLimit-Reset.
+func rateLimitWait(resp *http.Response) (time.Duration, bool) {
+	retryAfter := resp.Header.Get("Retry-After")
+	exhausted := resp.Header.Get("X-RateLimit-Remaining") == "0"
+
+	switch {
+	case resp.StatusCode != http.StatusTooManyRequests &&
+		resp.StatusCode != http.StatusForbidden:
+
+		return 0, false
+
+	case retryAfter != "":
+		secs, _ := strconv.Atoi(retryAfter)
+		return time.Duration(secs) * time.Second, true
+
+	case exhausted:
+		reset, _ := strconv.ParseInt(
+			resp.Header.Get("X-RateLimit-Reset"), 10, 64,
+		)
+		return max(time.Until(time.Unix(reset, 0)), 0), true
+
+	default:
+		return 0, resp.StatusCode == http.StatusTooManyRequests
+	}
+}
+
+// sleep waits for the duration, returning false if the context is canceled
+// first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// redactURL returns the scheme and host of the webhook URL, leaving out the
+// path and query that usually embed its secret token.
+func redactURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "<invalid URL>"
+	}
+
+	return parsed.Scheme + "://" + parsed.Host
+}
+
+// redactError redacts the URL the error of a request embeds in its text, e.g.
+// `Post "https://hooks.slack.com/services/...": ...`, as the path of webhook
+// URLs is their secret.
+func redactError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = redactURL(urlErr.URL)
+	}
+
+	return err
+}
@@ -0,0 +1,281 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/NishantBansal2003/LND-Fuzz/crash"
+	"github.com/NishantBansal2003/LND-Fuzz/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// webhookServer is a stand-in webhook answering requests with a predefined
+// sequence of status codes, and recording the bodies and times of the
+// requests it receives.
+type webhookServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	bodies   [][]byte
+	times    []time.Time
+}
+
+// newWebhookServer starts a webhook answering with the given status codes, and
+// with 200 OK once they are exhausted.
+func newWebhookServer(t *testing.T, statuses ...int) *webhookServer {
+	s := &webhookServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			s.bodies = append(s.bodies, body)
+			s.times = append(s.times, time.Now())
+
+			status := http.StatusOK
+			if len(s.statuses) > 0 {
+				status, s.statuses = s.statuses[0],
+					s.statuses[1:]
+			}
+			if status == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", "0")
+			}
+			w.WriteHeader(status)
+		},
+	))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// requests returns the number of requests received so far.
+func (s *webhookServer) requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.bodies)
+}
+
+// newTestNotifier creates a notifier for a single webhook, with delays short
+// enough for tests.
+func newTestNotifier(format, url string) *Notifier {
+	n := New(slog.New(slog.NewTextHandler(io.Discard, nil)),
+		&config.Config{Webhooks: []config.Webhook{
+			{Format: format, URL: url},
+		}})
+	n.minInterval = 50 * time.Millisecond
+	n.retryDelay = time.Millisecond
+
+	return n
+}
+
+// testEvent returns the event used by the tests.
+func testEvent() *Event {
+	return &Event{
+		Signature: "3f2a9c1d",
+		Class:     "panic",
+		Priority:  1,
+		Summary:   "runtime error: index out of range",
+		Package:   "foo/bar",
+		Target:    "FuzzFoo",
+		Stack:     "foo.Parse\n\tfoo/parse.go:42",
+		InputID:   "771e938e4458e983",
+		Input:     "go test fuzz v1\nstring(\"0\")\n",
+		Time:      time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Verification: &crash.Verification{
+			Runs:     5,
+			Failures: 3,
+			Label:    crash.Flaky,
+		},
+		Bisection: &git.Bisection{
+			FirstBad: "9e8d7c6b",
+			LastGood: "5a4b3c2d",
+		},
+	}
+}
+
+// TestDeliver verifies which failed notifications are retried.
+func TestDeliver(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantRequests int
+		wantErr      bool
+	}{
+		{
+			name:         "success",
+			wantRequests: 1,
+		},
+		{
+			name: "server errors are retried",
+			statuses: []int{
+				http.StatusInternalServerError,
+				http.StatusBadGateway,
+			},
+			wantRequests: 3,
+		},
+		{
+			name:         "rate limits are retried",
+			statuses:     []int{http.StatusTooManyRequests},
+			wantRequests: 2,
+		},
+		{
+			name:         "client errors are not retried",
+			statuses:     []int{http.StatusBadRequest},
+			wantRequests: 1,
+			wantErr:      true,
+		},
+		{
+			name: "attempts are bounded",
+			statuses: []int{
+				http.StatusInternalServerError,
+				http.StatusInternalServerError,
+				http.StatusInternalServerError,
+				http.StatusInternalServerError,
+			},
+			wantRequests: defaultMaxAttempts,
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newWebhookServer(t, tt.statuses...)
+			n := newTestNotifier(config.WebhookFormatJSON, srv.URL)
+
+			err := n.deliver(context.Background(), n.webhooks[0],
+				testEvent())
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantRequests, srv.requests())
+		})
+	}
+}
+
+// TestDeliverRedactsURL verifies that the secret path of a webhook URL is kept
+// out of the logs and errors of a failed delivery.
+func TestDeliverRedactsURL(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	var logs bytes.Buffer
+	n := newTestNotifier(config.WebhookFormatSlack,
+		"http://"+addr+"/services/T000/B000/secret")
+	n.logger = slog.New(slog.NewTextHandler(&logs, nil))
+
+	err = n.deliver(context.Background(), n.webhooks[0], testEvent())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "http://"+addr)
+	assert.NotContains(t, err.Error(), "secret")
+
+	assert.Contains(t, logs.String(), "Notification failed, retrying")
+	assert.NotContains(t, logs.String(), "secret")
+}
+
+// TestNotifyRateLimit verifies that queued notifications are all sent, in
+// order, no more often than the minimum interval.
+func TestNotifyRateLimit(t *testing.T) {
+	srv := newWebhookServer(t)
+	n := newTestNotifier(config.WebhookFormatJSON, srv.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		n.Run(ctx)
+		close(done)
+	}()
+
+	signatures := []string{"a", "b", "c"}
+	for _, sig := range signatures {
+		e := testEvent()
+		e.Signature = sig
+		n.Notify(e)
+	}
+
+	require.Eventually(t, func() bool {
+		return srv.requests() == len(signatures)
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	for i, sig := range signatures {
+		var e Event
+		require.NoError(t, json.Unmarshal(srv.bodies[i], &e))
+		assert.Equal(t, sig, e.Signature)
+
+		if i > 0 {
+			assert.GreaterOrEqual(t,
+				srv.times[i].Sub(srv.times[i-1]), n.minInterval)
+		}
+	}
+}
+
+// TestClose verifies that once closed, the notifier sends the queued
+// notifications before Run returns.
+func TestClose(t *testing.T) {
+	srv := newWebhookServer(t)
+	n := newTestNotifier(config.WebhookFormatJSON, srv.URL)
+
+	for _, sig := range []string{"a", "b", "c"} {
+		e := testEvent()
+		e.Signature = sig
+		n.Notify(e)
+	}
+	n.Close()
+
+	done := make(chan struct{})
+	go func() {
+		n.Run(context.Background())
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return once closed")
+	}
+	assert.Equal(t, 3, srv.requests())
+}
+
+// TestNilNotifier verifies that a notifier without webhooks is nil and can be
+// used safely.
+func TestNilNotifier(t *testing.T) {
+	n := New(slog.Default(), &config.Config{})
+	require.Nil(t, n)
+
+	n.Notify(testEvent())
+	n.Run(context.Background())
+	n.Close()
+
+	ctx := NewContext(context.Background(), n)
+	assert.Nil(t, FromContext(ctx))
+	assert.Nil(t, FromContext(context.Background()))
+}
+
+// TestRedactURL verifies that logged webhook URLs leave out their secret.
+func TestRedactURL(t *testing.T) {
+	assert.Equal(t, "https://hooks.slack.com", redactURL(
+		"https://hooks.slack.com/services/T000/B000/XXXX"))
+}
@@ -0,0 +1,147 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/NishantBansal2003/LND-Fuzz/crash"
+)
+
+const (
+	// maxDiscordContent is the maximum length of the content of a Discord
+	// message.
+	maxDiscordContent = 2000
+
+	// maxSlackText is the length above which the text of a Slack message
+	// is truncated.
+	maxSlackText = 3000
+)
+
+// messageTemplates render the text of the chat messages announcing a new
+// crash, in the markdown flavour of each chat service.
+var messageTemplates = map[string]*template.Template{
+	config.WebhookFormatSlack: template.Must(template.New("slack").Parse(
+		":rotating_light: *New {{.Class}} crash* (priority " +
+			"{{.Priority}}) in `{{.Package}}/{{.Target}}`\n" +
+			"*Summary:* {{.Summary}}\n" +
+			"*Signature:* `{{.Signature}}`\n" +
+			"{{if .Commit}}*Commit:* `{{.Commit}}`\n{{end}}" +
+			"{{with .Verification}}*Reproducibility:* " +
+			"{{.Label}} ({{.Failures}}/{{.Runs}} runs " +
+			"failed)\n{{end}}" +
+			"{{with .Bisection}}{{if .FirstBad}}*Introduced " +
+			"by:* `{{.FirstBad}}`\n{{else if .Candidates}}" +
+			"*Introduced by one of:* {{len .Candidates}} " +
+			"commits after `{{.LastGood}}`\n{{end}}{{end}}" +
+			"*Stack:*\n```{{.Stack}}```\n" +
+			"{{if .Input}}*Input* `{{.InputID}}`" +
+			"{{if .InputTruncated}} (truncated){{end}}:\n" +
+			"```{{.Input}}```\n{{end}}",
+	)),
+	config.WebhookFormatDiscord: template.Must(template.New("discord").
+		Parse(
+			":rotating_light: **New {{.Class}} crash** (priority " +
+				"{{.Priority}}) in `{{.Package}}/{{.Target}}`" +
+				"\n**Summary:** {{.Summary}}\n" +
+				"**Signature:** `{{.Signature}}`\n" +
+				"{{if .Commit}}**Commit:** `{{.Commit}}`\n" +
+				"{{end}}" +
+				"{{with .Verification}}**Reproducibility:** " +
+				"{{.Label}} ({{.Failures}}/{{.Runs}} runs " +
+				"failed)\n{{end}}" +
+				"{{with .Bisection}}{{if .FirstBad}}" +
+				"**Introduced by:** `{{.FirstBad}}`\n" +
+				"{{else if .Candidates}}**Introduced by one " +
+				"of:** {{len .Candidates}} commits after " +
+				"`{{.LastGood}}`\n{{end}}{{end}}" +
+				"**Stack:**\n```{{.Stack}}```\n" +
+				"{{if .Input}}**Input** `{{.InputID}}`" +
+				"{{if .InputTruncated}} (truncated){{end}}:\n" +
+				"```{{.Input}}```\n{{end}}",
+		)),
+}
+
+// fixedTemplates render the text of the chat messages announcing a fixed
+// crash.
+var fixedTemplates = map[string]*template.Template{
+	config.WebhookFormatSlack: template.Must(template.New("slack").Parse(
+		":white_check_mark: *Fixed {{.Class}} crash* in " +
+			"`{{.Package}}/{{.Target}}`\n" +
+			"*Summary:* {{.Summary}}\n" +
+			"*Signature:* `{{.Signature}}`\n" +
+			"*No longer reproduces at:* `{{.Commit}}`\n",
+	)),
+	config.WebhookFormatDiscord: template.Must(template.New("discord").
+		Parse(
+			":white_check_mark: **Fixed {{.Class}} crash** in " +
+				"`{{.Package}}/{{.Target}}`\n" +
+				"**Summary:** {{.Summary}}\n" +
+				"**Signature:** `{{.Signature}}`\n" +
+				"**No longer reproduces at:** `{{.Commit}}`\n",
+		)),
+}
+
+// payload encodes the event in the given webhook format.
+func payload(format string, e *Event) ([]byte, error) {
+	var body any
+	switch format {
+	case config.WebhookFormatJSON:
+		body = e
+
+	case config.WebhookFormatSlack:
+		text, err := renderMessage(format, e, maxSlackText)
+		if err != nil {
+			return nil, err
+		}
+		body = map[string]string{"text": text}
+
+	case config.WebhookFormatDiscord:
+		content, err := renderMessage(format, e, maxDiscordContent)
+		if err != nil {
+			return nil, err
+		}
+		body = map[string]string{"content": content}
+
+	default:
+		return nil, fmt.Errorf("unknown webhook format %q", format)
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	return data, nil
+}
+
+// renderMessage renders the chat message of the event, truncated to maxLen
+// characters.
+func renderMessage(format string, e *Event, maxLen int) (string, error) {
+	tmpl := messageTemplates[format]
+	if e.State == crash.StateFixed {
+		tmpl = fixedTemplates[format]
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, e); err != nil {
+		return "", fmt.Errorf("failed to render message: %w", err)
+	}
+
+	text := []rune(b.String())
+	if len(text) <= maxLen {
+		return string(text), nil
+	}
+
+	// Keep room for the ellipsis and for closing the code block left
+	// open by the truncation, if any.
+	const ellipsis, fence = "\n…", "```"
+	truncated := string(text[:maxLen-5]) + ellipsis
+	if strings.Count(truncated, fence)%2 == 1 {
+		truncated += fence
+	}
+
+	return truncated, nil
+}
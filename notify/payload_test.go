@@ -0,0 +1,124 @@
+package notify
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/NishantBansal2003/LND-Fuzz/crash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPayload verifies the body posted to each kind of webhook.
+func TestPayload(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		field  string
+		fixed  bool
+		want   []string
+	}{
+		{
+			name:   "json",
+			format: config.WebhookFormatJSON,
+			field:  "signature",
+			want:   []string{"3f2a9c1d"},
+		},
+		{
+			name:   "slack",
+			format: config.WebhookFormatSlack,
+			field:  "text",
+			want: []string{
+				"*New panic crash* (priority 1)",
+				"`foo/bar/FuzzFoo`",
+				"*Signature:* `3f2a9c1d`",
+				"*Reproducibility:* flaky (3/5 runs failed)",
+				"*Introduced by:* `9e8d7c6b`",
+				"```foo.Parse\n\tfoo/parse.go:42```",
+				"```go test fuzz v1\nstring(\"0\")\n```",
+			},
+		},
+		{
+			name:   "discord",
+			format: config.WebhookFormatDiscord,
+			field:  "content",
+			want: []string{
+				"**New panic crash** (priority 1)",
+				"**Signature:** `3f2a9c1d`",
+				"**Reproducibility:** flaky",
+				"**Introduced by:** `9e8d7c6b`",
+			},
+		},
+		{
+			name:   "json fixed",
+			format: config.WebhookFormatJSON,
+			field:  "state",
+			fixed:  true,
+			want:   []string{"fixed"},
+		},
+		{
+			name:   "slack fixed",
+			format: config.WebhookFormatSlack,
+			field:  "text",
+			fixed:  true,
+			want: []string{
+				"*Fixed panic crash* in `foo/bar/FuzzFoo`",
+				"*No longer reproduces at:* `0a1b2c3d`",
+			},
+		},
+		{
+			name:   "discord fixed",
+			format: config.WebhookFormatDiscord,
+			field:  "content",
+			fixed:  true,
+			want: []string{
+				"**Fixed panic crash** in `foo/bar/FuzzFoo`",
+				"**No longer reproduces at:** `0a1b2c3d`",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := testEvent()
+			if tt.fixed {
+				e.State = crash.StateFixed
+				e.Commit = "0a1b2c3d"
+			}
+
+			data, err := payload(tt.format, e)
+			require.NoError(t, err)
+
+			var body map[string]any
+			require.NoError(t, json.Unmarshal(data, &body))
+			require.Contains(t, body, tt.field)
+			for _, want := range tt.want {
+				assert.Contains(t, body[tt.field], want)
+			}
+		})
+	}
+
+	_, err := payload("teams", testEvent())
+	assert.Error(t, err)
+}
+
+// TestPayloadTruncation verifies that chat messages with large inputs are cut
+// to the limit of the service, leaving no code block open.
+func TestPayloadTruncation(t *testing.T) {
+	e := testEvent()
+	e.Input = strings.Repeat("é", 16*1024)
+
+	for format, limit := range map[string]int{
+		config.WebhookFormatSlack:   maxSlackText,
+		config.WebhookFormatDiscord: maxDiscordContent,
+	} {
+		text, err := renderMessage(format, e, limit)
+		require.NoError(t, err)
+
+		assert.LessOrEqual(t, utf8.RuneCountInString(text), limit)
+		assert.Zero(t, strings.Count(text, "```")%2)
+	}
+}
@@ -0,0 +1,178 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// FailureClass is the kind of failure reported by a fuzz target.
+type FailureClass string
+
+const (
+	// ClassPanic is a Go panic raised by the fuzzed code.
+	ClassPanic FailureClass = "panic"
+
+	// ClassFatalError is an unrecoverable runtime error, such as
+	// "fatal error: concurrent map writes".
+	ClassFatalError FailureClass = "fatal-error"
+
+	// ClassTimeout is a test that exceeded its deadline and was killed by
+	// the testing package ("panic: test timed out").
+	ClassTimeout FailureClass = "timeout"
+
+	// ClassOOM is a fuzzing worker that hung or was killed, most often by
+	// running out of memory.
+	ClassOOM FailureClass = "oom"
+
+	// ClassDataRace is a report of the race detector.
+	ClassDataRace FailureClass = "data-race"
+
+	// ClassAssertion is a plain t.Error or t.Fatal failure.
+	ClassAssertion FailureClass = "assertion"
+
+	// ClassHang is a fuzz target that stopped making progress and was
+	// interrupted by the stall watchdog.
+	ClassHang FailureClass = "hang"
+)
+
+var (
+	// assertionRegex matches the location and message printed for a
+	// t.Error or t.Fatal call, e.g. "foo_test.go:12: unexpected result".
+	//
+	// Captured groups:
+	//   - "file": the base name of the source file
+	//   - "msg": the failure message
+	assertionRegex = regexp.MustCompile(
+		`^(?P<file>\S+\.go):\d+: (?P<msg>.+)$`,
+	)
+)
+
+const (
+	// timeoutMarker is the panic message used by the testing package when
+	// a test exceeds its deadline.
+	timeoutMarker = "panic: test timed out"
+
+	// workerCrashMarker is printed by the fuzzing coordinator when a worker
+	// process exits without reporting a result, typically after the
+	// kernel killed it for running out of memory.
+	workerCrashMarker = "fuzzing process hung or terminated unexpectedly"
+
+	// raceMarker is printed by the testing package when the race detector
+	// reported a race during the test.
+	raceMarker = "race detected during execution of test"
+)
+
+// Priority returns the triage priority of the failure class, 1 being the most
+// urgent. Memory corruption and crashes of the fuzzed code come first,
+// resource exhaustion next, and failed assertions last.
+func (c FailureClass) Priority() int {
+	switch c {
+	case ClassDataRace, ClassFatalError, ClassPanic:
+		return 1
+	case ClassTimeout, ClassOOM, ClassHang:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// Description returns a one line description of the failure class.
+func (c FailureClass) Description() string {
+	switch c {
+	case ClassPanic:
+		return "The fuzzed code panicked."
+	case ClassFatalError:
+		return "The Go runtime aborted with an unrecoverable error."
+	case ClassTimeout:
+		return "The fuzz target exceeded the test deadline."
+	case ClassOOM:
+		return "A fuzzing worker hung or was killed, most often for " +
+			"running out of memory."
+	case ClassDataRace:
+		return "The race detector reported a data race."
+	case ClassAssertion:
+		return "The fuzz target reported a failure with t.Error or " +
+			"t.Fatal."
+	case ClassHang:
+		return "The fuzz target stopped making progress."
+	default:
+		return "The fuzz target failed."
+	}
+}
+
+// ClassifyFailure determines the class of a failure from the fuzzer output
+// lines following the "--- FAIL:" marker.
+func ClassifyFailure(lines []string) FailureClass {
+	var seenPanic, seenFatal, seenTimeout, seenWorkerCrash bool
+	for _, raw := range lines {
+		line := strings.TrimSpace(raw)
+
+		switch {
+		// A race report takes precedence, as it usually comes with
+		// other failure output caused by the racing accesses.
+		case line == raceHeader, strings.Contains(line, raceMarker):
+			return ClassDataRace
+
+		case strings.Contains(line, timeoutMarker):
+			seenTimeout = true
+
+		case strings.Contains(line, workerCrashMarker):
+			seenWorkerCrash = true
+
+		default:
+			kind, _ := parsePanicLine(line)
+			seenFatal = seenFatal || kind == "fatal error"
+			seenPanic = seenPanic || kind == "panic"
+		}
+	}
+
+	switch {
+	case seenTimeout:
+		return ClassTimeout
+	case seenFatal:
+		return ClassFatalError
+	case seenPanic:
+		return ClassPanic
+	case seenWorkerCrash:
+		return ClassOOM
+	default:
+		return ClassAssertion
+	}
+}
+
+// FailureSummary returns a one line description of the failure, with values
+// that vary between runs replaced by placeholders. It is used to identify
+// failures that carry no stack trace.
+func FailureSummary(class FailureClass, st *StackTrace,
+	lines []string) string {
+
+	// A hang is identified by where its goroutines were blocked, its
+	// summary does not depend on the dump.
+	if class == ClassHang {
+		return "no fuzzing progress"
+	}
+
+	if st != nil {
+		return st.PanicType()
+	}
+
+	for _, raw := range lines {
+		line := strings.TrimSpace(raw)
+
+		if class == ClassOOM && strings.Contains(line,
+			workerCrashMarker) {
+
+			return normalizeMessage(line)
+		}
+
+		m := assertionRegex.FindStringSubmatch(line)
+		if class == ClassAssertion && m != nil {
+			return m[assertionRegex.SubexpIndex("file")] + ": " +
+				normalizeMessage(
+					m[assertionRegex.SubexpIndex("msg")],
+				)
+		}
+	}
+
+	return string(class)
+}
@@ -0,0 +1,143 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestClassifyFailure verifies that ClassifyFailure recognizes each kind of
+// failure reported by "go test -fuzz".
+func TestClassifyFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected FailureClass
+	}{
+		{
+			name: "panic",
+			output: "--- FAIL: FuzzFoo (0.00s)\n" +
+				"    testing.go:1591: panic: boom " +
+				"[recovered]\n" +
+				"        goroutine 34 [running]:",
+			expected: ClassPanic,
+		},
+		{
+			name: "fatal error",
+			output: "fatal error: concurrent map writes\n\n" +
+				"goroutine 7 [running]:",
+			expected: ClassFatalError,
+		},
+		{
+			name: "test timeout",
+			output: "panic: test timed out after 10m0s\n" +
+				"running tests:\n" +
+				"\tFuzzFoo (10m0s)",
+			expected: ClassTimeout,
+		},
+		{
+			name: "worker killed",
+			output: "--- FAIL: FuzzFoo (12.34s)\n" +
+				"    fuzzing process hung or terminated " +
+				"unexpectedly: exit status 2",
+			expected: ClassOOM,
+		},
+		{
+			name: "data race",
+			output: "==================\n" +
+				"WARNING: DATA RACE\n" +
+				"Write at 0x00c00001c0f8 by goroutine 7:\n" +
+				"--- FAIL: FuzzFoo (0.01s)\n" +
+				"    testing.go:1465: race detected during " +
+				"execution of test",
+			expected: ClassDataRace,
+		},
+		{
+			name: "assertion",
+			output: "--- FAIL: FuzzFoo (0.00s)\n" +
+				"    foo_test.go:12: unexpected result 3",
+			expected: ClassAssertion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := strings.Split(tt.output, "\n")
+			assert.Equal(t, tt.expected, ClassifyFailure(lines))
+		})
+	}
+}
+
+// TestFailureSummary verifies that failures without a stack trace are
+// summarized from their failure message, without varying values.
+func TestFailureSummary(t *testing.T) {
+	tests := []struct {
+		name     string
+		class    FailureClass
+		output   string
+		expected string
+	}{
+		{
+			name:  "assertion",
+			class: ClassAssertion,
+			output: "--- FAIL: FuzzFoo (0.00s)\n" +
+				"    foo_test.go:12: unexpected result 3",
+			expected: "foo_test.go: unexpected result N",
+		},
+		{
+			name:  "worker killed",
+			class: ClassOOM,
+			output: "--- FAIL: FuzzFoo (12.34s)\n" +
+				"    fuzzing process hung or terminated " +
+				"unexpectedly: exit status 2",
+			expected: "fuzzing process hung or terminated " +
+				"unexpectedly: exit status N",
+		},
+		{
+			name:     "nothing recognizable",
+			class:    ClassAssertion,
+			output:   "--- FAIL: FuzzFoo (0.00s)",
+			expected: "assertion",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := strings.Split(tt.output, "\n")
+			assert.Equal(t, tt.expected, FailureSummary(tt.class,
+				nil, lines))
+		})
+	}
+}
+
+// TestParseRaceReport verifies that race detector reports are parsed into the
+// stacks of the racing accesses.
+func TestParseRaceReport(t *testing.T) {
+	output := `==================
+WARNING: DATA RACE
+Write at 0x00c00001c0f8 by goroutine 8:
+  github.com/owner/repo/foo.(*Cache).Put()
+      /home/user/repo/foo/cache.go:21 +0x44
+
+Previous read at 0x00c00001c0f8 by goroutine 7:
+  github.com/owner/repo/foo.(*Cache).Get()
+      /home/user/repo/foo/cache.go:14 +0x3a
+
+Goroutine 8 (running) created at:
+  github.com/owner/repo/foo.FuzzCache.func1()
+      /home/user/repo/foo/cache_test.go:9 +0x1c4
+==================`
+
+	st := ParseStackTrace(strings.Split(output, "\n"))
+	require.NotNil(t, st)
+
+	assert.Equal(t, "data race", st.PanicType())
+	require.Len(t, st.Goroutines, 3)
+	assert.Equal(t, []Frame{{
+		Function: "github.com/owner/repo/foo.(*Cache).Put",
+		File:     "/home/user/repo/foo/cache.go",
+		Line:     21,
+	}}, st.ProjectFrames(SignatureFrameCount))
+}
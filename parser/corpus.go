@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// CorpusValue is a single value of a fuzz corpus file.
+type CorpusValue struct {
+	// Type is the Go type of the value, e.g. "[]byte" or "int64".
+	Type string
+
+	// Literal is the value as written in the corpus file.
+	Literal string
+
+	// Data holds the decoded contents of string and []byte values. It is
+	// nil for other types.
+	Data []byte
+}
+
+// DecodeCorpusFile parses the contents of a corpus file in the
+// "go test fuzz v1" format into its values.
+func DecodeCorpusFile(data []byte) ([]CorpusValue, error) {
+	lines := strings.Split(string(data), "\n")
+	if strings.TrimSpace(lines[0]) != corpusFileHeader {
+		return nil, errors.New("missing corpus file header")
+	}
+
+	var values []CorpusValue
+	for i, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		value, err := decodeCorpusValue(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+2, err)
+		}
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+// decodeCorpusValue parses a single "type(literal)" line of a corpus file.
+func decodeCorpusValue(line string) (CorpusValue, error) {
+	expr, err := parser.ParseExpr(line)
+	if err != nil {
+		return CorpusValue{}, fmt.Errorf("invalid value: %w", err)
+	}
+
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return CorpusValue{}, fmt.Errorf("invalid value %q", line)
+	}
+
+	value := CorpusValue{
+		Type:    exprString(call.Fun),
+		Literal: exprString(call.Args[0]),
+	}
+
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	isBytes := value.Type == "string" || value.Type == "[]byte"
+	if isBytes && ok && lit.Kind == token.STRING {
+		s, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			return CorpusValue{}, fmt.Errorf("invalid string "+
+				"%s: %w", lit.Value, err)
+		}
+		value.Data = []byte(s)
+	}
+
+	return value, nil
+}
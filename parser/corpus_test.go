@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDecodeCorpusFile verifies that the values of corpus files are decoded,
+// and that malformed files are rejected.
+func TestDecodeCorpusFile(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		expected  []CorpusValue
+		expectErr string
+	}{
+		{
+			name: "mixed values",
+			data: "go test fuzz v1\n[]byte(\"\\x00ab\")\n" +
+				"string(\"héllo\\n\")\nint64(-3)\n" +
+				"bool(true)\n",
+			expected: []CorpusValue{
+				{
+					Type:    "[]byte",
+					Literal: `"\x00ab"`,
+					Data:    []byte("\x00ab"),
+				},
+				{
+					Type:    "string",
+					Literal: `"héllo\n"`,
+					Data:    []byte("héllo\n"),
+				},
+				{
+					Type:    "int64",
+					Literal: "-3",
+				},
+				{
+					Type:    "bool",
+					Literal: "true",
+				},
+			},
+		},
+		{
+			name: "raw string",
+			data: "go test fuzz v1\nstring(`a\"b`)",
+			expected: []CorpusValue{
+				{
+					Type:    "string",
+					Literal: "`a\"b`",
+					Data:    []byte(`a"b`),
+				},
+			},
+		},
+		{
+			name:      "missing header",
+			data:      "string(\"0\")\n",
+			expectErr: "missing corpus file header",
+		},
+		{
+			name:      "invalid value",
+			data:      "go test fuzz v1\nstring(\"0\")\n0x12\n",
+			expectErr: "line 3: invalid value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := DecodeCorpusFile([]byte(tt.data))
+			if tt.expectErr != "" {
+				assert.ErrorContains(t, err, tt.expectErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, values)
+		})
+	}
+}
@@ -1,8 +1,16 @@
 package parser
 
 import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
 )
 
 // LogWriter defines the interface for writing fuzz failure logs to any sink.
@@ -22,6 +30,41 @@ type LogWriter interface {
 	// Close writes the trailing formatted contents of the failing testcase
 	// and closes the underlying file.
 	Close(errorData string) error
+
+	// LogPath returns the path of the file written by the sink, or an
+	// empty string if the sink does not write to a file.
+	LogPath() string
+}
+
+// NewLogWriter returns the LogWriter for the given sink names. A single sink is
+// returned as is, several sinks are combined into a MultiLogWriter. With no
+// sinks, failure logs are written to plain text files.
+func NewLogWriter(sinks []string) (LogWriter, error) {
+	if len(sinks) == 0 {
+		return &FileLogWriter{}, nil
+	}
+
+	writers := make([]LogWriter, 0, len(sinks))
+	for _, sink := range sinks {
+		switch sink {
+		case config.LogSinkFile:
+			writers = append(writers, &FileLogWriter{})
+		case config.LogSinkJSON:
+			writers = append(writers, &JSONLogWriter{})
+		case config.LogSinkGzip:
+			writers = append(writers, &GzipLogWriter{})
+		case config.LogSinkMemory:
+			writers = append(writers, &MemoryLogWriter{})
+		default:
+			return nil, fmt.Errorf("unknown log sink %q", sink)
+		}
+	}
+
+	if len(writers) == 1 {
+		return writers[0], nil
+	}
+
+	return NewMultiLogWriter(writers...), nil
 }
 
 // FileLogWriter is a LogWriter that writes failure logs into a file.
@@ -72,3 +115,316 @@ func (fl *FileLogWriter) Close(errorData string) error {
 
 	return fl.file.Close()
 }
+
+// LogPath returns the path of the log file, or an empty string if it has not
+// been initialized.
+func (fl *FileLogWriter) LogPath() string {
+	if fl.file == nil {
+		return ""
+	}
+
+	return fl.file.Name()
+}
+
+// jsonLogRecord is a single entry of a JSON lines failure log.
+type jsonLogRecord struct {
+	// Time is the time the entry was written.
+	Time time.Time `json:"time"`
+
+	// Kind is either "line" for fuzzer output or "error_data" for the
+	// formatted contents of the failing testcase.
+	Kind string `json:"kind"`
+
+	// Text is the logged content.
+	Text string `json:"text"`
+}
+
+// JSONLogWriter is a LogWriter that writes failure logs as JSON lines, one
+// object per fuzzer output line. The file is named after the log path, with
+// its ".log" extension replaced by ".jsonl".
+type JSONLogWriter struct {
+	file    *os.File
+	encoder *json.Encoder
+}
+
+// Initialize creates (or truncates, if it already exists) the JSON lines file
+// derived from the specified path.
+func (jl *JSONLogWriter) Initialize(logPath string) error {
+	jsonPath := strings.TrimSuffix(logPath, ".log") + ".jsonl"
+	logFile, err := os.Create(jsonPath)
+	if err != nil {
+		return fmt.Errorf("failed to create JSON log file: %w", err)
+	}
+
+	jl.file = logFile
+	jl.encoder = json.NewEncoder(logFile)
+	return nil
+}
+
+// WriteLine writes a single line of fuzzer output as a JSON object.
+func (jl *JSONLogWriter) WriteLine(line string) error {
+	return jl.write("line", line)
+}
+
+// WriteErrorData writes the formatted contents of the failing testcase as a
+// JSON object.
+func (jl *JSONLogWriter) WriteErrorData(data string) error {
+	return jl.write("error_data", data)
+}
+
+// write encodes a single log entry.
+func (jl *JSONLogWriter) write(kind, text string) error {
+	if jl.encoder == nil {
+		return errors.New("JSON log file not initialized")
+	}
+
+	err := jl.encoder.Encode(jsonLogRecord{
+		Time: time.Now().UTC(),
+		Kind: kind,
+		Text: text,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write JSON log file: %w", err)
+	}
+
+	return nil
+}
+
+// Close writes the trailing formatted contents of the failing testcase and
+// closes the underlying file.
+func (jl *JSONLogWriter) Close(errorData string) error {
+	if err := jl.WriteErrorData(errorData); err != nil {
+		return fmt.Errorf("error data write failed: %w", err)
+	}
+
+	return jl.file.Close()
+}
+
+// LogPath returns the path of the JSON lines file, or an empty string if it
+// has not been initialized.
+func (jl *JSONLogWriter) LogPath() string {
+	if jl.file == nil {
+		return ""
+	}
+
+	return jl.file.Name()
+}
+
+// GzipLogWriter is a LogWriter that writes failure logs into a gzip-compressed
+// text file, named after the log path with a ".gz" suffix.
+type GzipLogWriter struct {
+	file *os.File
+	gz   *gzip.Writer
+}
+
+// Initialize creates (or truncates, if it already exists) the compressed log
+// file derived from the specified path.
+func (gl *GzipLogWriter) Initialize(logPath string) error {
+	logFile, err := os.Create(logPath + ".gz")
+	if err != nil {
+		return fmt.Errorf("failed to create gzip log file: %w", err)
+	}
+
+	gl.file = logFile
+	gl.gz = gzip.NewWriter(logFile)
+	return nil
+}
+
+// WriteLine writes a single line of fuzzer output (followed by a newline) to
+// the compressed stream.
+func (gl *GzipLogWriter) WriteLine(line string) error {
+	return gl.write(line)
+}
+
+// WriteErrorData writes the formatted contents of the failing testcase (plus
+// newline) to the compressed stream.
+func (gl *GzipLogWriter) WriteErrorData(data string) error {
+	return gl.write(data)
+}
+
+// write appends the text and a newline to the compressed stream.
+func (gl *GzipLogWriter) write(text string) error {
+	if gl.gz == nil {
+		return errors.New("gzip log file not initialized")
+	}
+
+	if _, err := gl.gz.Write([]byte(text + "\n")); err != nil {
+		return fmt.Errorf("failed to write gzip log file: %w", err)
+	}
+
+	return nil
+}
+
+// Close writes the trailing formatted contents of the failing testcase, then
+// flushes the compressed stream and closes the underlying file.
+func (gl *GzipLogWriter) Close(errorData string) error {
+	if err := gl.WriteErrorData(errorData); err != nil {
+		return fmt.Errorf("error data write failed: %w", err)
+	}
+
+	if err := gl.gz.Close(); err != nil {
+		return fmt.Errorf("failed to flush gzip log file: %w", err)
+	}
+
+	return gl.file.Close()
+}
+
+// LogPath returns the path of the compressed log file, or an empty string if it
+// has not been initialized.
+func (gl *GzipLogWriter) LogPath() string {
+	if gl.file == nil {
+		return ""
+	}
+
+	return gl.file.Name()
+}
+
+// MemoryLogWriter is a LogWriter that keeps failure logs in memory. It is safe
+// for concurrent use, which makes it convenient for inspecting failure output
+// in tests.
+type MemoryLogWriter struct {
+	mu sync.Mutex
+
+	path      string
+	lines     []string
+	errorData []string
+	closed    bool
+}
+
+// Initialize resets the in-memory log. The path is only remembered.
+func (ml *MemoryLogWriter) Initialize(logPath string) error {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+
+	ml.path = logPath
+	ml.lines = nil
+	ml.errorData = nil
+	ml.closed = false
+	return nil
+}
+
+// WriteLine records a single line of fuzzer output.
+func (ml *MemoryLogWriter) WriteLine(line string) error {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+
+	ml.lines = append(ml.lines, line)
+	return nil
+}
+
+// WriteErrorData records the formatted contents of the failing testcase.
+func (ml *MemoryLogWriter) WriteErrorData(data string) error {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+
+	ml.errorData = append(ml.errorData, data)
+	return nil
+}
+
+// Close records the trailing formatted contents of the failing testcase and
+// marks the log as closed.
+func (ml *MemoryLogWriter) Close(errorData string) error {
+	if err := ml.WriteErrorData(errorData); err != nil {
+		return fmt.Errorf("error data write failed: %w", err)
+	}
+
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+
+	ml.closed = true
+	return nil
+}
+
+// LogPath returns an empty string, as nothing is written to a file.
+func (ml *MemoryLogWriter) LogPath() string {
+	return ""
+}
+
+// Lines returns a copy of the fuzzer output lines recorded so far.
+func (ml *MemoryLogWriter) Lines() []string {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+
+	return append([]string(nil), ml.lines...)
+}
+
+// ErrorData returns a copy of the error data recorded so far.
+func (ml *MemoryLogWriter) ErrorData() []string {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+
+	return append([]string(nil), ml.errorData...)
+}
+
+// Closed reports whether the log has been closed.
+func (ml *MemoryLogWriter) Closed() bool {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+
+	return ml.closed
+}
+
+// MultiLogWriter is a LogWriter that fans out every call to several sinks. A
+// failing sink does not prevent the others from being written; the errors of
+// all sinks are joined.
+type MultiLogWriter struct {
+	writers []LogWriter
+}
+
+// NewMultiLogWriter returns a LogWriter that writes to all of the given sinks.
+func NewMultiLogWriter(writers ...LogWriter) *MultiLogWriter {
+	return &MultiLogWriter{writers: writers}
+}
+
+// Initialize initializes every sink with the specified path.
+func (mw *MultiLogWriter) Initialize(logPath string) error {
+	return mw.each(func(w LogWriter) error {
+		return w.Initialize(logPath)
+	})
+}
+
+// WriteLine writes a single line of fuzzer output to every sink.
+func (mw *MultiLogWriter) WriteLine(line string) error {
+	return mw.each(func(w LogWriter) error {
+		return w.WriteLine(line)
+	})
+}
+
+// WriteErrorData writes the formatted contents of the failing testcase to
+// every sink.
+func (mw *MultiLogWriter) WriteErrorData(data string) error {
+	return mw.each(func(w LogWriter) error {
+		return w.WriteErrorData(data)
+	})
+}
+
+// Close writes the trailing formatted contents of the failing testcase to and
+// closes every sink.
+func (mw *MultiLogWriter) Close(errorData string) error {
+	return mw.each(func(w LogWriter) error {
+		return w.Close(errorData)
+	})
+}
+
+// LogPath returns the path of the first sink that writes to a file.
+func (mw *MultiLogWriter) LogPath() string {
+	for _, w := range mw.writers {
+		if path := w.LogPath(); path != "" {
+			return path
+		}
+	}
+
+	return ""
+}
+
+// each calls fn for every sink and joins the returned errors.
+func (mw *MultiLogWriter) each(fn func(w LogWriter) error) error {
+	var errs []error
+	for _, w := range mw.writers {
+		if err := fn(w); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,140 @@
+package parser
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewLogWriter verifies that the configured sink names select the
+// matching LogWriter implementations.
+func TestNewLogWriter(t *testing.T) {
+	tests := []struct {
+		name      string
+		sinks     []string
+		expected  LogWriter
+		expectErr bool
+	}{
+		{
+			name:     "default sink",
+			expected: &FileLogWriter{},
+		},
+		{
+			name:     "single sink",
+			sinks:    []string{"jsonl"},
+			expected: &JSONLogWriter{},
+		},
+		{
+			name:  "several sinks",
+			sinks: []string{"file", "gzip"},
+			expected: NewMultiLogWriter(&FileLogWriter{},
+				&GzipLogWriter{}),
+		},
+		{
+			name:      "unknown sink",
+			sinks:     []string{"syslog"},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer, err := NewLogWriter(tt.sinks)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, writer)
+		})
+	}
+}
+
+// TestMultiLogWriter verifies that every call is fanned out to all sinks and
+// that each file based sink produces a readable log.
+func TestMultiLogWriter(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "FuzzFoo_failure.log")
+	memory := &MemoryLogWriter{}
+	writer := NewMultiLogWriter(&FileLogWriter{}, &JSONLogWriter{},
+		&GzipLogWriter{}, memory)
+
+	require.NoError(t, writer.Initialize(logPath))
+	require.NoError(t, writer.WriteLine("--- FAIL: FuzzFoo (0.00s)"))
+	require.NoError(t, writer.WriteLine("    foo_test.go:12: boom"))
+	require.NoError(t, writer.Close("=== Failing testcase ==="))
+
+	assert.Equal(t, logPath, writer.LogPath())
+
+	// In-memory sink.
+	assert.True(t, memory.Closed())
+	assert.Equal(t, []string{
+		"--- FAIL: FuzzFoo (0.00s)",
+		"    foo_test.go:12: boom",
+	}, memory.Lines())
+	assert.Equal(t, []string{"=== Failing testcase ==="},
+		memory.ErrorData())
+
+	expected := "--- FAIL: FuzzFoo (0.00s)\n" +
+		"    foo_test.go:12: boom\n" +
+		"=== Failing testcase ===\n"
+
+	// Plain text sink.
+	data, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(data))
+
+	// Gzip sink.
+	gzFile, err := os.Open(logPath + ".gz")
+	require.NoError(t, err)
+	defer gzFile.Close()
+
+	gz, err := gzip.NewReader(gzFile)
+	require.NoError(t, err)
+	data, err = io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(data))
+
+	// JSON lines sink.
+	jsonFile, err := os.Open(filepath.Join(filepath.Dir(logPath),
+		"FuzzFoo_failure.jsonl"))
+	require.NoError(t, err)
+	defer jsonFile.Close()
+
+	var records []jsonLogRecord
+	scanner := bufio.NewScanner(jsonFile)
+	for scanner.Scan() {
+		var record jsonLogRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	require.NoError(t, scanner.Err())
+
+	require.Len(t, records, 3)
+	assert.Equal(t, "line", records[0].Kind)
+	assert.Equal(t, "    foo_test.go:12: boom", records[1].Text)
+	assert.Equal(t, "error_data", records[2].Kind)
+}
+
+// TestMultiLogWriterErrors verifies that a failing sink does not prevent the
+// other sinks from being written.
+func TestMultiLogWriterErrors(t *testing.T) {
+	memory := &MemoryLogWriter{}
+	writer := NewMultiLogWriter(&FileLogWriter{}, memory)
+
+	// The file sink cannot create a file in a missing directory.
+	err := writer.Initialize(filepath.Join(t.TempDir(), "missing",
+		"FuzzFoo_failure.log"))
+	assert.Error(t, err)
+
+	// Writing keeps failing for the file sink only.
+	assert.Error(t, writer.WriteLine("still written"))
+	assert.Equal(t, []string{"still written"}, memory.Lines())
+}
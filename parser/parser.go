@@ -2,6 +2,8 @@ package parser
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -9,8 +11,28 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/NishantBansal2003/LND-Fuzz/crash"
+	"github.com/NishantBansal2003/LND-Fuzz/metrics"
+	"github.com/NishantBansal2003/LND-Fuzz/notify"
+)
+
+// MaxLoggedLineLength is the maximum number of bytes of a fuzzer output line
+// echoed to the application log. Longer lines, such as large dumps of failing
+// inputs, are still processed and written to the failure log in full.
+const MaxLoggedLineLength = 4096
+
+const (
+	// notifiedStackLines is the number of stack frames, or of failure
+	// output lines if there is no stack trace, included in notifications.
+	notifiedStackLines = 20
+
+	// maxNotifiedInputSize is the number of bytes of the failing input
+	// included in notifications.
+	maxNotifiedInputSize = 16 * 1024
 )
 
 var (
@@ -46,15 +68,57 @@ type FuzzProcessor struct {
 	// stored.
 	corpusPath string
 
+	// Name of the package containing the fuzz target.
+	pkg string
+
 	// Name of the fuzz target being processed.
 	target string
 
+	// Hash of the project commit being fuzzed, empty if unknown.
+	commit string
+
+	// Version of the Go toolchain fuzzing the target, empty if unknown.
+	goVersion string
+
+	// Module path of the fuzzed project, read from its go.mod file, empty
+	// if unknown.
+	module string
+
+	// Path of the failure log file written by the log writer, set once a
+	// failure is detected. It is empty if no sink writes to a file.
+	logPath string
+
+	// Crash database used to deduplicate failures by signature.
+	crashStore *crash.Store
+
+	// Notifier announcing recorded crashes. It may be nil.
+	notifier *notify.Notifier
+
+	// Crash recorded while processing the stream and not reported yet, see
+	// ReportCrash.
+	finding *crash.Finding
+
 	// Interface responsible for writing logs to the desired output.
 	logWriter LogWriter
 
 	// Tracks the state of the processing, including whether a failure was
 	// detected.
 	State *ProcessState
+
+	// Time, in Unix nanoseconds, the fuzzer last made progress, or zero
+	// until fuzzing has started. It is read by the stall watchdog while
+	// the stream is processed.
+	lastProgress atomic.Int64
+
+	// Whether the stall watchdog interrupted the fuzz target. Once set,
+	// the remaining output, i.e. the goroutine dump, is handled as the
+	// failure output of a hang.
+	stalled atomic.Bool
+
+	// Execution count and baseline coverage progress of the previous
+	// progress lines, used to tell whether the fuzzer is still advancing.
+	lastExecs    int64
+	lastBaseline int64
 }
 
 // ProcessState holds mutable state information while processing fuzzer output.
@@ -68,47 +132,168 @@ type ProcessState struct {
 	// InputPrinted indicates whether the failing input has already been
 	// captured.
 	InputPrinted bool
+
+	// FailureLines holds every line of output seen since the failure
+	// marker, used to parse the crash stack trace.
+	FailureLines []string
+
+	// StackTrace is the parsed panic report of the failure, or nil if the
+	// failure did not produce one.
+	StackTrace *StackTrace
+
+	// Class is the kind of failure, set once the stream has ended.
+	Class FailureClass
+
+	// Summary is a normalized one line description of the failure.
+	Summary string
+
+	// Signature identifies the crash independently of the fuzz target and
+	// input that triggered it. It is empty if no failure was seen.
+	Signature string
+
+	// FailingInputID is the name of the failing input file in the corpus,
+	// if one was written.
+	FailingInputID string
+
+	// SeedLocation is the "file:line" location of the f.Add call behind a
+	// failing seed entry. It is empty for inputs found by the fuzzer.
+	SeedLocation string
+
+	// NewFinding indicates whether the crash signature was seen for the
+	// first time, i.e. whether the failure is a new bug.
+	NewFinding bool
+
+	// Verification is the outcome of re-running the failing input of a
+	// new crash, set by ReportCrash. It is nil if the crash was not
+	// verified.
+	Verification *crash.Verification
+
+	// Stats holds the progress reports of the fuzzer, oldest first.
+	Stats []StatsSample
+
+	// Stalled indicates that the fuzz target made no progress for too long
+	// and was interrupted by the stall watchdog.
+	Stalled bool
+}
+
+// FailureExcerpt returns up to n frames of the stack trace of the failure or,
+// if it has none, its first n lines of output.
+func (s *ProcessState) FailureExcerpt(n int) string {
+	if s.StackTrace != nil {
+		return s.StackTrace.Excerpt(n)
+	}
+
+	lines := s.FailureLines[:min(len(s.FailureLines), n)]
+	return strings.Join(lines, "\n")
 }
 
 // NewFuzzProcessor constructs a FuzzProcessor for the given logger, config,
-// corpus path, and fuzz target name.
+// corpus path, package, fuzz target name, fuzzed project commit and Go
+// toolchain version, the latter two being empty if unknown. Crashes are
+// announced through the notifier, which may be nil.
 func NewFuzzProcessor(logger *slog.Logger, cfg *config.Config,
-	corpusPath string, target string) *FuzzProcessor {
+	corpusPath string, pkg string, target string, commit string,
+	goVersion string, notifier *notify.Notifier) *FuzzProcessor {
+
+	// The configuration is validated when loaded, so an unknown sink can
+	// only come from a hand-built config.
+	logWriter, err := NewLogWriter(cfg.LogSinks)
+	if err != nil {
+		logger.Error("Invalid failure log sinks; using plain text file",
+			"error", err)
+		logWriter = &FileLogWriter{}
+	}
 
 	return &FuzzProcessor{
 		logger:     logger,
 		cfg:        cfg,
 		corpusPath: corpusPath,
+		pkg:        pkg,
 		target:     target,
+		commit:     commit,
+		goVersion:  goVersion,
+		module:     modulePath(config.DefaultProjectDir),
 		State:      &ProcessState{},
-		logWriter:  &FileLogWriter{},
+		logWriter:  logWriter,
+		crashStore: crash.NewStore(cfg),
+		notifier:   notifier,
 	}
 }
 
 // ProcessStream reads each line from the fuzzing output, processes it (logging
 // every line and capturing any failure details), and when complete closes the
-// log writer, flushing any accumulated error data.
-func (fp *FuzzProcessor) ProcessStream(stream io.Reader) {
-	scanner := bufio.NewScanner(stream)
+// log writer, flushing any accumulated error data. Lines of any length are
+// supported. If reading the stream fails, the rest of it is discarded so the
+// fuzzing process is not blocked, and the read error is returned once the
+// failure data gathered so far has been recorded.
+func (fp *FuzzProcessor) ProcessStream(stream io.Reader) error {
+	reader := bufio.NewReader(stream)
 
 	// Iterate over each line in the output stream.
-	for scanner.Scan() {
-		// Process the current line to detect any errors or failures.
-		// If an error occurs during processing, log it using the
-		// provided logWriter.
-		if err := fp.processLine(scanner.Text()); err != nil {
-			fp.logger.Error("Error processing line", "error", err)
+	var readErr error
+	for {
+		line, err := reader.ReadString('\n')
+
+		// A final line without a trailing newline is returned along
+		// with io.EOF.
+		if line != "" {
+			line = strings.TrimSuffix(line, "\n")
+			line = strings.TrimSuffix(line, "\r")
+
+			// Process the current line to detect any errors or
+			// failures. If an error occurs during processing, log
+			// it using the provided logWriter.
+			if err := fp.processLine(line); err != nil {
+				fp.logger.Error("Error processing line",
+					"error", err)
+			}
 		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			readErr = fmt.Errorf("failed to read fuzzer output: %w",
+				err)
+			_, _ = io.Copy(io.Discard, stream)
+			break
+		}
+	}
+
+	// A stalled fuzz target may be killed before printing anything else,
+	// the hang is still recorded.
+	if fp.stalled.Load() && !fp.State.SeenFailure {
+		if err := fp.startFailure(true); err != nil {
+			fp.logger.Error("Error recording stall", "error", err)
+		}
+	}
+
+	// Classify the failure and derive its crash signature from the
+	// collected failure output.
+	if fp.State.SeenFailure {
+		fp.analyzeFailure()
 	}
 
 	// Ensure we flush and close the log writer with the final error data.
-	defer func() { _ = fp.logWriter.Close(fp.State.ErrorData) }()
+	_ = fp.logWriter.Close(fp.State.ErrorData)
+
+	// Record the crash once its failure log is complete.
+	if fp.State.Signature != "" {
+		if err := fp.recordCrash(); err != nil {
+			fp.logger.Error("Failed to record crash", "error", err)
+		}
+	}
+
+	return readErr
 }
 
 // processLine handles one line of fuzz output: it logs it, checks for failure
 // markers, and if in failure mode, writes lines and captures failing input.
 func (fp *FuzzProcessor) processLine(line string) error {
-	fp.logger.Info("Fuzzer output", "message", line)
+	fp.logger.Info("Fuzzer output", "message", truncateLine(line))
+
+	// Keep track of the fuzzing progress.
+	fp.trackProgress(line)
 
 	// If a failure has not yet been detected, check if this line indicates
 	// a failure.
@@ -131,38 +316,124 @@ func (fp *FuzzProcessor) processLine(line string) error {
 	return nil
 }
 
-// handleFailureDetection looks for the first "--- FAIL:" marker. When found,
-// it initializes the failure log file for subsequent lines.
+// trackProgress records the progress reports of the fuzzer, exports the latest
+// execution rate and corpus size as metrics, and notes when the fuzzer last
+// advanced: when the number of executions or of corpus entries run to
+// gather baseline coverage grew. The first progress line marks the start of
+// fuzzing, the build and test setup that precede it are not watched.
+func (fp *FuzzProcessor) trackProgress(line string) {
+	started := fp.lastProgress.Load() != 0
+	advanced := false
+
+	if sample, ok := parseStatsLine(line); ok {
+		fp.State.Stats = append(fp.State.Stats, sample)
+		metrics.TargetExecsPerSecond.WithLabelValues(fp.pkg,
+			fp.target).Set(float64(sample.ExecsPerSec))
+		metrics.TargetCorpusSize.WithLabelValues(fp.pkg,
+			fp.target).Set(float64(sample.CorpusSize))
+
+		advanced = !started || sample.Execs > fp.lastExecs
+		fp.lastExecs = sample.Execs
+	}
+	if done, ok := parseBaselineLine(line); ok {
+		advanced = !started || done > fp.lastBaseline
+		fp.lastBaseline = done
+	}
+
+	if advanced {
+		fp.lastProgress.Store(time.Now().UnixNano())
+	}
+}
+
+// LastProgress returns the time the fuzzer last made progress. It returns the
+// zero time if fuzzing has not started yet. It is safe to call while the
+// stream is being processed.
+func (fp *FuzzProcessor) LastProgress() time.Time {
+	nanos := fp.lastProgress.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, nanos)
+}
+
+// MarkStalled notes that the fuzz target was interrupted for making no
+// progress, so that the output that follows is recorded as a hang. It is safe
+// to call while the stream is being processed, and must be called before the
+// target is signalled to dump its goroutines.
+func (fp *FuzzProcessor) MarkStalled() {
+	fp.stalled.Store(true)
+}
+
+// truncateLine shortens lines longer than MaxLoggedLineLength for logging,
+// noting how many bytes were left out.
+func truncateLine(line string) string {
+	if len(line) <= MaxLoggedLineLength {
+		return line
+	}
+
+	return fmt.Sprintf("%s... (%d bytes truncated)",
+		line[:MaxLoggedLineLength], len(line)-MaxLoggedLineLength)
+}
+
+// handleFailureDetection looks for the first "--- FAIL:" marker, or for the
+// start of a report that may precede it or replace it altogether (a race
+// report, a fatal error or a test timeout). Once the fuzz target has been
+// marked as stalled, any line starts the failure. When found, it initializes
+// the failure log file for subsequent lines.
 func (fp *FuzzProcessor) handleFailureDetection(line string) error {
-	// Check if the line contains the failure marker.
-	if strings.Contains(line, "--- FAIL:") {
-		// Mark that a failure has been detected.
-		fp.State.SeenFailure = true
-
-		// Construct the log file name and path for storing failure
-		// details.
-		logFileName := fmt.Sprintf("%s_failure.log", fp.target)
-		logPath := filepath.Join(fp.cfg.FuzzResultsPath, logFileName)
-
-		// Ensure the FuzzResultsPath directory exists (creates parents
-		// as needed)
-		if err := config.EnsureDirExists(
-			fp.cfg.FuzzResultsPath); err != nil {
-			return fmt.Errorf("failed to create fuzz result file: "+
-				"%w", err)
-		}
+	// Check if the line contains a failure marker.
+	if isFailureMarker(line) {
+		return fp.startFailure(false)
+	}
+	if fp.stalled.Load() {
+		return fp.startFailure(true)
+	}
 
-		// Initialize the log writer with the constructed path.
-		if err := fp.logWriter.Initialize(logPath); err != nil {
-			return fmt.Errorf("log writer initialization failed: "+
-				"%w", err)
-		}
+	return nil
+}
+
+// startFailure marks that a failure has been detected, a hang if stalled is
+// set, and initializes the failure log file.
+func (fp *FuzzProcessor) startFailure(stalled bool) error {
+	// Mark that a failure has been detected.
+	fp.State.SeenFailure = true
+	fp.State.Stalled = stalled
+
+	// Construct the log file name and path for storing failure details.
+	logFileName := fmt.Sprintf("%s_failure.log", fp.target)
+	logPath := filepath.Join(fp.cfg.FuzzResultsPath, logFileName)
+
+	// Ensure the FuzzResultsPath directory exists (creates parents as
+	// needed)
+	if err := config.EnsureDirExists(fp.cfg.FuzzResultsPath); err != nil {
+		return fmt.Errorf("failed to create fuzz result file: %w", err)
+	}
 
-		fp.logger.Info("Failure log initialized", "path", logPath)
+	// Initialize the log writer with the constructed path.
+	if err := fp.logWriter.Initialize(logPath); err != nil {
+		return fmt.Errorf("log writer initialization failed: %w", err)
 	}
+
+	fp.logPath = fp.logWriter.LogPath()
+	fp.logger.Info("Failure log initialized", "path", logPath)
+
 	return nil
 }
 
+// isFailureMarker reports whether the line starts the failure output of a fuzz
+// target.
+func isFailureMarker(line string) bool {
+	if strings.Contains(line, "--- FAIL:") {
+		return true
+	}
+
+	line = strings.TrimSpace(line)
+	return line == raceHeader ||
+		strings.HasPrefix(line, timeoutMarker) ||
+		strings.HasPrefix(line, "fatal error:")
+}
+
 // handleFailureLine writes the line to the log, then on the first occurrence
 // of a failure-input marker extracts the testcase and reads its contents.
 func (fp *FuzzProcessor) handleFailureLine(line string) error {
@@ -171,6 +442,9 @@ func (fp *FuzzProcessor) handleFailureLine(line string) error {
 		return fmt.Errorf("failed to write log line: %w", err)
 	}
 
+	// Keep the line for stack trace parsing once the stream ends.
+	fp.State.FailureLines = append(fp.State.FailureLines, line)
+
 	// If the failing input has already been printed, no further action is
 	// needed.
 	if fp.State.InputPrinted {
@@ -179,14 +453,21 @@ func (fp *FuzzProcessor) handleFailureLine(line string) error {
 
 	// Parse the line to extract the fuzz target and ID (hex) of the failing
 	// input.
+	target, id := parseFailureLine(line)
+
 	// When a fuzz target encounters a failure during f.Add, the crash is
 	// printed, but no input is saved to testdata/fuzz.
 	//
 	// The log output typically appears as:
 	//   failure while testing seed corpus entry: FuzzFoo/seed#0
 	//
-	// As a result, no error data will be printed.
-	target, id := parseFailureLine(line)
+	// In that case, the seed entry is located in the test source and its
+	// values are written to a corpus file.
+	if seedTarget, index := parseSeedFailureLine(line); seedTarget != "" {
+		target = seedTarget
+		id = fp.handleSeedFailure(seedTarget, index)
+	}
+
 	// If either target or ID is empty, skip further processing.
 	if target == "" || id == "" {
 		return nil
@@ -196,11 +477,294 @@ func (fp *FuzzProcessor) handleFailureLine(line string) error {
 	errorData := fp.readInputData(target, id)
 
 	// Store the read input data and mark that the input has been printed.
-	fp.State.ErrorData = errorData
+	fp.State.ErrorData += errorData
+	fp.State.FailingInputID = id
 	fp.State.InputPrinted = true
 	return nil
 }
 
+// handleSeedFailure reports the location of the f.Add call behind a failing
+// seed entry in the error data and materialises the seed values into a corpus
+// file. It returns the name of that file, or an empty string if the values
+// could not be determined.
+func (fp *FuzzProcessor) handleSeedFailure(target string, index int) string {
+	// The corpus path is <pkg>/testdata/fuzz, the test files live in
+	// <pkg>.
+	pkgDir := filepath.Dir(filepath.Dir(fp.corpusPath))
+
+	entry, err := findSeedEntry(pkgDir, target, index)
+	if err != nil {
+		fp.logger.Warn("Failed to locate failing seed entry", "target",
+			target, "index", index, "error", err)
+		fp.State.ErrorData = fmt.Sprintf("\n<< failed to locate %s/"+
+			"seed#%d: %v >>\n", target, index, err)
+		fp.State.InputPrinted = true
+
+		return ""
+	}
+
+	location := fmt.Sprintf("%s:%d", filepath.Join(fp.pkg,
+		filepath.Base(entry.File)), entry.Line)
+	if entry.Approximate {
+		location += " (approximate, f.Add called in or after a loop)"
+	}
+	fp.State.SeedLocation = location
+	fp.State.ErrorData = fmt.Sprintf("\n\n=== Failing seed entry (%s/"+
+		"seed#%d) added at %s ===", target, index, location)
+
+	fp.logger.Info("Failing seed entry located", "target", target,
+		"index", index, "location", location)
+
+	if entry.Values == nil {
+		fp.logger.Warn("Seed values cannot be determined statically",
+			"target", target, "index", index)
+		fp.State.InputPrinted = true
+
+		return ""
+	}
+
+	id, err := writeSeedCorpusFile(fp.corpusPath, target, entry)
+	if err != nil {
+		fp.logger.Error("Failed to materialise seed entry", "error",
+			err)
+		fp.State.InputPrinted = true
+
+		return ""
+	}
+
+	return id
+}
+
+// analyzeFailure classifies the failure, parses its stack trace and derives
+// the crash signature from it. Failures without in-project stack frames are
+// identified by their class, target and summary instead. The results are
+// appended to the error data written at the end of the failure log.
+func (fp *FuzzProcessor) analyzeFailure() {
+	lines := fp.State.FailureLines
+
+	fp.State.Class = ClassifyFailure(lines)
+	if fp.State.Stalled {
+		fp.State.Class = ClassHang
+	}
+	fp.State.StackTrace = ParseStackTrace(lines)
+	if fp.State.StackTrace != nil {
+		fp.State.StackTrace.Module = fp.module
+	}
+	fp.State.Summary = FailureSummary(fp.State.Class, fp.State.StackTrace,
+		lines)
+
+	st := fp.State.StackTrace
+	if st != nil && len(st.ProjectFrames(SignatureFrameCount)) > 0 {
+		fp.State.Signature = st.Signature(SignatureFrameCount)
+	} else {
+		fp.State.Signature = hashSignature(strings.Join([]string{
+			string(fp.State.Class), fp.pkg, fp.target,
+			fp.State.Summary,
+		}, "\n"))
+	}
+
+	fp.State.ErrorData += fmt.Sprintf("\n\n=== Crash summary ===\n"+
+		"class: %s\npriority: %d\nsignature: %s\nsummary: %s",
+		fp.State.Class, fp.State.Class.Priority(), fp.State.Signature,
+		fp.State.Summary)
+
+	fp.logger.Info("Failure classified", "class", fp.State.Class,
+		"priority", fp.State.Class.Priority(), "signature",
+		fp.State.Signature, "summary", fp.State.Summary)
+}
+
+// recordCrash adds the failure to the crash database and logs whether its
+// signature is a new finding or a repeat of a known crash. The crash is
+// announced later, by ReportCrash.
+func (fp *FuzzProcessor) recordCrash() error {
+	finding := &crash.Finding{
+		Signature: fp.State.Signature,
+		Class:     string(fp.State.Class),
+		Priority:  fp.State.Class.Priority(),
+		Summary:   fp.State.Summary,
+		Package:   fp.pkg,
+		Target:    fp.target,
+		LogPath:   fp.logPath,
+		Commit:    fp.commit,
+		GoVersion: fp.goVersion,
+		Location:  crashLocation(fp.State.StackTrace),
+	}
+	if fp.State.FailingInputID != "" {
+		finding.InputID = fp.State.FailingInputID
+		finding.InputPath = filepath.Join(fp.corpusPath, fp.target,
+			fp.State.FailingInputID)
+	}
+
+	isNew, err := fp.crashStore.Add(finding)
+	if err != nil {
+		return fmt.Errorf("crash database update failed: %w", err)
+	}
+	fp.State.NewFinding = isNew
+
+	metrics.CrashesFound.WithLabelValues(string(fp.State.Class), fp.pkg,
+		fp.target).Inc()
+
+	if isNew {
+		fp.logger.Warn("New crash found", "signature",
+			fp.State.Signature, "class", fp.State.Class,
+			"priority", fp.State.Class.Priority())
+	} else {
+		fp.logger.Info("Known crash reproduced", "signature",
+			fp.State.Signature, "class", fp.State.Class)
+	}
+	fp.finding = finding
+
+	return nil
+}
+
+// PendingCrash is a new crash whose announcement waits for the search of the
+// commit introducing it, see ReportCrash.
+type PendingCrash struct {
+	// Finding is the new crash, its failing input being kept in the
+	// crash database.
+	Finding *crash.Finding
+
+	// Event is the announcement of the crash, which the outcome of the
+	// search is added to.
+	Event *notify.Event
+}
+
+// ReportCrash announces the crash recorded while processing the stream, if
+// any. It must be called once the fuzzing process has exited, while the
+// failing input is still in the testdata of the package: the input of a new
+// crash is first re-run to verify that it reproduces, and the outcome is
+// recorded with the crash and included in its announcement.
+//
+// Searching the project history for the commit introducing a new crash takes
+// too long to be done while the fuzz target holds its slot in the cycle. If
+// bisection is enabled and the crash reliably reproduces, it is returned
+// instead of announced, to be bisected and announced by the caller once the
+// fuzz targets of the cycle are done. It returns nil otherwise.
+func (fp *FuzzProcessor) ReportCrash(ctx context.Context) *PendingCrash {
+	finding := fp.finding
+	if finding == nil {
+		return nil
+	}
+	fp.finding = nil
+
+	if !fp.State.NewFinding {
+		fp.notifier.NotifyRecurrence(fp.crashEvent(finding))
+		return nil
+	}
+
+	if finding.InputID != "" {
+		fp.verifyCrash(ctx, finding)
+
+		// Crashes that do not reliably reproduce would mislead the
+		// search.
+		v := fp.State.Verification
+		if fp.cfg.BisectCommits > 0 && finding.Commit != "" &&
+			(v == nil || v.Label == crash.Reproducible) {
+
+			return &PendingCrash{
+				Finding: finding,
+				Event:   fp.crashEvent(finding),
+			}
+		}
+	}
+	fp.notifier.Notify(fp.crashEvent(finding))
+
+	return nil
+}
+
+// verifyCrash re-runs the failing input of the crash, see crash.Verify, and
+// records how reliably it reproduces. A failed verification is only logged,
+// the crash is reported without it.
+func (fp *FuzzProcessor) verifyCrash(ctx context.Context,
+	finding *crash.Finding) {
+
+	// The corpus the failing input was written to is the testdata/fuzz
+	// directory of the package.
+	pkgDir := filepath.Dir(filepath.Dir(fp.corpusPath))
+
+	v, err := crash.Verify(ctx, fp.cfg, pkgDir, fp.pkg, fp.target,
+		finding.InputID)
+	if err != nil {
+		fp.logger.Warn("Failed to verify crash", "signature",
+			finding.Signature, "error", err)
+		return
+	}
+	if v == nil {
+		return
+	}
+	fp.State.Verification = v
+
+	if err := fp.crashStore.SetVerification(finding.Signature,
+		v); err != nil {
+
+		fp.logger.Error("Failed to record crash verification",
+			"signature", finding.Signature, "error", err)
+	}
+
+	fp.logger.Info("Crash verified", "signature", finding.Signature,
+		"reproducibility", v.Label, "failures", v.Failures, "runs",
+		v.Runs)
+}
+
+// crashLocation returns the location of the innermost project frame of the
+// stack trace, or nil if there is none. Files of the fuzzed project are made
+// relative to its root.
+func crashLocation(st *StackTrace) *crash.Location {
+	if st == nil {
+		return nil
+	}
+	frames := st.ProjectFrames(1)
+	if len(frames) == 0 {
+		return nil
+	}
+
+	file := frames[0].File
+	if root, err := filepath.Abs(config.DefaultProjectDir); err == nil {
+		if rel, err := filepath.Rel(root, file); err == nil &&
+			filepath.IsLocal(rel) {
+
+			file = rel
+		}
+	}
+
+	return &crash.Location{
+		Function: frames[0].Function,
+		File:     filepath.ToSlash(file),
+		Line:     frames[0].Line,
+	}
+}
+
+// crashEvent builds the notification announcing the recorded crash.
+func (fp *FuzzProcessor) crashEvent(finding *crash.Finding) *notify.Event {
+	event := &notify.Event{
+		Signature: finding.Signature,
+		Class:     finding.Class,
+		Priority:  finding.Priority,
+		Summary:   finding.Summary,
+		Package:   finding.Package,
+		Target:    finding.Target,
+		InputID:   finding.InputID,
+		Commit:    finding.Commit,
+		Time:      time.Now().UTC(),
+	}
+
+	event.Stack = fp.State.FailureExcerpt(notifiedStackLines)
+	event.Verification = fp.State.Verification
+
+	if finding.InputPath != "" {
+		data, err := os.ReadFile(finding.InputPath)
+		if err != nil {
+			fp.logger.Warn("Failed to read failing input for "+
+				"notification", "error", err)
+		}
+		event.Input = string(data[:min(len(data),
+			maxNotifiedInputSize)])
+		event.InputTruncated = len(data) > maxNotifiedInputSize
+	}
+
+	return event
+}
+
 // parseFailureLine attempts to extract the fuzz target name and input ID
 // from a line of fuzzing output. It uses a predefined regular expression
 // to match lines that indicate a failure, capturing the relevant details
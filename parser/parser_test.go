@@ -1,11 +1,21 @@
 package parser
 
 import (
+	"context"
+	"errors"
+	"io"
 	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/NishantBansal2003/LND-Fuzz/crash"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestParseFailureLine verifies that parseFailureLine correctly extracts
@@ -95,7 +105,8 @@ func TestReadInputData(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			processor := NewFuzzProcessor(&slog.Logger{},
-				&config.Config{}, tt.corpusPath, "")
+				&config.Config{}, tt.corpusPath, "", "", "",
+				"", nil)
 
 			actualData := processor.readInputData(tt.fuzzTarget,
 				tt.testcaseID)
@@ -107,3 +118,334 @@ func TestReadInputData(t *testing.T) {
 		})
 	}
 }
+
+// TestProcessStreamLongLines verifies that lines far longer than the default
+// bufio.Scanner limit neither stop the processing nor hide a later failure,
+// and that they are written to the failure log in full.
+func TestProcessStreamLongLines(t *testing.T) {
+	longLine := strings.Repeat("A", 4*1024*1024)
+
+	var stream strings.Builder
+	stream.WriteString("fuzz: elapsed: 0s, gathering baseline coverage\n")
+	stream.WriteString(longLine + "\n")
+	stream.WriteString("--- FAIL: FuzzFoo (0.05s)\n")
+	stream.WriteString("    foo_test.go:12: " + longLine + "\n")
+	stream.WriteString("    Failing input written to testdata/fuzz/" +
+		"FuzzFoo/771e938e4458e983\r\n")
+
+	// The final line has no trailing newline.
+	stream.WriteString("FAIL")
+
+	resultsPath := t.TempDir()
+	processor := NewFuzzProcessor(
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		&config.Config{FuzzResultsPath: resultsPath}, "testdata",
+		"foo", "FuzzFoo", "", "", nil,
+	)
+
+	err := processor.ProcessStream(strings.NewReader(stream.String()))
+	require.NoError(t, err)
+
+	assert.True(t, processor.State.SeenFailure)
+	assert.Equal(t, "771e938e4458e983", processor.State.FailingInputID)
+	assert.Equal(t, ClassAssertion, processor.State.Class)
+
+	data, err := os.ReadFile(filepath.Join(resultsPath,
+		"FuzzFoo_failure.log"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "    foo_test.go:12: "+longLine+
+		"\n")
+	assert.Contains(t, string(data), "\nFAIL\n")
+	assert.Contains(t, string(data), "=== Failing testcase (FuzzFoo/"+
+		"771e938e4458e983) ===")
+}
+
+// TestProcessStreamReadError verifies that a failing stream is reported,
+// while the output read before the error is still processed.
+func TestProcessStreamReadError(t *testing.T) {
+	stream := io.MultiReader(
+		strings.NewReader("--- FAIL: FuzzFoo (0.05s)\n"),
+		iotest.ErrReader(errors.New("pipe broken")),
+	)
+
+	processor := NewFuzzProcessor(
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		&config.Config{FuzzResultsPath: t.TempDir()}, "testdata",
+		"foo", "FuzzFoo", "", "", nil,
+	)
+
+	err := processor.ProcessStream(stream)
+	assert.ErrorContains(t, err, "pipe broken")
+	assert.True(t, processor.State.SeenFailure)
+}
+
+// TestReportCrashVerification verifies that the failing input of a new crash
+// is re-run before the crash is reported, and the outcome recorded, while
+// known crashes are not verified again.
+func TestReportCrashVerification(t *testing.T) {
+	pkgDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(pkgDir, "go.mod"),
+		[]byte("module example.com/project\n\ngo 1.21\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(pkgDir, "foo_test.go"),
+		[]byte("package foo\n\nimport \"testing\"\n\n"+
+			"func FuzzFoo(f *testing.F) {\n"+
+			"\tf.Fuzz(func(t *testing.T, s string) {\n"+
+			"\t\tpanic(\"boom\")\n"+
+			"\t})\n}\n"), 0644))
+
+	corpusPath := filepath.Join(pkgDir, "testdata", "fuzz")
+	inputPath := filepath.Join(corpusPath, "FuzzFoo", "771e938e4458e983")
+	require.NoError(t, os.MkdirAll(filepath.Dir(inputPath), 0755))
+	require.NoError(t, os.WriteFile(inputPath,
+		[]byte("go test fuzz v1\nstring(\"0\")\n"), 0644))
+
+	cfg := &config.Config{FuzzResultsPath: t.TempDir(), VerifyRuns: 2}
+	stream := "--- FAIL: FuzzFoo (0.05s)\n" +
+		"    --- FAIL: FuzzFoo (0.00s)\n" +
+		"        foo_test.go:7: boom\n" +
+		"    Failing input written to testdata/fuzz/FuzzFoo/" +
+		"771e938e4458e983\n" +
+		"FAIL\n"
+
+	process := func() *FuzzProcessor {
+		processor := NewFuzzProcessor(
+			slog.New(slog.NewTextHandler(io.Discard, nil)), cfg,
+			corpusPath, "foo", "FuzzFoo", "", "", nil,
+		)
+		require.NoError(t, processor.ProcessStream(
+			strings.NewReader(stream)))
+		processor.ReportCrash(context.Background())
+
+		return processor
+	}
+
+	processor := process()
+	require.True(t, processor.State.NewFinding)
+	require.NotNil(t, processor.State.Verification)
+	assert.Equal(t, crash.Reproducible,
+		processor.State.Verification.Label)
+
+	record, err := crash.NewStore(cfg).Record(processor.State.Signature)
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, processor.State.Verification, record.Verification)
+
+	// The same crash found again is not verified.
+	processor = process()
+	assert.False(t, processor.State.NewFinding)
+	assert.Nil(t, processor.State.Verification)
+
+	// A new crash to bisect is left to the caller to announce.
+	cfg = &config.Config{
+		FuzzResultsPath: t.TempDir(),
+		VerifyRuns:      1,
+		BisectCommits:   10,
+	}
+	processor = NewFuzzProcessor(
+		slog.New(slog.NewTextHandler(io.Discard, nil)), cfg,
+		corpusPath, "foo", "FuzzFoo", "0a1b2c3d", "", nil,
+	)
+	require.NoError(t, processor.ProcessStream(strings.NewReader(stream)))
+	pending := processor.ReportCrash(context.Background())
+	require.NotNil(t, pending)
+	assert.Equal(t, processor.State.Signature, pending.Finding.Signature)
+	assert.Equal(t, "0a1b2c3d", pending.Finding.Commit)
+	assert.Nil(t, pending.Event.Bisection)
+}
+
+// TestTruncateLine verifies that only lines longer than MaxLoggedLineLength
+// are truncated for logging.
+func TestTruncateLine(t *testing.T) {
+	short := strings.Repeat("a", MaxLoggedLineLength)
+	assert.Equal(t, short, truncateLine(short))
+
+	long := strings.Repeat("b", MaxLoggedLineLength+10)
+	assert.Equal(t, strings.Repeat("b", MaxLoggedLineLength)+
+		"... (10 bytes truncated)", truncateLine(long))
+}
+
+// TestTrackProgress verifies that progress is first noted when fuzzing
+// starts, and afterwards only when the fuzzer runs more inputs.
+func TestTrackProgress(t *testing.T) {
+	processor := NewFuzzProcessor(
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		&config.Config{FuzzResultsPath: t.TempDir()}, "testdata",
+		"foo", "FuzzFoo", "", "", nil,
+	)
+
+	processor.trackProgress("go: downloading github.com/foo/bar v1.0.0")
+	assert.True(t, processor.LastProgress().IsZero())
+
+	processor.trackProgress("fuzz: elapsed: 0s, gathering baseline " +
+		"coverage: 0/35 completed")
+	assert.False(t, processor.LastProgress().IsZero())
+
+	tests := []struct {
+		line     string
+		advanced bool
+	}{
+		{
+			line: "fuzz: elapsed: 3s, gathering baseline " +
+				"coverage: 0/35 completed",
+			advanced: false,
+		},
+		{
+			line: "fuzz: elapsed: 6s, gathering baseline " +
+				"coverage: 12/35 completed",
+			advanced: true,
+		},
+		{
+			line: "fuzz: elapsed: 9s, execs: 1200 (400/sec), " +
+				"new interesting: 0 (total: 35)",
+			advanced: true,
+		},
+		{
+			line: "fuzz: elapsed: 12s, execs: 1200 (0/sec), " +
+				"new interesting: 0 (total: 35)",
+			advanced: false,
+		},
+	}
+
+	for _, tt := range tests {
+		// Reset the last progress to tell whether the line updated it.
+		processor.lastProgress.Store(1)
+		processor.trackProgress(tt.line)
+
+		advanced := processor.LastProgress().After(time.Unix(0, 1))
+		assert.Equal(t, tt.advanced, advanced, tt.line)
+	}
+}
+
+// TestProcessStreamStalled verifies that the output following a stall, if
+// any, is recorded as a hang in the crash database.
+func TestProcessStreamStalled(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+	}{
+		{
+			name: "goroutine dump",
+			output: "SIGQUIT: quit\n" +
+				"PC=0x4721c1 m=0 sigcode=0\n\n" +
+				"goroutine 0 [idle]:\n" +
+				"runtime.futex(0x1, 0x80)\n" +
+				"\t/go/src/runtime/sys_linux_amd64.s:557\n\n" +
+				"goroutine 7 [chan receive]:\n" +
+				"github.com/foo/bar.wait(...)\n" +
+				"\t/src/bar/bar.go:10 +0x25\n" +
+				"github.com/foo/bar.FuzzFoo.func1(0x1)\n" +
+				"\t/src/bar/bar_test.go:20 +0x3a\n" +
+				"FAIL\tgithub.com/foo/bar\t610.2s\n",
+		},
+		{
+			name:   "no output",
+			output: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{FuzzResultsPath: t.TempDir()}
+			processor := NewFuzzProcessor(
+				slog.New(slog.NewTextHandler(io.Discard, nil)),
+				cfg, "testdata", "foo", "FuzzFoo", "", "", nil,
+			)
+
+			reader, writer := io.Pipe()
+			go func() {
+				_, _ = io.WriteString(writer, "fuzz: elapsed: "+
+					"3s, execs: 12 (4/sec), new "+
+					"interesting: 0 (total: 35)\n")
+				processor.MarkStalled()
+				_, _ = io.WriteString(writer, tt.output)
+				_ = writer.Close()
+			}()
+
+			require.NoError(t, processor.ProcessStream(reader))
+
+			state := processor.State
+			assert.True(t, state.SeenFailure)
+			assert.True(t, state.Stalled)
+			assert.Equal(t, ClassHang, state.Class)
+			assert.Equal(t, "no fuzzing progress", state.Summary)
+			assert.True(t, state.NewFinding)
+
+			records, err := crash.NewStore(cfg).Records()
+			require.NoError(t, err)
+			require.Len(t, records, 1)
+			assert.Equal(t, "hang", records[0].Class)
+		})
+	}
+}
+
+// TestCrashLocation verifies that the crash location is the innermost project
+// frame, with files of the fuzzed project relative to its root.
+func TestCrashLocation(t *testing.T) {
+	root, err := filepath.Abs(config.DefaultProjectDir)
+	require.NoError(t, err)
+
+	runtimeFrame := Frame{
+		Function: "runtime.gopanic",
+		File:     "/usr/local/go/src/runtime/panic.go",
+		Line:     787,
+	}
+
+	tests := []struct {
+		name     string
+		st       *StackTrace
+		expected *crash.Location
+	}{
+		{
+			name: "no stack trace",
+		},
+		{
+			name: "no project frame",
+			st: &StackTrace{
+				Goroutines: [][]Frame{{runtimeFrame}},
+			},
+		},
+		{
+			name: "project file",
+			st: &StackTrace{
+				Goroutines: [][]Frame{{
+					runtimeFrame,
+					{
+						Function: "github.com/" +
+							"owner/repo/foo.Parse",
+						File: filepath.Join(root,
+							"foo", "parse.go"),
+						Line: 42,
+					},
+				}},
+			},
+			expected: &crash.Location{
+				Function: "github.com/owner/repo/foo.Parse",
+				File:     "foo/parse.go",
+				Line:     42,
+			},
+		},
+		{
+			name: "dependency file",
+			st: &StackTrace{
+				Goroutines: [][]Frame{{{
+					Function: "github.com/owner/dep.Decode",
+					File: "/go/pkg/mod/github.com/owner/" +
+						"dep@v1.0.0/decode.go",
+					Line: 7,
+				}}},
+			},
+			expected: &crash.Location{
+				Function: "github.com/owner/dep.Decode",
+				File: "/go/pkg/mod/github.com/owner/" +
+					"dep@v1.0.0/decode.go",
+				Line: 7,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, crashLocation(tt.st))
+		})
+	}
+}
@@ -0,0 +1,356 @@
+package parser
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// corpusFileHeader is the first line of every file in a Go fuzz corpus.
+	corpusFileHeader = "go test fuzz v1"
+
+	// corpusIDLength is the number of hexadecimal characters of the SHA-256
+	// digest used by the go command to name corpus files.
+	corpusIDLength = 16
+)
+
+var (
+	// seedFailureRegex matches lines indicating a failure of an f.Add seed
+	// entry, capturing the fuzz target name and the seed index.
+	//
+	// It matches lines like:
+	//   "failure while testing seed corpus entry: FuzzFoo/seed#0"
+	//
+	// Captured groups:
+	//   - "target": the fuzz target name (e.g., "FuzzFoo")
+	//   - "index": the index of the f.Add call (e.g., "0")
+	seedFailureRegex = regexp.MustCompile(
+		`failure while testing seed corpus entry:\s*` +
+			`(?P<target>[^/]+)/seed#(?P<index>\d+)`,
+	)
+)
+
+// SeedEntry describes the f.Add call that produced a failing seed corpus entry.
+type SeedEntry struct {
+	// Index is the position of the entry among the seed entries of the fuzz
+	// target.
+	Index int
+
+	// File is the path of the test file containing the f.Add call.
+	File string
+
+	// Line is the line of the f.Add call within File.
+	Line int
+
+	// Approximate indicates that the f.Add call is executed in a loop, or
+	// follows one, so the reported location is a best guess rather than
+	// the exact entry and its values are unknown.
+	Approximate bool
+
+	// Values holds the seed values encoded in the "go test fuzz v1"
+	// format, one per line. It is nil if the values are not literals that
+	// can be determined statically.
+	Values []string
+}
+
+// CorpusData returns the contents of a corpus file holding the seed values and
+// the name the go command would give that file.
+func (e *SeedEntry) CorpusData() ([]byte, string) {
+	var b bytes.Buffer
+	b.WriteString(corpusFileHeader + "\n")
+	for _, value := range e.Values {
+		b.WriteString(value + "\n")
+	}
+
+	sum := sha256.Sum256(b.Bytes())
+	return b.Bytes(), hex.EncodeToString(sum[:])[:corpusIDLength]
+}
+
+// parseSeedFailureLine extracts the fuzz target name and seed index from a
+// seed corpus failure line. It returns an empty target if the line does not
+// match.
+func parseSeedFailureLine(line string) (string, int) {
+	m := seedFailureRegex.FindStringSubmatch(line)
+	if m == nil {
+		return "", 0
+	}
+
+	index, err := strconv.Atoi(m[seedFailureRegex.SubexpIndex("index")])
+	if err != nil {
+		return "", 0
+	}
+
+	return m[seedFailureRegex.SubexpIndex("target")], index
+}
+
+// findSeedEntry analyses the test files of the package in pkgDir to locate the
+// f.Add call of the fuzz target that produced the seed entry with the given
+// index. Seed entries are numbered in the order f.Add is called, which is
+// assumed to be the source order; calls made in a loop can only be located
+// approximately.
+func findSeedEntry(pkgDir, target string, index int) (*SeedEntry, error) {
+	testFiles, err := filepath.Glob(filepath.Join(pkgDir, "*_test.go"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list test files: %w", err)
+	}
+
+	fset := token.NewFileSet()
+	for _, path := range testFiles {
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path,
+				err)
+		}
+
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || fn.Name.Name != target ||
+				fn.Body == nil {
+
+				continue
+			}
+
+			return seedEntryInFunc(fset, fn, index)
+		}
+	}
+
+	return nil, fmt.Errorf("fuzz target %s not found in %s", target,
+		pkgDir)
+}
+
+// seedCall is an f.Add call found in a fuzz target.
+type seedCall struct {
+	call   *ast.CallExpr
+	inLoop bool
+}
+
+// seedEntryInFunc locates the seed entry with the given index within the fuzz
+// target declaration. The f.Add calls before the first one made in a loop add
+// one entry each, so their index is exact. Past that loop, the index of an
+// entry depends on how many times the loop ran: the f.Add call outside a loop
+// at the given position is the best guess, then the first call made in a
+// loop.
+func seedEntryInFunc(fset *token.FileSet, fn *ast.FuncDecl,
+	index int) (*SeedEntry, error) {
+
+	// The *testing.F parameter is the receiver of the f.Add and f.Fuzz
+	// calls.
+	params := fn.Type.Params.List
+	if len(params) != 1 || len(params[0].Names) != 1 {
+		return nil, fmt.Errorf("%s is not a fuzz target", fn.Name.Name)
+	}
+	fName := params[0].Names[0].Name
+
+	var (
+		calls     []seedCall
+		fuzzTypes []ast.Expr
+	)
+	var inspect func(n ast.Node, inLoop bool)
+	inspect = func(n ast.Node, inLoop bool) {
+		ast.Inspect(n, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.ForStmt:
+				inspect(n.Body, true)
+				return false
+
+			case *ast.RangeStmt:
+				inspect(n.Body, true)
+				return false
+
+			case *ast.CallExpr:
+				switch methodOf(n, fName) {
+				case "Add":
+					calls = append(calls, seedCall{
+						call:   n,
+						inLoop: inLoop,
+					})
+
+				case "Fuzz":
+					fuzzTypes = fuzzArgTypes(n)
+				}
+			}
+			return true
+		})
+	}
+	inspect(fn.Body, false)
+
+	var loop *seedCall
+	for i, c := range calls {
+		switch {
+		case c.inLoop:
+			if loop == nil {
+				loop = &calls[i]
+			}
+
+		case i == index && loop == nil:
+			entry := newSeedEntry(fset, c.call, index, false)
+			entry.Values = encodeSeedValues(c.call.Args, fuzzTypes)
+
+			return entry, nil
+
+		case i == index:
+			return newSeedEntry(fset, c.call, index, true), nil
+		}
+	}
+
+	if loop != nil {
+		return newSeedEntry(fset, loop.call, index, true), nil
+	}
+
+	return nil, fmt.Errorf("seed entry #%d of %s not found", index,
+		fn.Name.Name)
+}
+
+// newSeedEntry returns the seed entry with the given index added by the f.Add
+// call, without its values.
+func newSeedEntry(fset *token.FileSet, call *ast.CallExpr, index int,
+	approximate bool) *SeedEntry {
+
+	pos := fset.Position(call.Pos())
+	return &SeedEntry{
+		Index:       index,
+		File:        pos.Filename,
+		Line:        pos.Line,
+		Approximate: approximate,
+	}
+}
+
+// methodOf returns the name of the method called on the identifier recv, or an
+// empty string if the call is not a method call on recv.
+func methodOf(call *ast.CallExpr, recv string) string {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+
+	ident, ok := sel.X.(*ast.Ident)
+	if !ok || ident.Name != recv {
+		return ""
+	}
+
+	return sel.Sel.Name
+}
+
+// fuzzArgTypes returns the types of the fuzzed arguments of an f.Fuzz call,
+// i.e. the parameters of the fuzz function after the *testing.T.
+func fuzzArgTypes(call *ast.CallExpr) []ast.Expr {
+	if len(call.Args) != 1 {
+		return nil
+	}
+
+	lit, ok := call.Args[0].(*ast.FuncLit)
+	if !ok {
+		return nil
+	}
+
+	var types []ast.Expr
+	for _, field := range lit.Type.Params.List {
+		// Unnamed parameters still count once.
+		n := max(len(field.Names), 1)
+		for range n {
+			types = append(types, field.Type)
+		}
+	}
+	if len(types) == 0 {
+		return nil
+	}
+
+	return types[1:]
+}
+
+// encodeSeedValues encodes the arguments of an f.Add call in the
+// "go test fuzz v1" format, using the parameter types of the fuzz function.
+// It returns nil unless every argument is a literal whose value can be
+// determined statically.
+func encodeSeedValues(args []ast.Expr, types []ast.Expr) []string {
+	if len(args) == 0 || len(args) != len(types) {
+		return nil
+	}
+
+	values := make([]string, 0, len(args))
+	for i, arg := range args {
+		typ := exprString(types[i])
+
+		// An explicit conversion such as []byte("x") or uint8(3)
+		// already has the corpus form, provided its operand is a
+		// literal.
+		if conv, ok := arg.(*ast.CallExpr); ok && len(conv.Args) == 1 {
+			if exprString(conv.Fun) != typ ||
+				!isLiteral(conv.Args[0]) {
+
+				return nil
+			}
+			values = append(values, exprString(conv))
+			continue
+		}
+
+		if !isLiteral(arg) {
+			return nil
+		}
+		values = append(values, fmt.Sprintf("%s(%s)", typ,
+			exprString(arg)))
+	}
+
+	return values
+}
+
+// isLiteral reports whether the expression is a basic literal, a negated basic
+// literal or a boolean constant.
+func isLiteral(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		return true
+
+	case *ast.UnaryExpr:
+		_, ok := e.X.(*ast.BasicLit)
+		return ok && e.Op == token.SUB
+
+	case *ast.Ident:
+		return e.Name == "true" || e.Name == "false"
+
+	default:
+		return false
+	}
+}
+
+// exprString returns the source form of the expression.
+func exprString(expr ast.Expr) string {
+	var b strings.Builder
+	_ = format.Node(&b, token.NewFileSet(), expr)
+
+	return b.String()
+}
+
+// writeSeedCorpusFile writes the seed values to a corpus file of the target in
+// corpusDir, so that the failing seed can be reproduced and tracked like an
+// input found by the fuzzer. It returns the name of the corpus file.
+func writeSeedCorpusFile(corpusDir, target string,
+	entry *SeedEntry) (string, error) {
+
+	data, id := entry.CorpusData()
+
+	targetDir := filepath.Join(corpusDir, target)
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create corpus directory: %w",
+			err)
+	}
+
+	if err := os.WriteFile(filepath.Join(targetDir, id), data,
+		0644); err != nil {
+
+		return "", fmt.Errorf("failed to write corpus file: %w", err)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,161 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseSeedFailureLine verifies that parseSeedFailureLine extracts the
+// fuzz target and seed index from seed corpus failure lines only.
+func TestParseSeedFailureLine(t *testing.T) {
+	target, index := parseSeedFailureLine("failure while testing seed " +
+		"corpus entry: FuzzFoo/seed#12")
+	assert.Equal(t, "FuzzFoo", target)
+	assert.Equal(t, 12, index)
+
+	target, _ = parseSeedFailureLine("failure while testing seed " +
+		"corpus entry: FuzzFoo/771e938e4458e983")
+	assert.Empty(t, target)
+}
+
+// TestFindSeedEntry verifies that failing seed entries are mapped to their
+// f.Add call and that literal seed values are encoded in the corpus format.
+func TestFindSeedEntry(t *testing.T) {
+	pkgDir := filepath.Join("testdata", "seed")
+	testFile := filepath.Join(pkgDir, "seed_test.go")
+
+	tests := []struct {
+		name      string
+		target    string
+		index     int
+		expectErr bool
+		expected  *SeedEntry
+	}{
+		{
+			name:   "literal values",
+			target: "FuzzFoo",
+			index:  0,
+			expected: &SeedEntry{
+				Index: 0,
+				File:  testFile,
+				Line:  6,
+				Values: []string{
+					`string("hello")`,
+					`int(5)`,
+					`[]byte("x")`,
+				},
+			},
+		},
+		{
+			name:   "composite literal",
+			target: "FuzzFoo",
+			index:  1,
+			expected: &SeedEntry{
+				Index: 1,
+				File:  testFile,
+				Line:  7,
+			},
+		},
+		{
+			name:   "variable",
+			target: "FuzzFoo",
+			index:  2,
+			expected: &SeedEntry{
+				Index: 2,
+				File:  testFile,
+				Line:  10,
+			},
+		},
+		{
+			name:   "explicit conversion",
+			target: "FuzzLoop",
+			index:  0,
+			expected: &SeedEntry{
+				Index:  0,
+				File:   testFile,
+				Line:   16,
+				Values: []string{`uint8(1)`},
+			},
+		},
+		{
+			name:   "added in a loop",
+			target: "FuzzLoop",
+			index:  2,
+			expected: &SeedEntry{
+				Index:       2,
+				File:        testFile,
+				Line:        18,
+				Approximate: true,
+			},
+		},
+		{
+			name:   "added after a loop",
+			target: "FuzzAfterLoop",
+			index:  1,
+			expected: &SeedEntry{
+				Index:       1,
+				File:        testFile,
+				Line:        28,
+				Approximate: true,
+			},
+		},
+		{
+			name:   "not added after a loop",
+			target: "FuzzAfterLoop",
+			index:  3,
+			expected: &SeedEntry{
+				Index:       3,
+				File:        testFile,
+				Line:        26,
+				Approximate: true,
+			},
+		},
+		{
+			name:      "index out of range",
+			target:    "FuzzFoo",
+			index:     3,
+			expectErr: true,
+		},
+		{
+			name:      "unknown target",
+			target:    "FuzzBar",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := findSeedEntry(pkgDir, tt.target,
+				tt.index)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, entry)
+		})
+	}
+}
+
+// TestWriteSeedCorpusFile verifies that seed values are written to a corpus
+// file named after its content, like the go command does.
+func TestWriteSeedCorpusFile(t *testing.T) {
+	corpusDir := t.TempDir()
+	entry := &SeedEntry{Values: []string{`string("0")`}}
+
+	id, err := writeSeedCorpusFile(corpusDir, "FuzzFoo", entry)
+	require.NoError(t, err)
+
+	// The same input committed under testdata/FuzzFoo was named by the go
+	// command.
+	assert.Equal(t, "771e938e4458e983", id)
+
+	data, err := os.ReadFile(filepath.Join(corpusDir, "FuzzFoo", id))
+	require.NoError(t, err)
+	assert.Equal(t, "go test fuzz v1\nstring(\"0\")\n", string(data))
+}
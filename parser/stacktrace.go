@@ -0,0 +1,367 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// SignatureFrameCount is the number of in-project stack frames that
+	// contribute to a crash signature.
+	SignatureFrameCount = 5
+
+	// signatureLength is the number of hexadecimal characters kept from
+	// the SHA-256 digest when building a crash signature.
+	signatureLength = 16
+)
+
+var (
+	// panicRegex matches the line that starts a Go panic or a fatal runtime
+	// error, optionally prefixed by the "file.go:123: " location emitted by
+	// the testing package when it reports a recovered panic.
+	//
+	// It matches lines like:
+	//   "panic: runtime error: index out of range [3] with length 3"
+	//   "testing.go:1591: panic: boom [recovered]"
+	//   "fatal error: concurrent map writes"
+	//
+	// Captured groups:
+	//   - "kind": either "panic" or "fatal error"
+	//   - "msg": the panic or fatal error message
+	panicRegex = regexp.MustCompile(
+		`^(?:\S+\.go:\d+: )?(?P<kind>panic|fatal error): (?P<msg>.*)$`,
+	)
+
+	// goroutineRegex matches the header of a goroutine dump, e.g.
+	// "goroutine 34 [running]:", as well as the headers of the stacks in a
+	// race detector report, e.g. "Write at 0x00c00001c0f8 by goroutine 7:"
+	// or "Goroutine 7 (running) created at:".
+	goroutineRegex = regexp.MustCompile(
+		`^(?:goroutine \d+ \[.*\]:?|(?:Previous )?(?i:read|write) ` +
+			`at 0x[0-9a-f]+ by (?:main )?goroutine(?: \d+)?:|` +
+			`Goroutine \d+ \(.*\) created at:)$`,
+	)
+
+	// raceHeader is the line that starts a race detector report.
+	raceHeader = "WARNING: DATA RACE"
+
+	// quitHeader is the line that starts the goroutine dump printed by a
+	// Go program receiving SIGQUIT.
+	quitHeader = "SIGQUIT: quit"
+
+	// fileLineRegex matches the file location line that follows each
+	// function line in a goroutine dump, e.g.
+	// "/path/to/foo.go:12 +0x89".
+	//
+	// Captured groups:
+	//   - "file": the absolute path of the source file
+	//   - "line": the line number within the file
+	fileLineRegex = regexp.MustCompile(
+		`^(?P<file>\S+\.go):(?P<line>\d+)(?: \+0x[0-9a-f]+)?$`,
+	)
+
+	// funcLineRegex matches the function line of a stack frame and strips
+	// its argument list, e.g. "pkg.(*T).Method(0xc000010000, {0x1, 0x2})".
+	funcLineRegex = regexp.MustCompile(`^(?P<func>.+)\([^()]*\)$`)
+
+	// createdByRegex matches the trailing "created by" frame of a goroutine
+	// dump, e.g. "created by testing.(*F).Fuzz.func1 in goroutine 33".
+	createdByRegex = regexp.MustCompile(
+		`^created by (?P<func>\S+)(?: in goroutine \d+)?$`,
+	)
+
+	// hexRegex matches hexadecimal literals such as pointer addresses.
+	hexRegex = regexp.MustCompile(`0x[0-9a-fA-F]+`)
+
+	// numberRegex matches decimal numbers such as indexes and lengths.
+	numberRegex = regexp.MustCompile(`\d+`)
+)
+
+// Frame is a single stack frame of a Go goroutine dump.
+type Frame struct {
+	// Function is the fully qualified function name without arguments,
+	// e.g. "github.com/owner/repo/pkg.(*T).Method".
+	Function string
+
+	// File is the absolute path of the source file.
+	File string
+
+	// Line is the line number within File.
+	Line int
+
+	// CreatedBy indicates that the frame is the "created by" entry that
+	// terminates a goroutine dump rather than an active call.
+	CreatedBy bool
+}
+
+// StackTrace is the parsed form of a Go panic, fatal error or race detector
+// report, or of a goroutine dump requested with SIGQUIT.
+type StackTrace struct {
+	// Kind is either "panic", "fatal error", "data race" or "SIGQUIT".
+	Kind string
+
+	// Message is the raw panic or fatal error message. It is empty for
+	// race detector reports and goroutine dumps.
+	Message string
+
+	// Frames holds the frames of the crashing goroutine, innermost first.
+	// For race detector reports it holds the frames of the first access.
+	Frames []Frame
+
+	// Goroutines holds the frames of every goroutine dumped in the report,
+	// starting with the crashing one.
+	Goroutines [][]Frame
+
+	// Module is the module path of the fuzzed project, whose packages are
+	// the only project code: frames of its dependencies are skipped like
+	// those of the standard library. If empty, every package outside the
+	// standard library is project code.
+	Module string
+}
+
+// ParseStackTrace scans the given lines of fuzzer output for a Go panic, fatal
+// error, race detector report or SIGQUIT goroutine dump and parses the
+// goroutine stacks that follow it.
+// Leading indentation added by the testing package is ignored. It returns nil
+// if no such report is found.
+func ParseStackTrace(lines []string) *StackTrace {
+	var (
+		st     *StackTrace
+		frames *[]Frame
+	)
+
+	for _, raw := range lines {
+		line := strings.TrimSpace(raw)
+
+		// Until the report header is found, look for nothing else.
+		if st == nil {
+			kind, msg := parsePanicLine(line)
+			switch {
+			case kind != "":
+				st = &StackTrace{Kind: kind, Message: msg}
+			case line == raceHeader:
+				st = &StackTrace{Kind: "data race"}
+			case line == quitHeader:
+				st = &StackTrace{Kind: "SIGQUIT"}
+			}
+			continue
+		}
+
+		// Each goroutine header starts a new stack.
+		if goroutineRegex.MatchString(line) {
+			st.Goroutines = append(st.Goroutines, nil)
+			frames = &st.Goroutines[len(st.Goroutines)-1]
+			continue
+		}
+		if frames == nil {
+			continue
+		}
+
+		// A file location completes the frame started by the preceding
+		// function line.
+		if m := fileLineRegex.FindStringSubmatch(line); m != nil {
+			if len(*frames) == 0 {
+				continue
+			}
+			last := &(*frames)[len(*frames)-1]
+			if last.File != "" {
+				continue
+			}
+			last.File = m[fileLineRegex.SubexpIndex("file")]
+			last.Line, _ = strconv.Atoi(
+				m[fileLineRegex.SubexpIndex("line")],
+			)
+			continue
+		}
+
+		if m := createdByRegex.FindStringSubmatch(line); m != nil {
+			fn := m[createdByRegex.SubexpIndex("func")]
+			*frames = append(*frames, Frame{
+				Function:  fn,
+				CreatedBy: true,
+			})
+			continue
+		}
+
+		if m := funcLineRegex.FindStringSubmatch(line); m != nil {
+			*frames = append(*frames, Frame{
+				Function: m[funcLineRegex.SubexpIndex("func")],
+			})
+		}
+	}
+
+	if st != nil && len(st.Goroutines) > 0 {
+		st.Frames = st.Goroutines[0]
+	}
+
+	return st
+}
+
+// parsePanicLine returns the kind ("panic" or "fatal error") and message of
+// a panic line, or empty strings if the line does not start a panic report.
+func parsePanicLine(line string) (string, string) {
+	m := panicRegex.FindStringSubmatch(line)
+	if m == nil {
+		return "", ""
+	}
+
+	return m[panicRegex.SubexpIndex("kind")],
+		m[panicRegex.SubexpIndex("msg")]
+}
+
+// PanicType returns the panic kind and message with every value that varies
+// between runs of the same bug (addresses, indexes, lengths) replaced by a
+// placeholder, e.g. "panic: runtime error: index out of range [N] with
+// length N".
+func (st *StackTrace) PanicType() string {
+	if st.Message == "" {
+		return st.Kind
+	}
+
+	msg := strings.TrimSuffix(st.Message, " [recovered]")
+	msg = strings.TrimSuffix(msg, " [recovered, repanicked]")
+
+	return st.Kind + ": " + normalizeMessage(msg)
+}
+
+// ProjectFrames returns up to n frames that belong to the fuzzed project,
+// skipping runtime, testing and other standard library frames as well as the
+// trailing "created by" frame. The frames are taken from the crashing
+// goroutine or, if it has none (e.g. the alarm goroutine of a test timeout),
+// from the first dumped goroutine that does.
+func (st *StackTrace) ProjectFrames(n int) []Frame {
+	for _, goroutine := range st.Goroutines {
+		frames := projectFrames(goroutine, st.Module, n)
+		if len(frames) > 0 {
+			return frames
+		}
+	}
+
+	return nil
+}
+
+// Excerpt formats the report header followed by up to n frames of the first
+// goroutine running project code, in the layout of a Go traceback.
+func (st *StackTrace) Excerpt(n int) string {
+	var b strings.Builder
+	b.WriteString(st.Kind)
+	if st.Message != "" {
+		b.WriteString(": " + st.Message)
+	}
+	b.WriteString("\n")
+
+	frames := st.Frames
+	for _, goroutine := range st.Goroutines {
+		if len(projectFrames(goroutine, st.Module, 1)) > 0 {
+			frames = goroutine
+			break
+		}
+	}
+
+	for i, frame := range frames {
+		if i == n {
+			b.WriteString("...\n")
+			break
+		}
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File,
+			frame.Line)
+	}
+
+	return b.String()
+}
+
+// projectFrames returns up to n frames of the goroutine that belong to the
+// fuzzed project, whose module path is given, empty if unknown.
+func projectFrames(goroutine []Frame, module string, n int) []Frame {
+	var frames []Frame
+	for _, frame := range goroutine {
+		if len(frames) == n {
+			break
+		}
+		if frame.CreatedBy ||
+			!isProjectFunction(frame.Function, module) {
+
+			continue
+		}
+		frames = append(frames, frame)
+	}
+
+	return frames
+}
+
+// normalizeMessage replaces every value that varies between runs of the same
+// bug (addresses, indexes, lengths, durations) with a placeholder.
+func normalizeMessage(msg string) string {
+	msg = hexRegex.ReplaceAllString(msg, "0x?")
+	return numberRegex.ReplaceAllString(msg, "N")
+}
+
+// Signature returns a stable identifier for the crash, derived from the panic
+// type and the function names of the top n in-project frames. Line numbers,
+// arguments and addresses are ignored so that the signature survives
+// unrelated changes to the fuzzed project.
+func (st *StackTrace) Signature(n int) string {
+	var b strings.Builder
+	b.WriteString(st.PanicType())
+	for _, frame := range st.ProjectFrames(n) {
+		b.WriteString("\n")
+		b.WriteString(frame.Function)
+	}
+
+	return hashSignature(b.String())
+}
+
+// hashSignature hashes the canonical description of a crash into a short
+// hexadecimal signature.
+func hashSignature(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])[:signatureLength]
+}
+
+// isProjectFunction reports whether the fully qualified function name belongs
+// to a package of the module, including its external test packages. If the
+// module is unknown, it reports whether the function belongs to a non standard
+// library package: like the go command, it treats import paths whose first
+// element contains no dot as part of the standard library.
+func isProjectFunction(function, module string) bool {
+	if module != "" {
+		return strings.HasPrefix(function, module+"/") ||
+			strings.HasPrefix(function, module+".") ||
+			strings.HasPrefix(function, module+"_test.")
+	}
+
+	first, _, hasSlash := strings.Cut(function, "/")
+	if !hasSlash {
+		// A single element path such as "main.main" or
+		// "runtime.gopanic": only "main" is user code. Dots here
+		// separate the package from method and closure names.
+		pkg, _, _ := strings.Cut(function, ".")
+		return pkg == "main"
+	}
+
+	return strings.Contains(first, ".")
+}
+
+// modulePath returns the module path declared by the go.mod file of the
+// directory, or an empty string if it cannot be read.
+func modulePath(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`)
+		}
+	}
+
+	return ""
+}
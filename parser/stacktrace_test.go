@@ -0,0 +1,213 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readPanicOutput returns the failure output of a fuzz target that panicked,
+// as printed by "go test -fuzz".
+func readPanicOutput(t *testing.T) string {
+	data, err := os.ReadFile("testdata/stacktrace/panic.log")
+	require.NoError(t, err)
+
+	return string(data)
+}
+
+// TestParseStackTrace verifies that ParseStackTrace extracts the panic message
+// and the frames of the crashing goroutine from fuzzer output.
+func TestParseStackTrace(t *testing.T) {
+	st := ParseStackTrace(strings.Split(readPanicOutput(t), "\n"))
+	require.NotNil(t, st)
+
+	assert.Equal(t, "panic", st.Kind)
+	assert.Equal(t, "runtime error: index out of range [3] with "+
+		"length 3 [recovered]", st.Message)
+	assert.Equal(t, "panic: runtime error: index out of range [N] "+
+		"with length N", st.PanicType())
+
+	require.Len(t, st.Frames, 9)
+	assert.Equal(t, Frame{
+		Function: "github.com/owner/repo/foo.parse",
+		File:     "/home/user/repo/foo/foo.go",
+		Line:     10,
+	}, st.Frames[3])
+	assert.Equal(t, Frame{
+		Function:  "testing.(*F).Fuzz.func1",
+		File:      "/usr/local/go/src/testing/fuzz.go",
+		Line:      322,
+		CreatedBy: true,
+	}, st.Frames[8])
+
+	assert.Equal(t, []Frame{st.Frames[3], st.Frames[4]},
+		st.ProjectFrames(SignatureFrameCount))
+}
+
+// TestExcerpt verifies that the excerpt of a stack trace holds its header and
+// the first frames of the crashing goroutine.
+func TestExcerpt(t *testing.T) {
+	st := ParseStackTrace(strings.Split(readPanicOutput(t), "\n"))
+	require.NotNil(t, st)
+
+	assert.Equal(t, "panic: runtime error: index out of range [3] with "+
+		"length 3 [recovered]\n"+
+		"runtime/debug.Stack\n"+
+		"\t/usr/local/go/src/runtime/debug/stack.go:24\n"+
+		"testing.tRunner.func1\n"+
+		"\t/usr/local/go/src/testing/testing.go:1591\n"+
+		"...\n", st.Excerpt(2))
+}
+
+// TestParseStackTraceNoPanic verifies that output without a panic yields no
+// stack trace.
+func TestParseStackTraceNoPanic(t *testing.T) {
+	lines := []string{
+		"--- FAIL: FuzzFoo (0.00s)",
+		"    foo_test.go:12: unexpected result",
+	}
+	assert.Nil(t, ParseStackTrace(lines))
+}
+
+// TestSignature verifies that crash signatures ignore addresses, line numbers
+// and indexes, but distinguish different panics and call sites.
+func TestSignature(t *testing.T) {
+	panicOutput := readPanicOutput(t)
+	base := ParseStackTrace(strings.Split(panicOutput, "\n"))
+	require.NotNil(t, base)
+
+	tests := []struct {
+		name      string
+		old       string
+		new       string
+		sameCrash bool
+	}{
+		{
+			name:      "different index and length",
+			old:       "[3] with length 3",
+			new:       "[7] with length 5",
+			sameCrash: true,
+		},
+		{
+			name:      "different addresses",
+			old:       "0xc00001a0b8",
+			new:       "0xc00002b0c9",
+			sameCrash: true,
+		},
+		{
+			name:      "different line numbers",
+			old:       "foo.go:10",
+			new:       "foo.go:42",
+			sameCrash: true,
+		},
+		{
+			name:      "different runtime frames",
+			old:       "testing.go:1689 +0xfb",
+			new:       "testing.go:1702 +0x1fb",
+			sameCrash: true,
+		},
+		{
+			name:      "different panic message",
+			old:       "index out of range",
+			new:       "slice bounds out of range",
+			sameCrash: false,
+		},
+		{
+			name:      "different crashing function",
+			old:       "github.com/owner/repo/foo.parse(...)",
+			new:       "github.com/owner/repo/foo.decode(...)",
+			sameCrash: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := strings.Replace(panicOutput, tt.old,
+				tt.new, 1)
+			st := ParseStackTrace(strings.Split(output, "\n"))
+			require.NotNil(t, st)
+
+			sig := st.Signature(SignatureFrameCount)
+			assert.Len(t, sig, signatureLength)
+			if tt.sameCrash {
+				assert.Equal(t, base.Signature(
+					SignatureFrameCount), sig)
+			} else {
+				assert.NotEqual(t, base.Signature(
+					SignatureFrameCount), sig)
+			}
+		})
+	}
+}
+
+// TestIsProjectFunction verifies the classification of function names into
+// project code and standard library or dependency code.
+func TestIsProjectFunction(t *testing.T) {
+	tests := []struct {
+		function string
+		module   string
+		expected bool
+	}{
+		{"runtime.gopanic", "", false},
+		{"runtime/debug.Stack", "", false},
+		{"testing.(*F).Fuzz.func1.1", "", false},
+		{"internal/fuzz.RunFuzzWorker", "", false},
+		{"main.main", "", true},
+		{"github.com/owner/repo/foo.(*T).Method", "", true},
+		{"example.com/foo.FuzzFoo.func1", "", true},
+		{"runtime.gopanic", "github.com/owner/repo", false},
+		{"github.com/owner/repo/foo.(*T).Method",
+			"github.com/owner/repo", true},
+		{"github.com/owner/repo.Parse", "github.com/owner/repo", true},
+		{"github.com/owner/repo_test.FuzzFoo", "github.com/owner/repo",
+			true},
+		{"github.com/owner/repo-extra.Parse", "github.com/owner/repo",
+			false},
+		{"github.com/btcsuite/btcd/wire.(*MsgTx).Deserialize",
+			"github.com/owner/repo", false},
+		{"google.golang.org/protobuf/proto.Unmarshal",
+			"github.com/owner/repo", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.function, func(t *testing.T) {
+			assert.Equal(t, tt.expected,
+				isProjectFunction(tt.function, tt.module))
+		})
+	}
+}
+
+// TestModuleFrames verifies that the frames of the dependencies of the fuzzed
+// module are not project frames.
+func TestModuleFrames(t *testing.T) {
+	st := &StackTrace{
+		Kind:    "panic",
+		Message: "boom",
+		Goroutines: [][]Frame{{
+			{Function: "runtime.gopanic"},
+			{Function: "github.com/btcsuite/btcd/wire.Decode"},
+			{Function: "github.com/owner/repo/foo.Parse"},
+		}},
+	}
+	assert.Equal(t, "github.com/btcsuite/btcd/wire.Decode",
+		st.ProjectFrames(1)[0].Function)
+
+	st.Module = "github.com/owner/repo"
+	assert.Equal(t, "github.com/owner/repo/foo.Parse",
+		st.ProjectFrames(1)[0].Function)
+}
+
+// TestModulePath verifies that the module path is read from go.mod.
+func TestModulePath(t *testing.T) {
+	dir := t.TempDir()
+	assert.Empty(t, modulePath(dir))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"),
+		[]byte("// The project.\nmodule github.com/owner/repo "+
+			"// comment\n\ngo 1.21\n"), 0644))
+	assert.Equal(t, "github.com/owner/repo", modulePath(dir))
+}
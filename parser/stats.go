@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var (
+	// fuzzStatsRegex matches the periodic progress line printed by the
+	// fuzzing coordinator.
+	//
+	// It matches lines like:
+	//   "fuzz: elapsed: 3s, execs: 326385 (108782/sec), new interesting:
+	//    10 (total: 35)"
+	//
+	// Captured groups:
+	//   - "elapsed": the time spent fuzzing (e.g., "1m3s")
+	//   - "execs": the total number of executions
+	//   - "rate": the executions per second since the previous line
+	//   - "new": the inputs added to the corpus since fuzzing started
+	//   - "total": the size of the corpus
+	fuzzStatsRegex = regexp.MustCompile(
+		`fuzz: elapsed: (?P<elapsed>\S+), ` +
+			`execs: (?P<execs>\d+) \((?P<rate>\d+)/sec\), ` +
+			`new interesting: (?P<new>\d+) ` +
+			`\(total: (?P<total>\d+)\)`,
+	)
+
+	// baselineRegex matches the progress line printed while the fuzzing
+	// coordinator runs the existing corpus to gather baseline coverage,
+	// capturing the number of corpus entries already run.
+	//
+	// It matches lines like:
+	//   "fuzz: elapsed: 3s, gathering baseline coverage: 12/35 completed"
+	baselineRegex = regexp.MustCompile(
+		`fuzz: elapsed: \S+, gathering baseline coverage: ` +
+			`(?P<done>\d+)/\d+ completed`,
+	)
+)
+
+// StatsSample is a single progress report of a fuzz target.
+type StatsSample struct {
+	// Time is when the progress line was read.
+	Time time.Time `json:"time"`
+
+	// Elapsed is the time spent fuzzing, as reported by the fuzzer.
+	Elapsed time.Duration `json:"elapsed"`
+
+	// Execs is the total number of executions of the fuzz function.
+	Execs int64 `json:"execs"`
+
+	// ExecsPerSec is the execution rate since the previous progress line.
+	ExecsPerSec int64 `json:"execs_per_sec"`
+
+	// NewInteresting is the number of inputs that expanded coverage since
+	// fuzzing started.
+	NewInteresting int64 `json:"new_interesting"`
+
+	// CorpusSize is the total number of inputs in the corpus.
+	CorpusSize int64 `json:"corpus_size"`
+}
+
+// parseStatsLine extracts a progress report from a line of fuzzer output. It
+// returns false if the line is not a progress line.
+func parseStatsLine(line string) (StatsSample, bool) {
+	m := fuzzStatsRegex.FindStringSubmatch(line)
+	if m == nil {
+		return StatsSample{}, false
+	}
+
+	elapsed, err := time.ParseDuration(
+		m[fuzzStatsRegex.SubexpIndex("elapsed")],
+	)
+	if err != nil {
+		return StatsSample{}, false
+	}
+
+	// The remaining groups only match digits, they always parse.
+	number := func(name string) int64 {
+		n, _ := strconv.ParseInt(m[fuzzStatsRegex.SubexpIndex(name)],
+			10, 64)
+		return n
+	}
+
+	return StatsSample{
+		Time:           time.Now().UTC(),
+		Elapsed:        elapsed,
+		Execs:          number("execs"),
+		ExecsPerSec:    number("rate"),
+		NewInteresting: number("new"),
+		CorpusSize:     number("total"),
+	}, true
+}
+
+// parseBaselineLine extracts the number of corpus entries run so far from a
+// baseline coverage progress line. It returns false if the line is not one.
+func parseBaselineLine(line string) (int64, bool) {
+	m := baselineRegex.FindStringSubmatch(line)
+	if m == nil {
+		return 0, false
+	}
+
+	done, err := strconv.ParseInt(m[baselineRegex.SubexpIndex("done")],
+		10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return done, true
+}
@@ -0,0 +1,59 @@
+package parser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseStatsLine verifies that parseStatsLine extracts the fuzzing
+// progress from the periodic coordinator output and ignores other lines.
+func TestParseStatsLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected StatsSample
+		ok       bool
+	}{
+		{
+			name: "progress line",
+			line: "fuzz: elapsed: 1m3s, execs: 326385 " +
+				"(108782/sec), new interesting: 10 " +
+				"(total: 35)",
+			expected: StatsSample{
+				Elapsed:        63 * time.Second,
+				Execs:          326385,
+				ExecsPerSec:    108782,
+				NewInteresting: 10,
+				CorpusSize:     35,
+			},
+			ok: true,
+		},
+		{
+			name: "baseline coverage line",
+			line: "fuzz: elapsed: 0s, gathering baseline " +
+				"coverage: 0/35 completed",
+			ok: false,
+		},
+		{
+			name: "unrelated line",
+			line: "--- FAIL: FuzzFoo (0.05s)",
+			ok:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sample, ok := parseStatsLine(tt.line)
+			assert.Equal(t, tt.ok, ok)
+			if !ok {
+				return
+			}
+
+			// The read time is not part of the comparison.
+			sample.Time = time.Time{}
+			assert.Equal(t, tt.expected, sample)
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package seed
+
+import "testing"
+
+func FuzzFoo(f *testing.F) {
+	f.Add("hello", 5, []byte("x"))
+	f.Add("world", -1, []byte{0x1})
+
+	prefix := "pre"
+	f.Add(prefix, 3, []byte(nil))
+
+	f.Fuzz(func(t *testing.T, s string, n int, b []byte) {})
+}
+
+func FuzzLoop(f *testing.F) {
+	f.Add(uint8(1))
+	for _, v := range []uint8{2, 3} {
+		f.Add(v)
+	}
+
+	f.Fuzz(func(t *testing.T, v uint8) {})
+}
+
+func FuzzAfterLoop(f *testing.F) {
+	for _, v := range []uint8{1} {
+		f.Add(v)
+	}
+	f.Add(uint8(2))
+
+	f.Fuzz(func(t *testing.T, v uint8) {})
+}
@@ -0,0 +1,551 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/NishantBansal2003/LND-Fuzz/crash"
+	"github.com/NishantBansal2003/LND-Fuzz/parser"
+)
+
+const (
+	// SARIFFileName is the file, relative to the fuzz results path, the
+	// SARIF log of the crash database is written to.
+	SARIFFileName = "findings.sarif"
+
+	// sarifVersion is the version of the SARIF format produced.
+	sarifVersion = "2.1.0"
+
+	// sarifSchema is the JSON schema of the SARIF format produced.
+	sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+	// toolName is the name of the tool reported in SARIF logs.
+	toolName = "go-continuous-fuzz"
+
+	// projectRootID is the base of the paths of the fuzzed project's
+	// source files.
+	projectRootID = "PROJECTROOT"
+
+	// crashDirID is the base of the paths of the failing inputs and logs
+	// stored in the crash database.
+	crashDirID = "CRASHDIR"
+
+	// fingerprintKey names the crash signature among the fingerprints of
+	// a result, letting consumers track a crash across runs.
+	fingerprintKey = "crashSignature/v1"
+)
+
+// sarifLog is the top-level object of a SARIF log file.
+type sarifLog struct {
+	// Schema is the URI of the JSON schema of the log.
+	Schema string `json:"$schema"`
+
+	// Version is the SARIF version of the log.
+	Version string `json:"version"`
+
+	// Runs holds the results of each run of the tool.
+	Runs []sarifRun `json:"runs"`
+}
+
+// sarifRun holds the results of a single run of the tool.
+type sarifRun struct {
+	// Tool describes the tool and its rules.
+	Tool sarifTool `json:"tool"`
+
+	// BaseIDs defines the bases the relative paths of the log refer to.
+	BaseIDs map[string]sarifArtifactPlace `json:"originalUriBaseIds"`
+
+	// Artifacts lists the files attached to the results.
+	Artifacts []sarifArtifact `json:"artifacts,omitempty"`
+
+	// Results lists the crashes.
+	Results []sarifResult `json:"results"`
+}
+
+// sarifTool describes the tool that produced the results.
+type sarifTool struct {
+	// Driver is the main component of the tool.
+	Driver sarifDriver `json:"driver"`
+}
+
+// sarifDriver describes the tool component and the rules it checks.
+type sarifDriver struct {
+	// Name is the name of the tool.
+	Name string `json:"name"`
+
+	// Rules describes the crash classes of the results.
+	Rules []sarifRule `json:"rules"`
+}
+
+// sarifRule describes a crash class.
+type sarifRule struct {
+	// ID is the crash class, e.g. "panic".
+	ID string `json:"id"`
+
+	// ShortDescription describes the crash class.
+	ShortDescription sarifMessage `json:"shortDescription"`
+
+	// DefaultConfiguration holds the severity of the crash class.
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+// sarifConfiguration holds the default severity of a rule.
+type sarifConfiguration struct {
+	// Level is "error", "warning" or "note".
+	Level string `json:"level"`
+}
+
+// sarifMessage is a plain text message.
+type sarifMessage struct {
+	// Text is the message.
+	Text string `json:"text"`
+}
+
+// sarifArtifactPlace locates a file, relative to the base identified by
+// URIBaseID if set. It is the "artifactLocation" object of the SARIF format.
+type sarifArtifactPlace struct {
+	// URI is the path or URI of the file.
+	URI string `json:"uri,omitempty"`
+
+	// URIBaseID identifies the base URI is relative to.
+	URIBaseID string `json:"uriBaseId,omitempty"`
+
+	// Index is the position of the file among the artifacts of the run.
+	Index *int `json:"index,omitempty"`
+
+	// Description describes the location.
+	Description *sarifMessage `json:"description,omitempty"`
+}
+
+// sarifArtifact is a file referenced by the results.
+type sarifArtifact struct {
+	// Location is where the file is stored.
+	Location sarifArtifactPlace `json:"location"`
+
+	// Roles lists how the file relates to the results.
+	Roles []string `json:"roles"`
+
+	// Contents holds the content of the file, if embedded.
+	Contents *sarifArtifactContent `json:"contents,omitempty"`
+}
+
+// sarifArtifactContent holds the content of an artifact.
+type sarifArtifactContent struct {
+	// Text is the content of a valid UTF-8 file.
+	Text string `json:"text,omitempty"`
+
+	// Binary is the content of any other file, base64 encoded.
+	Binary []byte `json:"binary,omitempty"`
+}
+
+// sarifResult is a single crash.
+type sarifResult struct {
+	// RuleID is the crash class.
+	RuleID string `json:"ruleId"`
+
+	// RuleIndex is the position of the crash class among the rules.
+	RuleIndex int `json:"ruleIndex"`
+
+	// Level is the severity of the crash.
+	Level string `json:"level"`
+
+	// Message summarizes the crash.
+	Message sarifMessage `json:"message"`
+
+	// Locations holds where the crash occurred, if known.
+	Locations []sarifLocation `json:"locations,omitempty"`
+
+	// PartialFingerprints identifies the crash across runs.
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+
+	// Attachments lists the failing inputs and the failure log.
+	Attachments []sarifAttachment `json:"attachments,omitempty"`
+
+	// Properties holds the details without a SARIF equivalent.
+	Properties sarifProperties `json:"properties"`
+}
+
+// sarifLocation is where a crash occurred.
+type sarifLocation struct {
+	// PhysicalLocation is the source line of the crashing frame.
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+
+	// LogicalLocations holds the crashing function.
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+// sarifPhysicalLocation is a line of a source file.
+type sarifPhysicalLocation struct {
+	// ArtifactLocation is the source file.
+	ArtifactLocation sarifArtifactPlace `json:"artifactLocation"`
+
+	// Region is the line within the source file.
+	Region sarifRegion `json:"region"`
+}
+
+// sarifRegion is a region of a source file.
+type sarifRegion struct {
+	// StartLine is the line number of the region.
+	StartLine int `json:"startLine"`
+}
+
+// sarifLogicalLocation is a function of the fuzzed code.
+type sarifLogicalLocation struct {
+	// FullyQualifiedName is the fully qualified function name.
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+
+	// Kind is always "function".
+	Kind string `json:"kind"`
+}
+
+// sarifAttachment is an artifact relevant to a result.
+type sarifAttachment struct {
+	// Description describes the attached file.
+	Description sarifMessage `json:"description"`
+
+	// ArtifactLocation is the attached file.
+	ArtifactLocation sarifArtifactPlace `json:"artifactLocation"`
+}
+
+// sarifProperties holds the details of a crash that have no SARIF
+// equivalent.
+type sarifProperties struct {
+	// Signature is the stack based identifier of the crash.
+	Signature string `json:"signature"`
+
+	// Priority is the triage priority of the crash class.
+	Priority int `json:"priority"`
+
+	// Hits counts how many times the crash has been recorded.
+	Hits int `json:"hits"`
+
+	// Targets lists the "<pkg>/<target>" fuzz targets that hit the crash.
+	Targets []string `json:"targets"`
+
+	// FirstSeen is the time the crash was first recorded.
+	FirstSeen time.Time `json:"firstSeen"`
+
+	// LastSeen is the time the crash was most recently recorded.
+	LastSeen time.Time `json:"lastSeen"`
+
+	// Commit is the project commit the crash was most recently recorded
+	// at, if known.
+	Commit string `json:"commit,omitempty"`
+
+	// Issue is the number of the GitHub issue filed for the crash, if any.
+	Issue int `json:"issue,omitempty"`
+
+	// State is where the crash stands in its lifecycle.
+	State crash.State `json:"state"`
+
+	// FixedCommit is the project commit the crash stopped reproducing at,
+	// if it is fixed.
+	FixedCommit string `json:"fixedCommit,omitempty"`
+
+	// Reproducibility labels how reliably the failing input of the crash
+	// fails again, if it was verified.
+	Reproducibility crash.Reproducibility `json:"reproducibility,omitempty"`
+
+	// ReproducibilityRate is the share of verification runs in which the
+	// failing input failed, if it was verified.
+	ReproducibilityRate *float64 `json:"reproducibilityRate,omitempty"`
+
+	// IntroducedBy is the commit introducing the crash, if bisecting
+	// narrowed it down to a single commit.
+	IntroducedBy string `json:"introducedBy,omitempty"`
+
+	// Candidates lists the commits which may have introduced
+	// the crash, if bisecting could only narrow it down to a range.
+	Candidates []string `json:"introducedByCandidates,omitempty"`
+}
+
+// WriteSARIF writes every crash of the database as a result of a SARIF 2.1.0
+// log. The crash class is the rule of the result and the crashing stack frame
+// its location. The failing inputs are embedded as artifacts attached to the
+// result, and the failure log is attached by reference.
+func WriteSARIF(w io.Writer, store *crash.Store) error {
+	records, err := store.Records()
+	if err != nil {
+		return err
+	}
+
+	crashDir, err := filepath.Abs(store.Dir())
+	if err != nil {
+		return fmt.Errorf("failed to resolve crash directory: %w", err)
+	}
+
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:  toolName,
+			Rules: []sarifRule{},
+		}},
+		BaseIDs: map[string]sarifArtifactPlace{
+			projectRootID: {
+				Description: &sarifMessage{
+					Text: "The root of the fuzzed " +
+						"project repository.",
+				},
+			},
+			crashDirID: {
+				URI: (&url.URL{
+					Scheme: "file",
+					Path: filepath.ToSlash(crashDir) +
+						"/",
+				}).String(),
+			},
+		},
+		Results: []sarifResult{},
+	}
+
+	// Results are listed oldest first, so that they keep their order as
+	// new crashes are found.
+	slices.SortStableFunc(records, func(a, b *crash.Record) int {
+		if c := a.FirstSeen.Compare(b.FirstSeen); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Signature, b.Signature)
+	})
+
+	for _, record := range records {
+		ruleIndex := slices.IndexFunc(run.Tool.Driver.Rules,
+			func(rule sarifRule) bool {
+				return rule.ID == record.Class
+			})
+		if ruleIndex < 0 {
+			ruleIndex = len(run.Tool.Driver.Rules)
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules,
+				newSARIFRule(record))
+		}
+
+		result := sarifResult{
+			RuleID:    record.Class,
+			RuleIndex: ruleIndex,
+			Level:     sarifLevel(record.Priority),
+			Message: sarifMessage{
+				Text: fmt.Sprintf("%s (found %d times by %s)",
+					record.Summary, record.Hits,
+					joinTargets(record.Targets)),
+			},
+			PartialFingerprints: map[string]string{
+				fingerprintKey: record.Signature,
+			},
+			Properties: sarifProperties{
+				Signature:   record.Signature,
+				Priority:    record.Priority,
+				Hits:        record.Hits,
+				Targets:     record.Targets,
+				FirstSeen:   record.FirstSeen,
+				LastSeen:    record.LastSeen,
+				Commit:      record.Commit,
+				Issue:       record.Issue,
+				State:       record.State,
+				FixedCommit: record.FixedCommit,
+			},
+		}
+		if v := record.Verification; v != nil {
+			rate := v.Rate()
+			result.Properties.Reproducibility = v.Label
+			result.Properties.ReproducibilityRate = &rate
+		}
+		if b := record.Bisection; b != nil {
+			result.Properties.IntroducedBy = b.FirstBad
+			result.Properties.Candidates = b.Candidates
+		}
+		if record.Location != nil {
+			result.Locations = []sarifLocation{
+				newSARIFLocation(record.Location),
+			}
+		}
+
+		for _, input := range record.Inputs {
+			artifact := sarifArtifact{
+				Location: sarifArtifactPlace{
+					URI:       filepath.ToSlash(input.Path),
+					URIBaseID: crashDirID,
+				},
+				Roles: []string{"attachment"},
+			}
+
+			data, err := os.ReadFile(filepath.Join(store.Dir(),
+				input.Path))
+			if err != nil {
+				return fmt.Errorf("failed to read input: %w",
+					err)
+			}
+			if utf8.Valid(data) {
+				artifact.Contents = &sarifArtifactContent{
+					Text: string(data),
+				}
+			} else {
+				artifact.Contents = &sarifArtifactContent{
+					Binary: data,
+				}
+			}
+
+			result.Attachments = append(result.Attachments,
+				newSARIFAttachment(&run, artifact, fmt.Sprintf(
+					"Failing input %s found by %s/%s",
+					input.ID, input.Package, input.Target,
+				)))
+		}
+
+		if record.LogPath != "" {
+			result.Attachments = append(result.Attachments,
+				newSARIFAttachment(&run, sarifArtifact{
+					Location: sarifArtifactPlace{
+						URI: filepath.ToSlash(
+							record.LogPath,
+						),
+						URIBaseID: crashDirID,
+					},
+					Roles: []string{"attachment"},
+				}, "Latest failure log"))
+		}
+
+		run.Results = append(run.Results, result)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(&sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode SARIF log: %w", err)
+	}
+
+	return nil
+}
+
+// SaveSARIF writes the SARIF log of the crash database to
+// <FuzzResultsPath>/findings.sarif, replacing the previous one.
+func SaveSARIF(cfg *config.Config) (string, error) {
+	if err := config.EnsureDirExists(cfg.FuzzResultsPath); err != nil {
+		return "", fmt.Errorf("failed to create results directory: %w",
+			err)
+	}
+
+	sarifPath := filepath.Join(cfg.FuzzResultsPath, SARIFFileName)
+	if err := writeFileAtomic(sarifPath, func(w io.Writer) error {
+		return WriteSARIF(w, crash.NewStore(cfg))
+	}); err != nil {
+		return "", err
+	}
+
+	return sarifPath, nil
+}
+
+// writeFileAtomic writes the file through a temporary file, so that readers
+// never see a partial file.
+func writeFileAtomic(name string, write func(io.Writer) error) error {
+	tmpPath := name + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", name, err)
+	}
+
+	if err := write(f); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+
+	if err := os.Rename(tmpPath, name); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// newSARIFRule returns the rule of the crash class of the record.
+func newSARIFRule(record *crash.Record) sarifRule {
+	return sarifRule{
+		ID: record.Class,
+		ShortDescription: sarifMessage{
+			Text: parser.FailureClass(record.Class).Description(),
+		},
+		DefaultConfiguration: sarifConfiguration{
+			Level: sarifLevel(record.Priority),
+		},
+	}
+}
+
+// newSARIFLocation returns the physical and logical location of the crash.
+// Files of the fuzzed project are relative to its root, other files are
+// referenced by their absolute path.
+func newSARIFLocation(loc *crash.Location) sarifLocation {
+	file := sarifArtifactPlace{URI: loc.File, URIBaseID: projectRootID}
+	if path.IsAbs(loc.File) {
+		fileURL := &url.URL{Scheme: "file", Path: loc.File}
+		file = sarifArtifactPlace{URI: fileURL.String()}
+	}
+
+	return sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: file,
+			Region:           sarifRegion{StartLine: loc.Line},
+		},
+		LogicalLocations: []sarifLogicalLocation{{
+			FullyQualifiedName: loc.Function,
+			Kind:               "function",
+		}},
+	}
+}
+
+// newSARIFAttachment adds the artifact to the run and returns an attachment
+// referencing it.
+func newSARIFAttachment(run *sarifRun, artifact sarifArtifact,
+	description string) sarifAttachment {
+
+	index := len(run.Artifacts)
+	run.Artifacts = append(run.Artifacts, artifact)
+
+	place := artifact.Location
+	place.Index = &index
+
+	return sarifAttachment{
+		Description:      sarifMessage{Text: description},
+		ArtifactLocation: place,
+	}
+}
+
+// sarifLevel maps the triage priority of a crash to a SARIF level.
+func sarifLevel(priority int) string {
+	switch priority {
+	case 1:
+		return "error"
+	case 2:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// joinTargets lists the fuzz targets for a message.
+func joinTargets(targets []string) string {
+	switch len(targets) {
+	case 0:
+		return "an unknown target"
+	case 1:
+		return targets[0]
+	default:
+		return fmt.Sprintf("%s and %d other targets", targets[0],
+			len(targets)-1)
+	}
+}
@@ -0,0 +1,163 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/NishantBansal2003/LND-Fuzz/crash"
+	"github.com/NishantBansal2003/LND-Fuzz/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStore returns a crash database holding a panic with a failing input
+// in the fuzzed project and a hang in one of its dependencies.
+func newTestStore(t *testing.T) (*config.Config, *crash.Store) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		FuzzResultsPath: filepath.Join(tmpDir, "fuzz_results"),
+	}
+	store := crash.NewStore(cfg)
+
+	inputPath := filepath.Join(tmpDir, "771e938e4458e983")
+	require.NoError(t, os.WriteFile(inputPath,
+		[]byte("go test fuzz v1\nstring(\"0\")\n"), 0644))
+	logPath := filepath.Join(tmpDir, "FuzzFoo_failure.log")
+	require.NoError(t, os.WriteFile(logPath, []byte("--- FAIL:\n"),
+		0644))
+
+	_, err := store.Add(&crash.Finding{
+		Signature: "0123456789abcdef",
+		Class:     "panic",
+		Priority:  1,
+		Summary:   "runtime error: index out of range",
+		Package:   "foo",
+		Target:    "FuzzFoo",
+		InputID:   "771e938e4458e983",
+		InputPath: inputPath,
+		LogPath:   logPath,
+		Commit:    "0a1b2c3d",
+		Location: &crash.Location{
+			Function: "github.com/owner/repo/foo.Parse",
+			File:     "foo/parse.go",
+			Line:     42,
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, store.SetVerification("0123456789abcdef",
+		&crash.Verification{Runs: 4, Failures: 1, Label: crash.Flaky}))
+	require.NoError(t, store.SetBisection("0123456789abcdef",
+		&git.Bisection{FirstBad: "0a1b2c3d", LastGood: "9f8e7d6c"}))
+
+	_, err = store.Add(&crash.Finding{
+		Signature: "fedcba9876543210",
+		Class:     "hang",
+		Priority:  2,
+		Summary:   "no fuzzing progress",
+		Package:   "bar",
+		Target:    "FuzzBar",
+		Location: &crash.Location{
+			Function: "github.com/owner/dep.Decode",
+			File:     "/go/pkg/mod/github.com/owner/dep/decode.go",
+			Line:     7,
+		},
+	})
+	require.NoError(t, err)
+
+	return cfg, store
+}
+
+// TestWriteSARIF verifies that every recorded crash is exported as a result,
+// with its class as the rule, its crashing frame as the location and its
+// failing input attached.
+func TestWriteSARIF(t *testing.T) {
+	_, store := newTestStore(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteSARIF(&buf, store))
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+
+	require.Len(t, run.Tool.Driver.Rules, 2)
+	assert.Equal(t, "panic", run.Tool.Driver.Rules[0].ID)
+	assert.Equal(t, "error",
+		run.Tool.Driver.Rules[0].DefaultConfiguration.Level)
+	assert.Equal(t, "hang", run.Tool.Driver.Rules[1].ID)
+	assert.Equal(t, "warning",
+		run.Tool.Driver.Rules[1].DefaultConfiguration.Level)
+
+	require.Len(t, run.Results, 2)
+
+	// The panic is located in the project and has its input and log
+	// attached.
+	result := run.Results[0]
+	assert.Equal(t, "panic", result.RuleID)
+	assert.Equal(t, 0, result.RuleIndex)
+	assert.Equal(t, "0123456789abcdef",
+		result.PartialFingerprints[fingerprintKey])
+	assert.Equal(t, "0a1b2c3d", result.Properties.Commit)
+	assert.Equal(t, crash.Flaky, result.Properties.Reproducibility)
+	require.NotNil(t, result.Properties.ReproducibilityRate)
+	assert.InDelta(t, 0.25, *result.Properties.ReproducibilityRate, 1e-9)
+	assert.Equal(t, "0a1b2c3d", result.Properties.IntroducedBy)
+	assert.Equal(t, crash.StateNew, result.Properties.State)
+	assert.Empty(t, result.Properties.FixedCommit)
+	require.Len(t, result.Locations, 1)
+	physical := result.Locations[0].PhysicalLocation
+	assert.Equal(t, "foo/parse.go", physical.ArtifactLocation.URI)
+	assert.Equal(t, projectRootID, physical.ArtifactLocation.URIBaseID)
+	assert.Equal(t, 42, physical.Region.StartLine)
+	assert.Equal(t, "github.com/owner/repo/foo.Parse",
+		result.Locations[0].LogicalLocations[0].FullyQualifiedName)
+
+	require.Len(t, result.Attachments, 2)
+	input := result.Attachments[0].ArtifactLocation
+	require.NotNil(t, input.Index)
+	assert.Equal(t, crashDirID, input.URIBaseID)
+	assert.Equal(t, "0123456789abcdef/inputs/foo/FuzzFoo/"+
+		"771e938e4458e983", input.URI)
+	artifact := run.Artifacts[*input.Index]
+	require.NotNil(t, artifact.Contents)
+	assert.Equal(t, "go test fuzz v1\nstring(\"0\")\n",
+		artifact.Contents.Text)
+	assert.Equal(t, "0123456789abcdef/FuzzFoo_failure.log",
+		result.Attachments[1].ArtifactLocation.URI)
+
+	// The hang is located in a dependency, outside the project.
+	result = run.Results[1]
+	assert.Equal(t, 1, result.RuleIndex)
+	assert.Equal(t, "warning", result.Level)
+	assert.Empty(t, result.Attachments)
+	assert.Empty(t, result.Properties.Reproducibility)
+	assert.Nil(t, result.Properties.ReproducibilityRate)
+	assert.Empty(t, result.Properties.IntroducedBy)
+	physical = result.Locations[0].PhysicalLocation
+	assert.Equal(t, "file:///go/pkg/mod/github.com/owner/dep/decode.go",
+		physical.ArtifactLocation.URI)
+	assert.Empty(t, physical.ArtifactLocation.URIBaseID)
+}
+
+// TestSaveSARIF verifies that the SARIF log is written to the results
+// directory.
+func TestSaveSARIF(t *testing.T) {
+	cfg, _ := newTestStore(t)
+
+	sarifPath, err := SaveSARIF(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(cfg.FuzzResultsPath, SARIFFileName),
+		sarifPath)
+
+	data, err := os.ReadFile(sarifPath)
+	require.NoError(t, err)
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(data, &log))
+	assert.Len(t, log.Runs[0].Results, 2)
+}
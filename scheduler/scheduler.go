@@ -2,77 +2,174 @@ package scheduler
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"os"
 	"time"
 
 	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/NishantBansal2003/LND-Fuzz/control"
+	"github.com/NishantBansal2003/LND-Fuzz/fuzz"
+	"github.com/NishantBansal2003/LND-Fuzz/metrics"
 	"github.com/NishantBansal2003/LND-Fuzz/worker"
 )
 
-// RunFuzzingCycles starts a continuous loop that triggers fuzzing work for a
-// specified duration. It creates a sub-context for each cycle and performs
-// cleanup after each cycle before starting a new one. The cycles run
-// indefinitely until the parent context is canceled.
+// RunFuzzingCycles starts a continuous loop that triggers fuzzing work for at
+// most a specified duration. It runs one cycle after another, see
+// runFuzzingCycle, indefinitely until the parent context is canceled.
 func RunFuzzingCycles(ctx context.Context, logger *slog.Logger, cfg *config.
 	Config, cycleDuration time.Duration) {
 
-	for {
-		// Check if the overall application context has been canceled.
+	for runFuzzingCycle(ctx, logger, cfg, cycleDuration) {
+	}
+}
+
+// RunFuzzingOnce runs a single fuzzing cycle to completion: it clones the
+// repositories, fuzzes every target for the configured fuzz time and saves the
+// results, instead of ending the cycle after a fixed duration. If the context
+// carries a control.Controller, the cycle does not start while it is paused,
+// and ends early when it is interrupted. It returns the statistics of the
+// cycle, nil if it did not get to fuzzing, and the error which ended it, if
+// any.
+func RunFuzzingOnce(ctx context.Context, logger *slog.Logger,
+	cfg *config.Config) (*fuzz.CycleStats, error) {
+
+	ctl := control.FromContext(ctx)
+
+	// Wait while fuzzing is paused.
+	if !ctl.WaitReady(ctx) {
+		return nil, fmt.Errorf("shutdown requested while paused: %w",
+			ctx.Err())
+	}
+
+	metrics.CycleStartTime.SetToCurrentTime()
+
+	// Create a sub-context for the cycle, canceled if it is interrupted.
+	cycleCtx, cancelCycle := context.WithCancel(ctx)
+	defer cancelCycle()
+	go func() {
 		select {
-		case <-ctx.Done():
-			logger.Info("Shutdown requested; exiting fuzzing " +
-				"cycles.")
-			return
-		default:
-			// Continue with the current cycle.
+		case <-ctl.Interrupts():
+			logger.Info("Cycle interrupted; initiating cleanup.")
+			cancelCycle()
+		case <-cycleCtx.Done():
 		}
+	}()
 
-		// Create a sub-context for the current fuzzing cycle.
-		cycleCtx, cancelCycle := context.WithCancel(ctx)
+	logger.Info("Starting fuzzing worker", "startTime", time.Now().
+		Format(time.RFC1123))
+	stats, err := worker.Main(cycleCtx, logger, cfg)
+	config.CleanupWorkspace(logger)
+	if err == nil && cycleCtx.Err() == nil {
+		metrics.CyclesCompleted.Inc()
+	}
 
-		// Channel to check if the cycle is cancelled, before cleanup.
-		doneChan := make(chan struct{})
+	return stats, err
+}
 
-		// Start the fuzzing worker concurrently.
-		go runFuzzingWorker(cycleCtx, logger, cfg, doneChan)
+// runFuzzingCycle triggers fuzzing work for a single cycle, which ends once the
+// work is done or, at the latest, after the specified duration. It creates a
+// sub-context for the cycle and performs cleanup once the cycle ends. If the
+// context carries a control.Controller, the cycle does not start while it is
+// paused, and ends early when it is interrupted. It reports whether another
+// cycle may follow, i.e. false once the parent context is canceled.
+func runFuzzingCycle(ctx context.Context, logger *slog.Logger, cfg *config.
+	Config, cycleDuration time.Duration) bool {
 
-		// Wait for either the cycle duration to elapse or the overall
-		// context to cancel.
-		select {
-		case <-time.After(cycleDuration):
-			logger.Info("Cycle duration complete; initiating " +
-				"cleanup.")
+	ctl := control.FromContext(ctx)
 
-			// Cancel the current cycle.
-			cancelCycle()
+	// Check if the overall application context has been canceled.
+	select {
+	case <-ctx.Done():
+		logger.Info("Shutdown requested; exiting fuzzing cycles.")
+		return false
+	default:
+		// Continue with the current cycle.
+	}
 
-			// wait before the fuzzing worker is closed before
-			// cleanup.
-			<-doneChan
-			config.CleanupWorkspace(logger)
+	// Wait while fuzzing is paused.
+	if !ctl.WaitReady(ctx) {
+		logger.Info("Shutdown requested while paused; exiting " +
+			"fuzzing cycles.")
+		return false
+	}
 
-		case <-ctx.Done():
-			logger.Info("Shutdown initiated during fuzzing " +
-				"cycle; performing final cleanup.")
+	metrics.CycleStartTime.SetToCurrentTime()
 
-			// Overall application context canceled.
-			cancelCycle()
+	// Create a sub-context for the current fuzzing cycle.
+	cycleCtx, cancelCycle := context.WithCancel(ctx)
 
-			// wait before the fuzzing worker is closed before
-			// cleanup.
-			<-doneChan
-			config.CleanupWorkspace(logger)
+	// Channel to check if the cycle is cancelled, before cleanup.
+	doneChan := make(chan struct{})
 
-			return
-		}
+	// Start the fuzzing worker concurrently.
+	go runFuzzingWorker(cycleCtx, logger, cfg, doneChan)
+
+	// Wait for either the fuzzing work to complete, the cycle duration to
+	// elapse or the overall context to cancel.
+	select {
+	case <-doneChan:
+		logger.Info("Fuzzing work complete; initiating cleanup.")
+
+		cancelCycle()
+		config.CleanupWorkspace(logger)
+
+		metrics.CyclesCompleted.Inc()
+
+		return true
+
+	case <-time.After(cycleDuration):
+		logger.Info("Cycle duration complete; initiating cleanup.")
+
+		// Cancel the current cycle.
+		cancelCycle()
+
+		// wait before the fuzzing worker is closed before cleanup.
+		<-doneChan
+		config.CleanupWorkspace(logger)
+
+		metrics.CyclesCompleted.Inc()
+
+		return true
+
+	case <-ctl.Interrupts():
+		logger.Info("Cycle interrupted; initiating cleanup.")
+
+		// Cancel the current cycle, a new one starts once fuzzing is
+		// not paused.
+		cancelCycle()
+
+		// wait before the fuzzing worker is closed before cleanup.
+		<-doneChan
+		config.CleanupWorkspace(logger)
+
+		return true
+
+	case <-ctx.Done():
+		logger.Info("Shutdown initiated during fuzzing cycle; " +
+			"performing final cleanup.")
+
+		// Overall application context canceled.
+		cancelCycle()
+
+		// wait before the fuzzing worker is closed before cleanup.
+		<-doneChan
+		config.CleanupWorkspace(logger)
+
+		return false
 	}
 }
 
 // runFuzzingWorker executes the fuzzing work until the cycle context is
 // canceled. It repeatedly calls the main fuzzing function from the app package.
+// If the work fails, the workspace is cleaned up and the program exits.
 func runFuzzingWorker(ctx context.Context, logger *slog.Logger, cfg *config.
 	Config, doneChan chan struct{}) {
 
+	// Close the channel to indicate that the fuzzing cycle has completed,
+	// so that the scheduler can perform cleanup.
+	defer close(doneChan)
+
 	logger.Info("Starting fuzzing worker", "startTime", time.Now().
 		Format(time.RFC1123))
 
@@ -83,6 +180,13 @@ func runFuzzingWorker(ctx context.Context, logger *slog.Logger, cfg *config.
 		return
 	default:
 		// Execute the main fuzzing operation.
-		worker.Main(ctx, logger, cfg, doneChan)
+		if _, err := worker.Main(ctx, logger, cfg); err != nil {
+			logger.Error("Fuzzing cycle failed", "error", err)
+
+			// Perform workspace cleanup before exiting due to the
+			// error.
+			config.CleanupWorkspace(logger)
+			os.Exit(1)
+		}
 	}
 }
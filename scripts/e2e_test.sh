@@ -8,12 +8,24 @@ export GIT_STORAGE_REPO="https://github.com/lightninglabs/lnd-fuzz.git"
 export FUZZ_TIME="1700"
 export FUZZ_PKG="macaroons routing watchtower/wtclient watchtower/wtwire zpay32"
 
-# Run the make command with a 30-minute timeout
-timeout -s INT --preserve-status 30m make run
+# Build the fuzzer, then run it directly rather than through make, which exits
+# with status 2 whenever a recipe fails and would hide the outcome of the cycle.
+make build || exit 1
+
+# Run a single fuzzing cycle, which exits on its own once every fuzz target ran
+# for FUZZ_TIME. The timeout leaves room for cloning and building, and stops a
+# hung cycle.
+timeout -s INT -k 5m 45m ./app run --once
 EXIT_STATUS=$?
 
-# If make run failed (not timeout and SIGINT), exit with error
-if [ $EXIT_STATUS -ne 0 ] && [ $EXIT_STATUS -ne 130 ]; then
+if [ $EXIT_STATUS -eq 124 ]; then
+  echo "❌ The fuzzing cycle did not complete within 45 minutes."
+  exit 1
+fi
+
+# A crash found in the fuzzed project is not a failure of the fuzzer, any other
+# non-zero status is.
+if [ $EXIT_STATUS -ne 0 ] && [ $EXIT_STATUS -ne 1 ]; then
   echo "❌ The operation exited with status $EXIT_STATUS."
   exit $EXIT_STATUS
 fi
@@ -27,4 +39,4 @@ else
 fi
 
 # Cleanup: Delete the ./fuzz_results directory
-rm -rf ./fuzz_results
\ No newline at end of file
+rm -rf ./fuzz_results
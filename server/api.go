@@ -0,0 +1,122 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/NishantBansal2003/LND-Fuzz/control"
+	"github.com/NishantBansal2003/LND-Fuzz/crash"
+)
+
+// defaultCrashLimit is the number of crashes returned by /api/crashes unless
+// the request sets the limit parameter.
+const defaultCrashLimit = 20
+
+// api serves the status and control endpoints under /api.
+type api struct {
+	cfg *config.Config
+	ctl *control.Controller
+}
+
+// register adds the endpoints of the API to the mux.
+func (a *api) register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/status", a.handleStatus)
+	mux.HandleFunc("GET /api/targets", a.handleTargets)
+	mux.HandleFunc("GET /api/crashes", a.handleCrashes)
+	mux.HandleFunc("POST /api/pause", a.handlePause)
+	mux.HandleFunc("POST /api/resume", a.handleResume)
+	mux.HandleFunc("POST /api/cycle", a.handleCycle)
+	mux.HandleFunc("POST /api/targets/stop", a.handleStopTarget)
+}
+
+// handleStatus reports whether fuzzing is paused, the current cycle and its
+// fuzz targets.
+func (a *api) handleStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, a.ctl.Status())
+}
+
+// handleTargets lists the fuzz targets of the current cycle and their state.
+func (a *api) handleTargets(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, a.ctl.Status().Targets)
+}
+
+// handleCrashes lists the most recently seen crashes, at most "limit" of them.
+func (a *api) handleCrashes(w http.ResponseWriter, r *http.Request) {
+	limit := defaultCrashLimit
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest,
+				errors.New("limit must be a positive number"))
+			return
+		}
+		limit = n
+	}
+
+	records, err := crash.NewStore(a.cfg).Records()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if len(records) > limit {
+		records = records[:limit]
+	}
+
+	writeJSON(w, http.StatusOK, records)
+}
+
+// handlePause interrupts the current cycle and keeps new ones from starting.
+func (a *api) handlePause(w http.ResponseWriter, r *http.Request) {
+	a.ctl.Pause()
+	writeJSON(w, http.StatusOK, a.ctl.Status())
+}
+
+// handleResume lets new cycles start again.
+func (a *api) handleResume(w http.ResponseWriter, r *http.Request) {
+	a.ctl.Resume()
+	writeJSON(w, http.StatusOK, a.ctl.Status())
+}
+
+// handleCycle ends the current cycle so that a new one starts right away.
+func (a *api) handleCycle(w http.ResponseWriter, r *http.Request) {
+	if err := a.ctl.TriggerCycle(); err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// handleStopTarget stops the fuzz target given by the "package" and "target"
+// parameters for the rest of the cycle.
+func (a *api) handleStopTarget(w http.ResponseWriter, r *http.Request) {
+	pkg := r.URL.Query().Get("package")
+	target := r.URL.Query().Get("target")
+	if pkg == "" || target == "" {
+		writeError(w, http.StatusBadRequest,
+			errors.New("package and target are required"))
+		return
+	}
+
+	if err := a.ctl.StopTarget(pkg, target); err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// writeJSON writes the value as the JSON body of the response.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes the error as the JSON body of the response.
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/NishantBansal2003/LND-Fuzz/control"
+	"github.com/NishantBansal2003/LND-Fuzz/crash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAPI exercises the status and control endpoints against a controller
+// running a single fuzz target.
+func TestAPI(t *testing.T) {
+	cfg := &config.Config{FuzzResultsPath: t.TempDir()}
+	ctl := control.New()
+	ctl.StartCycle("20250101T000000Z", time.Now())
+	targetCtx, cancel := ctl.StartTarget(context.Background(), "foo/bar",
+		"FuzzFoo")
+	defer cancel()
+
+	srv := httptest.NewServer(NewHandler(cfg, ctl))
+	defer srv.Close()
+
+	do := func(method, path string) *http.Response {
+		req, err := http.NewRequest(method, srv.URL+path, nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+
+		return resp
+	}
+
+	// The running target is listed.
+	resp := do(http.MethodGet, "/api/targets")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var targets []control.TargetStatus
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&targets))
+	require.Len(t, targets, 1)
+	assert.Equal(t, "FuzzFoo", targets[0].Target)
+	assert.Equal(t, control.TargetRunning, targets[0].State)
+
+	// Stopping requires the package and target, and only applies to
+	// running targets.
+	resp = do(http.MethodPost, "/api/targets/stop?target=FuzzFoo")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp = do(http.MethodPost, "/api/targets/stop?package=foo/bar&"+
+		"target=FuzzBar")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp = do(http.MethodPost, "/api/targets/stop?package=foo/bar&"+
+		"target=FuzzFoo")
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	assert.Error(t, targetCtx.Err())
+
+	// A new cycle can be triggered, unless fuzzing is paused.
+	resp = do(http.MethodPost, "/api/cycle")
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	resp = do(http.MethodPost, "/api/pause")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var status control.Status
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+	assert.True(t, status.Paused)
+
+	resp = do(http.MethodPost, "/api/cycle")
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	resp = do(http.MethodPost, "/api/resume")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = do(http.MethodGet, "/api/status")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+	assert.False(t, status.Paused)
+	assert.Equal(t, "20250101T000000Z", status.Cycle)
+
+	// Control endpoints only accept POST.
+	resp = do(http.MethodGet, "/api/pause")
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+// TestAPICrashes verifies that the most recently seen crashes are listed,
+// limited to the requested number.
+func TestAPICrashes(t *testing.T) {
+	cfg := &config.Config{FuzzResultsPath: t.TempDir()}
+	store := crash.NewStore(cfg)
+	for _, sig := range []string{"aaaa", "bbbb", "cccc"} {
+		_, err := store.Add(&crash.Finding{
+			Signature: sig,
+			Class:     "panic",
+			Package:   "foo",
+			Target:    "FuzzFoo",
+		})
+		require.NoError(t, err)
+	}
+
+	srv := httptest.NewServer(NewHandler(cfg, control.New()))
+	defer srv.Close()
+
+	tests := []struct {
+		name       string
+		query      string
+		statusCode int
+		count      int
+	}{
+		{
+			name:       "default limit",
+			statusCode: http.StatusOK,
+			count:      3,
+		},
+		{
+			name:       "limit",
+			query:      "?limit=2",
+			statusCode: http.StatusOK,
+			count:      2,
+		},
+		{
+			name:       "invalid limit",
+			query:      "?limit=0",
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(srv.URL + "/api/crashes" +
+				tt.query)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			require.Equal(t, tt.statusCode, resp.StatusCode)
+			if tt.statusCode != http.StatusOK {
+				return
+			}
+
+			var records []*crash.Record
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(
+				&records))
+			assert.Len(t, records, tt.count)
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package server
+
+import (
+	"bytes"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/NishantBansal2003/LND-Fuzz/crash"
+	"github.com/NishantBansal2003/LND-Fuzz/fuzz"
+	"github.com/NishantBansal2003/LND-Fuzz/parser"
+)
+
+// templateFS holds the HTML templates of the dashboard.
+//
+//go:embed templates/*.html
+var templateFS embed.FS
+
+// templates are the parsed HTML templates of the dashboard.
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"shortSig": func(sig string) string {
+		return sig[:min(len(sig), 12)]
+	},
+	"formatTime": func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.UTC().Format("2006-01-02 15:04:05 UTC")
+	},
+	"hexDump": hex.Dump,
+}).ParseFS(templateFS, "templates/*.html"))
+
+// targetRow is a fuzz target listed on the dashboard, with the statistics of
+// the last cycle it ran in.
+type targetRow struct {
+	Package     string
+	Target      string
+	Cycle       string
+	Summary     fuzz.TargetSummary
+	SavedCorpus int
+}
+
+// cycleRow is a past cycle listed on the dashboard.
+type cycleRow struct {
+	Cycle    string
+	Start    time.Time
+	Duration time.Duration
+	Targets  int
+	Starved  int
+	Execs    int64
+}
+
+// dashboardPage is the data rendered by the dashboard template.
+type dashboardPage struct {
+	GeneratedAt time.Time
+	Targets     []targetRow
+	Crashes     []*crash.Record
+	Cycles      []cycleRow
+}
+
+// inputPage is the data rendered by the input template.
+type inputPage struct {
+	Record *crash.Record
+	Input  crash.Input
+	Values []parser.CorpusValue
+	Error  string
+}
+
+// dashboard serves a read-only HTML view of the fuzz results.
+type dashboard struct {
+	cfg *config.Config
+}
+
+// register adds the pages of the dashboard to the mux.
+func (d *dashboard) register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /{$}", d.handleIndex)
+	mux.HandleFunc("GET /crashes/{signature}/log", d.handleCrashLog)
+	mux.HandleFunc("GET /crashes/{signature}/inputs/{index}",
+		d.handleCrashInput)
+}
+
+// handleIndex renders the fuzz targets, the crashes and the cycle history.
+func (d *dashboard) handleIndex(w http.ResponseWriter, r *http.Request) {
+	cycles, err := fuzz.LoadStats(d.cfg)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	records, err := crash.NewStore(d.cfg).Records()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	page := &dashboardPage{
+		GeneratedAt: time.Now(),
+		Targets:     d.targetRows(cycles),
+		Crashes:     records,
+		Cycles:      cycleRows(cycles),
+	}
+	render(w, "dashboard.html", page)
+}
+
+// handleCrashLog serves the latest failure log of a crash.
+func (d *dashboard) handleCrashLog(w http.ResponseWriter, r *http.Request) {
+	store := crash.NewStore(d.cfg)
+	record, err := findRecord(store, r.PathValue("signature"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if record.LogPath == "" {
+		http.Error(w, "crash has no failure log", http.StatusNotFound)
+		return
+	}
+
+	http.ServeFile(w, r, filepath.Join(store.Dir(), record.LogPath))
+}
+
+// handleCrashInput renders the decoded values of an input of a crash.
+func (d *dashboard) handleCrashInput(w http.ResponseWriter, r *http.Request) {
+	store := crash.NewStore(d.cfg)
+	record, err := findRecord(store, r.PathValue("signature"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	index, err := strconv.Atoi(r.PathValue("index"))
+	if err != nil || index < 0 || index >= len(record.Inputs) {
+		http.Error(w, "unknown input", http.StatusNotFound)
+		return
+	}
+
+	page := &inputPage{
+		Record: record,
+		Input:  record.Inputs[index],
+	}
+	data, err := os.ReadFile(filepath.Join(store.Dir(), page.Input.Path))
+	if err == nil {
+		page.Values, err = parser.DecodeCorpusFile(data)
+	}
+	if err != nil {
+		page.Error = err.Error()
+	}
+
+	render(w, "input.html", page)
+}
+
+// targetRows lists every fuzz target found in the cycles, newest first, with
+// the statistics of the last cycle it ran in.
+func (d *dashboard) targetRows(cycles []*fuzz.CycleStats) []targetRow {
+	seen := make(map[string]bool)
+	var rows []targetRow
+	for _, cycle := range cycles {
+		for _, ts := range cycle.Targets {
+			key := ts.Package + "/" + ts.Target
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			rows = append(rows, targetRow{
+				Package: ts.Package,
+				Target:  ts.Target,
+				Cycle:   cycle.Cycle,
+				Summary: ts.Summary,
+				SavedCorpus: savedCorpusSize(d.cfg, ts.Package,
+					ts.Target),
+			})
+		}
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Package != rows[j].Package {
+			return rows[i].Package < rows[j].Package
+		}
+		return rows[i].Target < rows[j].Target
+	})
+
+	return rows
+}
+
+// cycleRows summarizes each cycle.
+func cycleRows(cycles []*fuzz.CycleStats) []cycleRow {
+	rows := make([]cycleRow, 0, len(cycles))
+	for _, cycle := range cycles {
+		row := cycleRow{
+			Cycle:    cycle.Cycle,
+			Start:    cycle.Start,
+			Duration: cycle.End.Sub(cycle.Start).Round(time.Second),
+			Targets:  len(cycle.Targets),
+		}
+		for _, ts := range cycle.Targets {
+			row.Execs += ts.Summary.Execs
+			if ts.Summary.Starved {
+				row.Starved++
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// savedCorpusSize returns the number of inputs in the corpus of the fuzz
+// target saved under the fuzz results path.
+func savedCorpusSize(cfg *config.Config, pkg, target string) int {
+	entries, err := os.ReadDir(filepath.Join(cfg.FuzzResultsPath, pkg,
+		"testdata", "fuzz", target))
+	if err != nil {
+		return 0
+	}
+
+	size := 0
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			size++
+		}
+	}
+
+	return size
+}
+
+// findRecord returns the crash record with the given signature.
+func findRecord(store *crash.Store, signature string) (*crash.Record, error) {
+	records, err := store.Records()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		if record.Signature == signature {
+			return record, nil
+		}
+	}
+
+	return nil, errors.New("unknown crash")
+}
+
+// render executes the named template into the response. The page is rendered
+// into a buffer first, so that a template failure yields a clean error
+// response rather than a truncated page.
+func render(w http.ResponseWriter, name string, data any) {
+	var b bytes.Buffer
+	if err := templates.ExecuteTemplate(&b, name, data); err != nil {
+		http.Error(w, fmt.Sprintf("failed to render page: %v", err),
+			http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = b.WriteTo(w)
+}
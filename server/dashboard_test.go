@@ -0,0 +1,153 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/NishantBansal2003/LND-Fuzz/control"
+	"github.com/NishantBansal2003/LND-Fuzz/crash"
+	"github.com/NishantBansal2003/LND-Fuzz/fuzz"
+	"github.com/NishantBansal2003/LND-Fuzz/git"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDashboard verifies that the dashboard renders the saved statistics,
+// crashes and cycles, and serves the failure logs and decoded inputs of the
+// crashes.
+func TestDashboard(t *testing.T) {
+	cfg := &config.Config{FuzzResultsPath: t.TempDir()}
+
+	// Save the statistics of a cycle.
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	stats := &fuzz.CycleStats{
+		Cycle: "20250101T000000Z",
+		Start: start,
+		End:   start.Add(2 * time.Minute),
+		Targets: []*fuzz.TargetStats{{
+			Package: "foo/bar",
+			Target:  "FuzzFoo",
+			Summary: fuzz.TargetSummary{
+				Execs:      123456,
+				CorpusSize: 42,
+			},
+		}},
+	}
+	statsDir := filepath.Join(cfg.FuzzResultsPath, fuzz.StatsDirName)
+	require.NoError(t, os.MkdirAll(statsDir, 0755))
+	data, err := json.Marshal(stats)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(statsDir,
+		stats.Cycle+".json"), data, 0644))
+
+	// Record a crash with its input and failure log.
+	tmp := t.TempDir()
+	inputPath := filepath.Join(tmp, "771e938e4458e983")
+	require.NoError(t, os.WriteFile(inputPath,
+		[]byte("go test fuzz v1\nstring(\"<boom>\")\n"), 0644))
+	logPath := filepath.Join(tmp, "FuzzFoo_failure.log")
+	require.NoError(t, os.WriteFile(logPath,
+		[]byte("panic: boom\n"), 0644))
+
+	_, err = crash.NewStore(cfg).Add(&crash.Finding{
+		Signature: "0123456789abcdef",
+		Class:     "panic",
+		Priority:  1,
+		Summary:   "panic: boom",
+		Package:   "foo/bar",
+		Target:    "FuzzFoo",
+		InputID:   "771e938e4458e983",
+		InputPath: inputPath,
+		LogPath:   logPath,
+	})
+	require.NoError(t, err)
+	require.NoError(t, crash.NewStore(cfg).SetVerification(
+		"0123456789abcdef", &crash.Verification{
+			Runs:     5,
+			Failures: 5,
+			Label:    crash.Reproducible,
+		}))
+	require.NoError(t, crash.NewStore(cfg).SetBisection(
+		"0123456789abcdef", &git.Bisection{
+			FirstBad: "9e8d7c6b5a4f3e2d1c0b",
+			LastGood: "1a2b3c4d5e6f7a8b9c0d",
+		}))
+
+	srv := httptest.NewServer(NewHandler(cfg, control.New()))
+	defer srv.Close()
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(srv.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		return resp.StatusCode, string(body)
+	}
+
+	status, body := get("/")
+	require.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, "<code>FuzzFoo</code>")
+	assert.Contains(t, body, "123456")
+	assert.Contains(t, body, "0123456789ab")
+	assert.Contains(t, body, `href="/crashes/0123456789abcdef/log"`)
+	assert.Contains(t, body, `href="/crashes/0123456789abcdef/inputs/0"`)
+	assert.Contains(t, body, "2m0s")
+	assert.Contains(t, body, `title="5/5 verification runs failed">`+
+		`reproducible</span>`)
+	assert.Contains(t, body, `<code title="9e8d7c6b5a4f3e2d1c0b">`+
+		`9e8d7c6b5a4f</code>`)
+	assert.Contains(t, body, "<td>new</td>")
+
+	status, body = get("/crashes/0123456789abcdef/log")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "panic: boom\n", body)
+
+	// The decoded input is escaped.
+	status, body = get("/crashes/0123456789abcdef/inputs/0")
+	require.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, "&lt;boom&gt;")
+	assert.Contains(t, body, "3c 62 6f 6f 6d 3e")
+
+	status, _ = get("/crashes/0123456789abcdef/inputs/1")
+	assert.Equal(t, http.StatusNotFound, status)
+	status, _ = get("/crashes/fedcba9876543210/log")
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+// TestDashboardEmpty verifies that the dashboard renders before any result is
+// saved.
+func TestDashboardEmpty(t *testing.T) {
+	cfg := &config.Config{FuzzResultsPath: t.TempDir()}
+	srv := httptest.NewServer(NewHandler(cfg, control.New()))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "No crashes found.")
+}
+
+// TestRenderError verifies that a page failing to render yields an error
+// response rather than a truncated page.
+func TestRenderError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	render(rec, "dashboard.html", struct{}{})
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Contains(t, rec.Body.String(), "failed to render page")
+	assert.NotContains(t, rec.Body.String(), "<!DOCTYPE html>")
+}
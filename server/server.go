@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/NishantBansal2003/LND-Fuzz/control"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// shutdownTimeout is how long in-flight requests are given to complete once
+// the server is shutting down.
+const shutdownTimeout = 5 * time.Second
+
+// NewHandler returns the handler serving every HTTP endpoint of the fuzzer:
+//   - /metrics: Prometheus metrics
+//   - /api/...: status and control API, reading crashes from the results
+//     of the given configuration and steering fuzzing through ctl
+//   - /: read-only HTML dashboard of the fuzz results
+func NewHandler(cfg *config.Config, ctl *control.Controller) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", promhttp.Handler())
+
+	a := &api{cfg: cfg, ctl: ctl}
+	a.register(mux)
+
+	d := &dashboard{cfg: cfg}
+	d.register(mux)
+
+	return mux
+}
+
+// Start listens on the configured address and serves the HTTP endpoints in the
+// background until the context is canceled. It returns an error if the
+// address cannot be listened on.
+func Start(ctx context.Context, logger *slog.Logger, cfg *config.Config,
+	ctl *control.Controller) error {
+
+	listener, err := net.Listen("tcp", cfg.HTTPAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %q: %w", cfg.HTTPAddr,
+			err)
+	}
+
+	srv := &http.Server{
+		Handler:           NewHandler(cfg, ctl),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		logger.Info("HTTP server started", "addr", listener.Addr())
+
+		err := srv.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("HTTP server failed", "error", err)
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(
+			context.Background(), shutdownTimeout,
+		)
+		defer cancel()
+
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			logger.Error("HTTP server shutdown failed", "error",
+				err)
+		}
+	}()
+
+	return nil
+}
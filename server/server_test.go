@@ -0,0 +1,59 @@
+package server
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NishantBansal2003/LND-Fuzz/config"
+	"github.com/NishantBansal2003/LND-Fuzz/control"
+	"github.com/NishantBansal2003/LND-Fuzz/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMetricsEndpoint verifies that the fuzzer metrics are served in the
+// Prometheus text format.
+func TestMetricsEndpoint(t *testing.T) {
+	metrics.CrashesFound.WithLabelValues("panic", "foo", "FuzzFoo").Inc()
+	metrics.TargetExecsPerSecond.WithLabelValues("foo", "FuzzFoo").Set(42)
+
+	srv := httptest.NewServer(NewHandler(&config.Config{}, control.New()))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(body), `continuous_fuzz_crashes_found_total`+
+		`{class="panic",package="foo",target="FuzzFoo"} 1`)
+	assert.Contains(t, string(body), `continuous_fuzz_target_execs_per_`+
+		`second{package="foo",target="FuzzFoo"} 42`)
+	assert.Contains(t, string(body), "continuous_fuzz_cycles_completed_"+
+		"total 0")
+}
+
+// TestStart verifies that starting the server fails only if the address
+// cannot be listened on.
+func TestStart(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	busy := httptest.NewServer(http.NotFoundHandler())
+	defer busy.Close()
+
+	cfg := &config.Config{HTTPAddr: busy.Listener.Addr().String()}
+	err := Start(ctx, logger, cfg, control.New())
+	assert.ErrorContains(t, err, "failed to listen")
+
+	cfg.HTTPAddr = "127.0.0.1:0"
+	require.NoError(t, Start(ctx, logger, cfg, control.New()))
+}
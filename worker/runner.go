@@ -2,8 +2,8 @@ package worker
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
-	"os"
 
 	"github.com/NishantBansal2003/LND-Fuzz/config"
 	"github.com/NishantBansal2003/LND-Fuzz/fuzz"
@@ -11,33 +11,23 @@ import (
 )
 
 // Main handles the cloning of repositories and the execution of fuzz testing.
-// It ensures that any errors encountered during these processes are logged and
-// that the workspace is cleaned up appropriately before the program exits.
-func Main(ctx context.Context, logger *slog.Logger, cfg *config.Config,
-	doneChan chan struct{}) {
-
-	// Close the channel to indicate that the fuzzing cycle has completed,
-	// so that the scheduler can perform cleanup.
-	defer close(doneChan)
+// It returns the statistics of the fuzzing cycle, nil if cloning failed, and
+// the error which ended it, if any. Cleaning up the workspace is left to the
+// caller.
+func Main(ctx context.Context, logger *slog.Logger,
+	cfg *config.Config) (*fuzz.CycleStats, error) {
 
 	// Clone the project and storage repositories based on the loaded
 	// configuration.
 	if err := git.CloneRepositories(ctx, logger, cfg); err != nil {
-		logger.Error("Repository cloning failed", "error", err)
-
-		// Perform workspace cleanup before exiting due to the cloning
-		// error.
-		config.CleanupWorkspace(logger)
-		os.Exit(1)
+		return nil, fmt.Errorf("repository cloning failed: %w", err)
 	}
 
 	// Execute fuzz testing on the specified packages.
-	if err := fuzz.RunFuzzing(ctx, logger, cfg); err != nil {
-		logger.Error("Fuzzing process failed", "error", err)
-
-		// Perform workspace cleanup before exiting due to the fuzzing
-		// error.
-		config.CleanupWorkspace(logger)
-		os.Exit(1)
+	stats, err := fuzz.RunFuzzing(ctx, logger, cfg)
+	if err != nil {
+		return stats, fmt.Errorf("fuzzing process failed: %w", err)
 	}
+
+	return stats, nil
 }